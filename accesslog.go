@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+var accessLogFile *os.File
+var accessLogMutex sync.Mutex
+
+func openAccessLog() error {
+	if *accessLogFileFlag == "" {
+		return nil
+	}
+
+	accessLogPath, err := homedir.Expand(*accessLogFileFlag)
+	if err != nil {
+		return err
+	}
+
+	accessLogFile, err = os.OpenFile(accessLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	return err
+}
+
+// getAccessLogLine formats a request in Apache Combined Log Format, using the absolute URL as proxies do
+func getAccessLogLine(req *http.Request, reqCtx *proxyRequestContext, statusCode int, responseBytes int64) string {
+	clientHost, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil || clientHost == "" {
+		clientHost = "-"
+	}
+
+	requestURL := *req.URL
+	if requestURL.Host == "" {
+		requestURL.Host = req.Host
+	}
+	if requestURL.Scheme == "" {
+		requestURL.Scheme = "http"
+	}
+
+	size := "-"
+	if responseBytes > 0 {
+		size = strconv.FormatInt(responseBytes, 10)
+	}
+
+	return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s %q %q\n",
+		clientHost,
+		reqCtx.timestamp.Format("02/Jan/2006:15:04:05 -0700"),
+		req.Method,
+		requestURL.String(),
+		req.Proto,
+		statusCode,
+		size,
+		accessLogField(req.Referer()),
+		accessLogField(req.UserAgent()),
+	)
+}
+
+func accessLogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func writeAccessLogLine(line string) {
+	accessLogMutex.Lock()
+	defer accessLogMutex.Unlock()
+
+	accessLogFile.WriteString(line)
+}
+
+// accessLogProxyResponse writes the access log line for a request once its response body has been sent
+func accessLogProxyResponse(resp *http.Response, req *http.Request, reqCtx *proxyRequestContext) *http.Response {
+	if resp == nil {
+		writeAccessLogLine(getAccessLogLine(req, reqCtx, http.StatusBadGateway, 0))
+		return resp
+	}
+	if resp.Body == nil {
+		writeAccessLogLine(getAccessLogLine(req, reqCtx, resp.StatusCode, 0))
+		return resp
+	}
+
+	statusCode := resp.StatusCode
+	resp.Body = &countingReadCloser{
+		ReadCloser: resp.Body,
+		done: func(count int64) {
+			writeAccessLogLine(getAccessLogLine(req, reqCtx, statusCode, count))
+		},
+	}
+
+	return resp
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// UnresolvedRequest is a line of the action star log, written for every AWS request whose action was not resolved
+type UnresolvedRequest struct {
+	Timestamp   time.Time `json:"Timestamp"`
+	Host        string    `json:"Host"`
+	HTTPMethod  string    `json:"HttpMethod"`
+	URI         string    `json:"Uri"`
+	ContentType string    `json:"ContentType,omitempty"`
+	Target      string    `json:"Target,omitempty"`
+	Body        string    `json:"Body,omitempty"`
+}
+
+var actionStarLogFile *os.File
+var actionStarLogMutex sync.Mutex
+
+func openActionStarLog() error {
+	if *actionStarLogFlag == "" {
+		return nil
+	}
+
+	actionStarLogPath, err := homedir.Expand(*actionStarLogFlag)
+	if err != nil {
+		return err
+	}
+
+	actionStarLogFile, err = os.OpenFile(actionStarLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	return err
+}
+
+func writeUnresolvedRequest(req *http.Request, body []byte) {
+	if actionStarLogFile == nil {
+		return
+	}
+
+	line, err := json.Marshal(UnresolvedRequest{
+		Timestamp:   time.Now(),
+		Host:        req.Host,
+		HTTPMethod:  req.Method,
+		URI:         req.RequestURI,
+		ContentType: req.Header.Get("Content-Type"),
+		Target:      req.Header.Get("X-Amz-Target"),
+		Body:        string(body),
+	})
+	if err != nil {
+		return
+	}
+
+	actionStarLogMutex.Lock()
+	defer actionStarLogMutex.Unlock()
+
+	actionStarLogFile.Write(append(line, '\n'))
+}
@@ -0,0 +1,67 @@
+// Sorts the calls table by the column whose header is clicked, toggling between ascending and descending
+(function () {
+  var table = document.getElementById("calls");
+  if (!table) {
+    return;
+  }
+  var headers = table.tHead.rows[0].cells;
+
+  function sortValue(row, column) {
+    var cell = row.cells[column];
+    return cell.hasAttribute("data-sort") ? Number(cell.getAttribute("data-sort")) : cell.textContent.toLowerCase();
+  }
+
+  Array.prototype.forEach.call(headers, function (header, column) {
+    header.addEventListener("click", function () {
+      var ascending = !header.classList.contains("asc");
+      Array.prototype.forEach.call(headers, function (other) {
+        other.classList.remove("asc", "desc");
+      });
+      header.classList.add(ascending ? "asc" : "desc");
+
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = sortValue(a, column);
+        var y = sortValue(b, column);
+        if (x === y) {
+          return 0;
+        }
+        return (x < y ? -1 : 1) * (ascending ? 1 : -1);
+      });
+      rows.forEach(function (row) {
+        body.appendChild(row);
+      });
+    });
+  });
+})();
+
+// Draws the timeline of calls per service with the embedded Chart.js
+(function () {
+  var canvas = document.getElementById("timeline");
+  if (!canvas || typeof Chart === "undefined") {
+    return;
+  }
+
+  new Chart(canvas.getContext("2d"), {
+    type: "bar",
+    data: timeline,
+    options: {
+      animation: false,
+      maintainAspectRatio: false,
+      legend: { position: "bottom" },
+      scales: {
+        xAxes: [{ stacked: true, categoryPercentage: 0.9, barPercentage: 1, gridLines: { display: false } }],
+        yAxes: [{
+          stacked: true,
+          ticks: {
+            beginAtZero: true,
+            callback: function (value) {
+              return Math.floor(value) === value ? value : null;
+            }
+          }
+        }]
+      }
+    }
+  });
+})();
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// AuditRecord is a line of the audit log, written for every request seen by the proxy
+type AuditRecord struct {
+	Timestamp        time.Time `json:"Timestamp"`
+	Host             string    `json:"Host"`
+	HTTPMethod       string    `json:"HttpMethod"`
+	URI              string    `json:"Uri"`
+	Service          string    `json:"Service"`
+	Method           string    `json:"Method"`
+	StatusCode       int       `json:"StatusCode"`
+	RequestBytes     int64     `json:"RequestBytes"`
+	ResponseBytes    int64     `json:"ResponseBytes"`
+	CorrelationID    string    `json:"CorrelationId,omitempty"`
+	EventSource      string    `json:"EventSource,omitempty"`
+	SourceAnnotation string    `json:"SourceAnnotation,omitempty"`
+	SessionID        string    `json:"SessionId,omitempty"`
+}
+
+var auditLogFile *os.File
+var auditLogMutex sync.Mutex
+
+func openAuditLog() error {
+	if *auditLogFlag == "" {
+		return nil
+	}
+
+	auditLogPath, err := homedir.Expand(*auditLogFlag)
+	if err != nil {
+		return err
+	}
+
+	auditLogFile, err = os.OpenFile(auditLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	return err
+}
+
+func writeAuditRecord(record AuditRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	auditLogMutex.Lock()
+	defer auditLogMutex.Unlock()
+
+	auditLogFile.Write(append(line, '\n'))
+}
+
+// auditProxyResponse writes the audit record for a request once its response body has been sent
+func auditProxyResponse(resp *http.Response, req *http.Request, reqCtx *proxyRequestContext) *http.Response {
+	record := AuditRecord{
+		Timestamp:        reqCtx.timestamp,
+		Host:             req.Host,
+		HTTPMethod:       req.Method,
+		URI:              req.URL.RequestURI(),
+		RequestBytes:     int64(len(reqCtx.body)),
+		EventSource:      *eventSourceFlag,
+		SourceAnnotation: *entrySourceAnnotationFlag,
+		SessionID:        *sessionIDFlag,
+	}
+	if reqCtx.entry != nil {
+		record.Service = reqCtx.entry.Service
+		record.Method = reqCtx.entry.Method
+		record.CorrelationID = reqCtx.entry.CorrelationID
+	}
+
+	if resp == nil || resp.Body == nil {
+		if resp != nil {
+			record.StatusCode = resp.StatusCode
+		}
+		writeAuditRecord(record)
+		return resp
+	}
+
+	record.StatusCode = resp.StatusCode
+	resp.Body = &countingReadCloser{
+		ReadCloser: resp.Body,
+		done: func(count int64) {
+			record.ResponseBytes = count
+			writeAuditRecord(record)
+		},
+	}
+
+	return resp
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestAuditLog opens --audit-log in a temporary directory, until the end of the test
+func openTestAuditLog(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	setTestFlag(t, "audit-log", path)
+	if err := openAuditLog(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		auditLogFile.Close()
+		auditLogFile = nil
+	})
+
+	return path
+}
+
+// readTestAuditRecords waits for a number of records to be written to the audit log, as they are written once the
+// proxy has sent the response body
+func readTestAuditRecords(t *testing.T, path string, count int) []AuditRecord {
+	t.Helper()
+
+	var records []AuditRecord
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		records = nil
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record AuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("the audit log has an invalid line: %v", err)
+			}
+			records = append(records, record)
+		}
+		file.Close()
+
+		if len(records) >= count {
+			return records
+		}
+	}
+
+	t.Fatalf("got %d audit records, want %d", len(records), count)
+	return nil
+}
+
+func TestAuditLogEventSource(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "event-source", "deploy")
+	path := openTestAuditLog(t)
+
+	entry := Entry{Region: "us-east-1", Type: "ApiCall", Service: "STS", Method: "GetCallerIdentity", FinalHTTPStatusCode: 200, Timestamp: time.Now()}
+	if !recordCall(entry) {
+		t.Fatal("the call was not recorded")
+	}
+	if entries := callLog.Snapshot(); len(entries) != 1 || entries[0].EventSource != "deploy" {
+		t.Errorf("got calls %+v, want one with event source deploy", entries)
+	}
+
+	// the proxy passes each response through auditProxyResponse, which writes the record once the body is read
+	body := "<GetCallerIdentityResponse/>"
+	req := httptest.NewRequest("POST", "http://sts.amazonaws.com/", nil)
+	resp := auditProxyResponse(&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}, req, &proxyRequestContext{
+		body:      []byte("Action=GetCallerIdentity&Version=2011-06-15"),
+		timestamp: time.Now(),
+		entry:     &entry,
+	})
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	record := readTestAuditRecords(t, path, 1)[0]
+	if record.EventSource != "deploy" || record.Service != "STS" || record.Method != "GetCallerIdentity" || record.StatusCode != 200 {
+		t.Errorf("got audit record %+v, want STS GetCallerIdentity with event source deploy", record)
+	}
+	if record.ResponseBytes != int64(len(body)) {
+		t.Errorf("got %d response bytes, want %d", record.ResponseBytes, len(body))
+	}
+}
+
+func TestAuditLogSourceAnnotation(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "entry-source-annotation", "integration-test-run-42")
+	path := openTestAuditLog(t)
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", queryFormHeader, "Action=GetCallerIdentity&Version=2011-06-15")
+
+	if entry := getSingleTestEntry(t); entry.SourceAnnotation != "integration-test-run-42" {
+		t.Errorf("got call annotation %q, want integration-test-run-42", entry.SourceAnnotation)
+	}
+	if record := readTestAuditRecords(t, path, 1)[0]; record.SourceAnnotation != "integration-test-run-42" {
+		t.Errorf("got audit record annotation %q, want integration-test-run-42", record.SourceAnnotation)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// autoStopGracePeriod is the longest a stop waits for the calls in flight to complete
+const autoStopGracePeriod = 10 * time.Second
+
+var inactivityTimer *time.Timer
+var inFlightCalls int64
+var uniqueCallPairs = make(map[string]bool)
+var uniqueCallPairsMutex sync.Mutex
+var autoStopOnce sync.Once
+
+// startAutoStop starts the --timeout inactivity timer
+func startAutoStop() {
+	if *timeoutFlag > 0 {
+		inactivityTimer = time.AfterFunc(*timeoutFlag, func() {
+			if atomic.LoadInt64(&inFlightCalls) > 0 {
+				inactivityTimer.Reset(*timeoutFlag) // a slow call is still in flight
+				return
+			}
+			stopSession(fmt.Sprintf("no AWS calls were made for %s", *timeoutFlag))
+		})
+	}
+}
+
+func resetInactivityTimer() {
+	if inactivityTimer != nil {
+		inactivityTimer.Reset(*timeoutFlag)
+	}
+}
+
+// inFlightCallBody ends a call in flight once its response has been passed on to the client
+type inFlightCallBody struct {
+	io.ReadCloser
+	once sync.Once
+}
+
+func (b *inFlightCallBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(endInFlightCall)
+	return err
+}
+
+// startInFlightCall marks an AWS call as received by the proxy, which a stop waits for
+func startInFlightCall() {
+	atomic.AddInt64(&inFlightCalls, 1)
+	resetInactivityTimer()
+}
+
+func endInFlightCall() {
+	atomic.AddInt64(&inFlightCalls, -1)
+	resetInactivityTimer()
+}
+
+// endInFlightCallWithResponse ends a call in flight once the response is closed, or now if there is none
+func endInFlightCallWithResponse(resp *http.Response) *http.Response {
+	if resp == nil || resp.Body == nil {
+		endInFlightCall()
+		return resp
+	}
+
+	resp.Body = &inFlightCallBody{ReadCloser: resp.Body}
+	return resp
+}
+
+// checkAutoStop counts the unique service and method pairs of the recorded calls, stopping at --max-unique-pairs
+func checkAutoStop(entry Entry) {
+	resetInactivityTimer()
+
+	if *maxUniquePairsFlag <= 0 {
+		return
+	}
+
+	uniqueCallPairsMutex.Lock()
+	uniqueCallPairs[entry.Service+"."+entry.Method] = true
+	pairCount := len(uniqueCallPairs)
+	uniqueCallPairsMutex.Unlock()
+
+	if pairCount >= *maxUniquePairsFlag {
+		go stopSession(fmt.Sprintf("%d unique calls were captured", pairCount))
+	}
+}
+
+// stopSession waits for the calls in flight to complete, then writes the output and ends the session
+func stopSession(reason string) {
+	autoStopOnce.Do(func() {
+		log.Printf("Stopping as %s", reason)
+
+		deadline := time.Now().Add(autoStopGracePeriod)
+		for atomic.LoadInt64(&inFlightCalls) > 0 && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if atomic.LoadInt64(&inFlightCalls) > 0 {
+			log.Printf("WARNING: stopping with %d calls still in flight", atomic.LoadInt64(&inFlightCalls))
+		}
+
+		writePolicyToFile()
+		exitSession()
+	})
+}
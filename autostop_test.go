@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordTestAutoStopCall records a successful call, as the proxy does once it completes
+func recordTestAutoStopCall(service string, method string) {
+	recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: service, Method: method, FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+}
+
+// runTestAutoStop runs a test again as a separate process, as a stop ends the session with os.Exit, returning its
+// output once it has exited by itself and the actions of the policy file it wrote
+func runTestAutoStop(t *testing.T, name string) (string, []string) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "policy.json")
+	cmd := exec.Command(os.Args[0], "-test.run=^"+name+"$")
+	cmd.Env = append(os.Environ(), "TEST_AUTO_STOP_OUTPUT_FILE="+file)
+
+	done := make(chan error, 1)
+	var output []byte
+	go func() {
+		var err error
+		output, err = cmd.CombinedOutput()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("the session ended with %v, want exit code 0:\n%s", err, output)
+		}
+	case <-time.After(20 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("the session did not stop by itself")
+	}
+
+	actions := getTestPolicyFileActions(t, file)
+	sort.Strings(actions)
+	return string(output), actions
+}
+
+func TestTimeoutStop(t *testing.T) {
+	if file := os.Getenv("TEST_AUTO_STOP_OUTPUT_FILE"); file != "" {
+		setTestFlag(t, "output-file", file)
+		setTestFlag(t, "timeout", "300ms")
+		startAutoStop()
+
+		// each call is within the timeout of the previous one, so the session outlasts a single timeout
+		recordTestAutoStopCall("S3", "ListBuckets")
+		time.Sleep(200 * time.Millisecond)
+		recordTestAutoStopCall("EC2", "DescribeInstances")
+		time.Sleep(200 * time.Millisecond)
+		recordTestAutoStopCall("DynamoDB", "ListTables")
+		time.Sleep(time.Minute)
+		return
+	}
+
+	output, actions := runTestAutoStop(t, "TestTimeoutStop")
+
+	if want := []string{"dynamodb:ListTables", "ec2:DescribeInstances", "s3:ListAllMyBuckets"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("got actions %v, want %v", actions, want)
+	}
+	if !strings.Contains(output, "Stopping as no AWS calls were made for 300ms") {
+		t.Errorf("got output %q, want the inactivity stop logged", output)
+	}
+}
+
+func TestMaxUniquePairsStop(t *testing.T) {
+	if file := os.Getenv("TEST_AUTO_STOP_OUTPUT_FILE"); file != "" {
+		setTestFlag(t, "output-file", file)
+		setTestFlag(t, "max-unique-pairs", "2")
+		startAutoStop()
+
+		// a proxied call is still in flight when the limit is reached
+		startInFlightCall()
+		recordTestAutoStopCall("S3", "ListBuckets")
+		recordTestAutoStopCall("S3", "ListBuckets") // a repeated pair does not count
+		recordTestAutoStopCall("EC2", "DescribeInstances")
+		time.Sleep(300 * time.Millisecond)
+		recordTestAutoStopCall("DynamoDB", "ListTables")
+		endInFlightCall()
+		time.Sleep(time.Minute)
+		return
+	}
+
+	output, actions := runTestAutoStop(t, "TestMaxUniquePairsStop")
+
+	// the call in flight completed before the policy was written
+	if want := []string{"dynamodb:ListTables", "ec2:DescribeInstances", "s3:ListAllMyBuckets"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("got actions %v, want %v", actions, want)
+	}
+	if !strings.Contains(output, "Stopping as 2 unique calls were captured") {
+		t.Errorf("got output %q, want the unique pair stop logged", output)
+	}
+}
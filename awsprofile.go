@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/ini.v1"
+)
+
+// AWSProfile is the configuration and credentials of a named profile, used by features that call AWS APIs
+type AWSProfile struct {
+	Name             string
+	Region           string
+	CredentialSource string
+	RoleARN          string
+	SourceProfile    string
+	AccessKeyID      string
+	SecretAccessKey  string
+	SessionToken     string
+}
+
+var awsProfile *AWSProfile
+
+func getAWSSharedFile(envVar string, defaultPath string) (string, error) {
+	if path := os.Getenv(envVar); path != "" {
+		return homedir.Expand(path)
+	}
+
+	return homedir.Expand(defaultPath)
+}
+
+func loadAWSProfile() error {
+	if *awsProfileFlag == "" {
+		return nil
+	}
+
+	configFile, err := getAWSSharedFile("AWS_CONFIG_FILE", "~/.aws/config")
+	if err != nil {
+		return err
+	}
+	credentialsFile, err := getAWSSharedFile("AWS_SHARED_CREDENTIALS_FILE", "~/.aws/credentials")
+	if err != nil {
+		return err
+	}
+
+	cfg, err := ini.LooseLoad(configFile)
+	if err != nil {
+		return err
+	}
+	creds, err := ini.LooseLoad(credentialsFile)
+	if err != nil {
+		return err
+	}
+
+	sectionName := fmt.Sprintf("profile %s", *awsProfileFlag)
+	if *awsProfileFlag == "default" {
+		sectionName = "default"
+	}
+	section, err := cfg.GetSection(sectionName)
+	_, credsErr := creds.GetSection(*awsProfileFlag)
+	if err != nil && credsErr != nil {
+		return fmt.Errorf("the AWS profile %q could not be found", *awsProfileFlag)
+	}
+
+	profile := AWSProfile{
+		Name: *awsProfileFlag,
+	}
+	if section != nil {
+		profile.Region = section.Key("region").String()
+		profile.CredentialSource = section.Key("credential_source").String()
+		profile.RoleARN = section.Key("role_arn").String()
+		profile.SourceProfile = section.Key("source_profile").String()
+	}
+
+	// static credentials are read from the source profile when assuming a role
+	credentialsProfile := *awsProfileFlag
+	if profile.SourceProfile != "" {
+		credentialsProfile = profile.SourceProfile
+	}
+	for _, credentialsSection := range []*ini.Section{creds.Section(credentialsProfile), cfg.Section(sectionName)} {
+		if profile.AccessKeyID == "" && credentialsSection.HasKey("aws_access_key_id") {
+			profile.AccessKeyID = credentialsSection.Key("aws_access_key_id").String()
+			profile.SecretAccessKey = credentialsSection.Key("aws_secret_access_key").String()
+			profile.SessionToken = credentialsSection.Key("aws_session_token").String()
+		}
+	}
+
+	awsProfile = &profile
+	return nil
+}
+
+// getAWSRegion returns the region to use when calling AWS APIs
+func getAWSRegion() string {
+	if awsProfile != nil && awsProfile.Region != "" {
+		return awsProfile.Region
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+
+	return "us-east-1"
+}
+
+// getAWSCredentials returns the credentials to use when calling AWS APIs, from the --aws-profile if set and
+// otherwise the environment
+func getAWSCredentials() (accessKeyID string, secretAccessKey string, sessionToken string, err error) {
+	if awsProfile != nil {
+		if awsProfile.AccessKeyID == "" {
+			return "", "", "", fmt.Errorf("the AWS profile %q has no static credentials", awsProfile.Name)
+		}
+		return awsProfile.AccessKeyID, awsProfile.SecretAccessKey, awsProfile.SessionToken, nil
+	}
+
+	accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return "", "", "", fmt.Errorf("no AWS credentials were found, set --aws-profile or the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+	}
+
+	return accessKeyID, secretAccessKey, os.Getenv("AWS_SESSION_TOKEN"), nil
+}
+
+// loadAWSConfig returns the configuration of the AWS SDK clients for a region, or the region of getAWSRegion if
+// none is given. Credentials come from the --aws-profile if set and otherwise the default credential chain, and are
+// checked here so that a missing profile or credentials fail on start.
+func loadAWSConfig(region string) (aws.Config, error) {
+	if region == "" {
+		region = getAWSRegion()
+	}
+
+	optFns := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if *awsProfileFlag != "" {
+		optFns = append(optFns, config.WithSharedConfigProfile(*awsProfileFlag))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), optFns...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if _, err := cfg.Credentials.Retrieve(context.Background()); err != nil {
+		return aws.Config{}, fmt.Errorf("no AWS credentials were found: %v", err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// interfaceListenerRefresh is how often an idle interface listener checks for a new IP address
+const interfaceListenerRefresh = 5 * time.Second
+
+// getInterfaceIP returns the current IP address of a network interface, preferring IPv4
+func getInterfaceIP(name string) (net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+
+	if fallback == nil {
+		return nil, fmt.Errorf("interface %s has no usable IP address", name)
+	}
+
+	return fallback, nil
+}
+
+// interfaceListener listens on the current IP address of a network interface, rebinding when it changes (e.g.
+// on DHCP lease renewal)
+type interfaceListener struct {
+	name string
+	port string
+
+	mutex    sync.Mutex
+	ip       net.IP
+	listener *net.TCPListener
+}
+
+func newInterfaceListener(name string, port string) (*interfaceListener, error) {
+	l := &interfaceListener{
+		name: name,
+		port: port,
+	}
+
+	if err := l.refresh(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// refresh rebinds the listener if the IP address of the interface has changed
+func (l *interfaceListener) refresh() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	ip, err := getInterfaceIP(l.name)
+	if err != nil {
+		if l.listener != nil {
+			return nil // keep the current binding until the interface has an address again
+		}
+		return err
+	}
+	if l.listener != nil && ip.Equal(l.ip) {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(ip.String(), l.port))
+	if err != nil {
+		return err
+	}
+	if l.listener != nil {
+		l.listener.Close()
+	}
+
+	l.ip = ip
+	l.listener = listener.(*net.TCPListener)
+	return nil
+}
+
+func (l *interfaceListener) Accept() (net.Conn, error) {
+	for {
+		if err := l.refresh(); err != nil {
+			return nil, err
+		}
+
+		l.mutex.Lock()
+		listener := l.listener
+		l.mutex.Unlock()
+
+		listener.SetDeadline(time.Now().Add(interfaceListenerRefresh))
+		conn, err := listener.Accept()
+		if err == nil {
+			return conn, nil
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			continue
+		}
+
+		return nil, err
+	}
+}
+
+func (l *interfaceListener) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.listener.Close()
+}
+
+func (l *interfaceListener) Addr() net.Addr {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.listener.Addr()
+}
+
+// getProxyListener returns the listener for the proxy, bound to the interface given by --bind-interface if set
+func getProxyListener(addr string) (net.Listener, error) {
+	if *bindInterfaceFlag == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInterfaceListener(*bindInterfaceFlag, port)
+}
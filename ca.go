@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"time"
+)
+
+const (
+	caKeyTypeRSA   = "rsa"
+	caKeyTypeECDSA = "ecdsa"
+)
+
+// generateCAKey generates the private key of a new CA, returning it with its PEM encoding
+func generateCAKey(keyType string) (crypto.Signer, *pem.Block, error) {
+	if keyType == caKeyTypeECDSA {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyBytes, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}, nil
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}, nil
+}
+
+// generateCA generates a self-signed CA certificate and key, both PEM encoded
+func generateCA(keyType string) ([]byte, []byte, error) {
+	ca := &x509.Certificate{
+		SerialNumber: big.NewInt(2019),
+		Subject: pkix.Name{
+			Organization:  []string{"iamlive CA"},
+			Country:       []string{"US"},
+			Province:      []string{""},
+			Locality:      []string{"San Francisco"},
+			StreetAddress: []string{"Golden Gate Bridge"},
+			PostalCode:    []string{"94016"},
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		IsCA:                  true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caPrivKey, caPrivKeyBlock, err := generateCAKey(keyType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, caPrivKey.Public(), caPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	caPEM := new(bytes.Buffer)
+	pem.Encode(caPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: caBytes,
+	})
+
+	return caPEM.Bytes(), pem.EncodeToMemory(caPrivKeyBlock), nil
+}
+
+// getCAKeyType returns the type of an existing CA key from its PEM block type
+func getCAKeyType(caKey []byte) (string, error) {
+	block, _ := pem.Decode(caKey)
+	if block == nil {
+		return "", fmt.Errorf("the CA key file is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return caKeyTypeRSA, nil
+	case "EC PRIVATE KEY":
+		return caKeyTypeECDSA, nil
+	case "PRIVATE KEY": // PKCS #8, which may hold either
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return "", err
+		}
+		switch key.(type) {
+		case *rsa.PrivateKey:
+			return caKeyTypeRSA, nil
+		case *ecdsa.PrivateKey:
+			return caKeyTypeECDSA, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported CA key type %q", block.Type)
+}
+
+// backUpCAFiles renames the existing CA files to <name>.bak.<timestamp>, so that a new CA is generated in their place
+func backUpCAFiles(paths ...string) error {
+	timestamp := time.Now().Format("20060102150405")
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		backupPath := path + ".bak." + timestamp
+		if err := os.Rename(path, backupPath); err != nil {
+			return err
+		}
+		log.Printf("Backed up %s to %s", path, backupPath)
+	}
+
+	return nil
+}
+
+// caExpiryWarningDays is how long before the CA certificate expires that a warning is logged on start
+const caExpiryWarningDays = 30
+
+// checkCAExpiry warns when the CA certificate is close to expiring, and fails once it has expired, as TLS
+// handshakes through the proxy would otherwise fail with certificate errors
+func checkCAExpiry(caCert *x509.Certificate, now time.Time) error {
+	if now.After(caCert.NotAfter) {
+		return fmt.Errorf("the CA certificate in --ca-bundle expired on %s, use --rotate-ca or delete the --ca-bundle and --ca-key files to generate a new CA, or extend the existing one with --ca-validity-from-existing", caCert.NotAfter.Format("2006-01-02"))
+	}
+
+	daysLeft := int(math.Ceil(caCert.NotAfter.Sub(now).Hours() / 24))
+	if daysLeft < caExpiryWarningDays {
+		log.Printf("WARNING: CA certificate expires in %d days, use --rotate-ca or --ca-validity-from-existing to renew it", daysLeft)
+	}
+
+	return nil
+}
+
+// extendCAValidity re-signs the CA certificate with its existing key pair so that it's valid for the given number of
+// days from now, leaving existing trust store entries valid
+func extendCAValidity(caCert []byte, caKey []byte, days int) ([]byte, error) {
+	keyPair, err := tls.X509KeyPair(caCert, caKey)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	ca := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               existing.Subject,
+		SubjectKeyId:          existing.SubjectKeyId,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(0, 0, days),
+		IsCA:                  existing.IsCA,
+		ExtKeyUsage:           existing.ExtKeyUsage,
+		KeyUsage:              existing.KeyUsage,
+		BasicConstraintsValid: existing.BasicConstraintsValid,
+		MaxPathLen:            existing.MaxPathLen,
+		MaxPathLenZero:        existing.MaxPathLenZero,
+	}
+
+	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, existing.PublicKey, keyPair.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not extend the CA validity: %v", err)
+	}
+
+	caPEM := new(bytes.Buffer)
+	pem.Encode(caPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: caBytes,
+	})
+
+	return caPEM.Bytes(), nil
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elazarl/goproxy"
+)
+
+// loadTestCAKeys loads the CA in a directory as on start, restoring the CA used by goproxy at the end of the test
+func loadTestCAKeys(t *testing.T, dir string, keyType string, rotate bool) error {
+	t.Helper()
+
+	setTestFlag(t, "ca-bundle", filepath.Join(dir, "ca.pem"))
+	setTestFlag(t, "ca-key", filepath.Join(dir, "ca.key"))
+	setTestFlag(t, "ca-key-type", keyType)
+	if rotate {
+		setTestFlag(t, "rotate-ca", "true")
+	}
+	ca, okConnect, mitmConnect, httpMitmConnect, rejectConnect := goproxy.GoproxyCa, goproxy.OkConnect, goproxy.MitmConnect, goproxy.HTTPMitmConnect, goproxy.RejectConnect
+	t.Cleanup(func() {
+		goproxy.GoproxyCa, goproxy.OkConnect, goproxy.MitmConnect, goproxy.HTTPMitmConnect, goproxy.RejectConnect = ca, okConnect, mitmConnect, httpMitmConnect, rejectConnect
+	})
+
+	return loadCAKeys()
+}
+
+// readTestPEMBlock returns the first PEM block of a file
+func readTestPEMBlock(t *testing.T, path string) *pem.Block {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("%s is not PEM encoded", path)
+	}
+	return block
+}
+
+// sendTestMitmRequest sends an HTTPS request through the proxy, trusting only the CA in a directory, and returns the
+// certificate that the proxy presented for the host
+func sendTestMitmRequest(t *testing.T, dir string) *x509.Certificate {
+	t.Helper()
+
+	upstreamServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(upstreamServer.Close)
+
+	proxy := newProxy()
+	proxy.Tr.Proxy = nil
+	proxy.Tr.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, upstreamServer.Listener.Addr().String())
+	}
+	proxyServer := httptest.NewServer(proxy)
+	t.Cleanup(proxyServer.Close)
+	proxyURL, err := url.Parse(proxyServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	caCert, err := ioutil.ReadFile(filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots.AppendCertsFromPEM(caCert)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+
+	resp, err := client.Post("https://sts.amazonaws.com/", "application/x-www-form-urlencoded; charset=utf-8", strings.NewReader("Action=GetCallerIdentity&Version=2011-06-15"))
+	if err != nil {
+		t.Fatalf("the intercepted connection was not trusted: %v", err)
+	}
+	resp.Body.Close()
+
+	return resp.TLS.PeerCertificates[0]
+}
+
+func TestLoadCAKeysKeyTypes(t *testing.T) {
+	tests := []struct {
+		keyType       string
+		wantBlockType string
+		wantAlgorithm x509.PublicKeyAlgorithm
+	}{
+		{keyType: caKeyTypeRSA, wantBlockType: "RSA PRIVATE KEY", wantAlgorithm: x509.RSA},
+		{keyType: caKeyTypeECDSA, wantBlockType: "EC PRIVATE KEY", wantAlgorithm: x509.ECDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyType, func(t *testing.T) {
+			resetTestCallLog(t)
+			dir := t.TempDir()
+
+			// generated on the first start
+			if err := loadTestCAKeys(t, dir, tt.keyType, false); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTestPEMBlock(t, filepath.Join(dir, "ca.key")).Type; got != tt.wantBlockType {
+				t.Errorf("got key block %s, want %s", got, tt.wantBlockType)
+			}
+			caCert, err := x509.ParseCertificate(readTestPEMBlock(t, filepath.Join(dir, "ca.pem")).Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if caCert.PublicKeyAlgorithm != tt.wantAlgorithm || !caCert.IsCA {
+				t.Errorf("got a %s certificate with IsCA %t, want a %s CA", caCert.PublicKeyAlgorithm, caCert.IsCA, tt.wantAlgorithm)
+			}
+
+			// loaded from the files on the next start
+			if err := loadTestCAKeys(t, dir, tt.keyType, false); err != nil {
+				t.Fatal(err)
+			}
+			if !goproxy.GoproxyCa.Leaf.Equal(caCert) {
+				t.Errorf("the CA was not loaded from --ca-bundle")
+			}
+
+			leaf := sendTestMitmRequest(t, dir)
+			if leaf.PublicKeyAlgorithm != tt.wantAlgorithm || leaf.Issuer.String() != caCert.Subject.String() {
+				t.Errorf("got a %s certificate issued by %s, want a %s certificate issued by the CA", leaf.PublicKeyAlgorithm, leaf.Issuer, tt.wantAlgorithm)
+			}
+			if entry := getSingleTestEntry(t); entry.Service != "STS" || entry.Method != "GetCallerIdentity" {
+				t.Errorf("got call %s.%s, want STS.GetCallerIdentity", entry.Service, entry.Method)
+			}
+		})
+	}
+}
+
+func TestLoadCAKeysDetectsKeyType(t *testing.T) {
+	dir := t.TempDir()
+	if err := loadTestCAKeys(t, dir, caKeyTypeECDSA, false); err != nil {
+		t.Fatal(err)
+	}
+	caCert := readTestPEMBlock(t, filepath.Join(dir, "ca.pem"))
+
+	// the default key type does not replace an existing ECDSA CA
+	if err := loadTestCAKeys(t, dir, caKeyTypeRSA, false); err != nil {
+		t.Fatal(err)
+	}
+	if goproxy.GoproxyCa.Leaf.PublicKeyAlgorithm != x509.ECDSA {
+		t.Errorf("got a %s CA, want the existing ECDSA CA", goproxy.GoproxyCa.Leaf.PublicKeyAlgorithm)
+	}
+	if got := readTestPEMBlock(t, filepath.Join(dir, "ca.pem")); string(got.Bytes) != string(caCert.Bytes) {
+		t.Errorf("the existing CA certificate was replaced")
+	}
+}
+
+func TestRotateCA(t *testing.T) {
+	dir := t.TempDir()
+	if err := loadTestCAKeys(t, dir, caKeyTypeECDSA, false); err != nil {
+		t.Fatal(err)
+	}
+	oldCert, err := ioutil.ReadFile(filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, err := ioutil.ReadFile(filepath.Join(dir, "ca.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadTestCAKeys(t, dir, caKeyTypeRSA, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, old := range map[string][]byte{"ca.pem": oldCert, "ca.key": oldKey} {
+		backups, err := filepath.Glob(filepath.Join(dir, name+".bak.*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(backups) != 1 {
+			t.Fatalf("got backups %v of %s, want 1", backups, name)
+		}
+		backup, err := ioutil.ReadFile(backups[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(backup) != string(old) {
+			t.Errorf("%s does not hold the previous %s", backups[0], name)
+		}
+	}
+
+	if got := readTestPEMBlock(t, filepath.Join(dir, "ca.key")).Type; got != "RSA PRIVATE KEY" {
+		t.Errorf("got key block %s after the rotation, want RSA PRIVATE KEY", got)
+	}
+	if newCert, _ := ioutil.ReadFile(filepath.Join(dir, "ca.pem")); string(newCert) == string(oldCert) {
+		t.Errorf("the CA certificate was not regenerated")
+	}
+
+	resetTestCallLog(t)
+	if leaf := sendTestMitmRequest(t, dir); leaf.PublicKeyAlgorithm != x509.RSA {
+		t.Errorf("got a %s host certificate, want one signed with the rotated RSA CA", leaf.PublicKeyAlgorithm)
+	}
+}
+
+func TestGetCAKeyType(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048) // smaller than a generated CA key, as only the type matters
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ecdsaKey, err := generateCA(caKeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaBlock, _ := pem.Decode(ecdsaKey)
+	parsed, err := x509.ParseECPrivateKey(ecdsaBlock.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaPKCS8, err := x509.MarshalPKCS8PrivateKey(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "RSA", key: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), want: caKeyTypeRSA},
+		{name: "ECDSA", key: ecdsaKey, want: caKeyTypeECDSA},
+		{name: "PKCS #8 RSA", key: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}), want: caKeyTypeRSA},
+		{name: "PKCS #8 ECDSA", key: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecdsaPKCS8}), want: caKeyTypeECDSA},
+		{name: "not PEM", key: []byte("not a key"), wantErr: true},
+		{name: "unsupported", key: pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte{1}}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getCAKeyType(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestCA writes a CA certificate valid between two times, with an ECDSA key, to ca.pem and ca.key in a directory
+func writeTestCA(t *testing.T, dir string, notBefore time.Time, notAfter time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(2019),
+		Subject:               pkix.Name{Organization: []string{"iamlive CA"}},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "ca.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateProxyExpiredCA(t *testing.T) {
+	// started again as a separate process, as the proxy exits with log.Fatal
+	if dir := os.Getenv("TEST_EXPIRED_CA_DIR"); dir != "" {
+		setTestFlag(t, "ca-bundle", filepath.Join(dir, "ca.pem"))
+		setTestFlag(t, "ca-key", filepath.Join(dir, "ca.key"))
+		createProxy("127.0.0.1:0")
+		return
+	}
+
+	dir := t.TempDir()
+	writeTestCA(t, dir, time.Date(2014, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCreateProxyExpiredCA$")
+	cmd.Env = append(os.Environ(), "TEST_EXPIRED_CA_DIR="+dir)
+	output, err := cmd.CombinedOutput()
+
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.Success() {
+		t.Fatalf("got error %v, want the proxy to exit with an error", err)
+	}
+	want := "the CA certificate in --ca-bundle expired on 2024-03-01, use --rotate-ca or delete the --ca-bundle and --ca-key files"
+	if !strings.Contains(string(output), want) {
+		t.Errorf("got output %q, want it to contain %q", output, want)
+	}
+}
+
+func TestCheckCAExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		notAfter    time.Time
+		wantErr     bool
+		wantWarning string
+	}{
+		{name: "valid", notAfter: now.AddDate(0, 0, 400)},
+		{name: "expires in 30 days", notAfter: now.AddDate(0, 0, 30)},
+		{name: "expires in 10 days", notAfter: now.AddDate(0, 0, 10), wantWarning: "WARNING: CA certificate expires in 10 days"},
+		{name: "expires today", notAfter: now.Add(time.Hour), wantWarning: "WARNING: CA certificate expires in 1 days"},
+		{name: "expired", notAfter: now.AddDate(0, 0, -1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			t.Cleanup(func() {
+				log.SetOutput(os.Stderr)
+			})
+
+			err := checkCAExpiry(&x509.Certificate{NotAfter: tt.notAfter}, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantWarning == "" && logs.Len() > 0 {
+				t.Errorf("got warning %q, want none", logs.String())
+			}
+			if tt.wantWarning != "" && !strings.Contains(logs.String(), tt.wantWarning) {
+				t.Errorf("got warning %q, want %q", logs.String(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestLoadCAKeysExtendsExpiredCA(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCA(t, dir, time.Now().AddDate(-1, 0, 0), time.Now().AddDate(0, 0, -1))
+	setTestFlag(t, "ca-validity-from-existing", "365")
+
+	if err := loadTestCAKeys(t, dir, caKeyTypeECDSA, false); err != nil {
+		t.Fatalf("got error %v, want the expired CA extended", err)
+	}
+	if days := time.Until(goproxy.GoproxyCa.Leaf.NotAfter).Hours() / 24; days < 364 {
+		t.Errorf("the CA is valid for %.0f days, want 365", days)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "sync"
+
+// CallLog is the log of captured calls, safe for concurrent use by the proxy's request handlers
+type CallLog struct {
+	mutex   sync.RWMutex
+	entries []Entry
+}
+
+// Append adds a call to the log
+func (l *CallLog) Append(entry Entry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.entries = append(l.entries, entry)
+}
+
+// Snapshot returns a copy of the calls in the log, which can be read while further calls are appended
+func (l *CallLog) Snapshot() []Entry {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return append([]Entry{}, l.entries...)
+}
+
+// Len returns the number of calls in the log
+func (l *CallLog) Len() int {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return len(l.entries)
+}
+
+// Retain removes the calls for which keep returns false
+func (l *CallLog) Retain(keep func(Entry) bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var retained []Entry
+	for _, entry := range l.entries {
+		if keep(entry) {
+			retained = append(retained, entry)
+		}
+	}
+	l.entries = retained
+}
+
+// Reset removes every call from the log
+func (l *CallLog) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.entries = nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+var requiredActions []string
+
+func loadRequiredActions() error {
+	if *checkRequiredActionsFlag == "" {
+		return nil
+	}
+
+	requiredActionsPath, err := homedir.Expand(*checkRequiredActionsFlag)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(requiredActionsPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(strings.Split(line, ":")) != 2 {
+			return fmt.Errorf("invalid required action %q, expected service:action", line)
+		}
+		requiredActions = append(requiredActions, line)
+	}
+
+	return scanner.Err()
+}
+
+func isCallLogEmpty() bool {
+	return callLog.Len() == 0
+}
+
+// setFailIfEmptyTimeout ends the session if no calls have been captured once the timeout elapses
+func setFailIfEmptyTimeout() {
+	time.AfterFunc(*failIfEmptyTimeoutFlag, func() {
+		if isCallLogEmpty() {
+			exitSession()
+		}
+	})
+}
+
+// getCapturedActions returns every action within the current policy
+func getCapturedActions() []string {
+	var actions []string
+	for _, statement := range getPolicy().Statement {
+		actions = append(actions, statement.Action...)
+	}
+
+	actions = uniqueSlice(actions)
+	sort.Strings(actions)
+	return actions
+}
+
+// runExitChecks runs the checks requested for the end of a capture session, returning the exit code
+func runExitChecks() int {
+	exitCode := 0
+
+	if *failIfEmptyFlag && isCallLogEmpty() {
+		fmt.Fprintln(os.Stderr, "ERROR: no AWS calls were captured, check that your application is configured to use iamlive")
+		exitCode = 1
+	}
+
+	if len(requiredActions) > 0 {
+		capturedActions := make(map[string]bool)
+		for _, action := range getCapturedActions() {
+			capturedActions[strings.ToLower(action)] = true
+		}
+
+		var missingActions []string
+		for _, requiredAction := range requiredActions {
+			if !capturedActions[strings.ToLower(requiredAction)] {
+				missingActions = append(missingActions, requiredAction)
+			}
+		}
+
+		if len(missingActions) > 0 {
+			fmt.Fprintf(os.Stderr, "ERROR: the following required actions were not captured:\n    %s\n", strings.Join(missingActions, "\n    "))
+			if exitCode == 0 {
+				exitCode = 3
+			}
+		}
+	}
+
+	if coverageServiceDefinition != nil {
+		observed, total := getCoverage()
+		coverage := 0.0
+		if total > 0 {
+			coverage = float64(observed) / float64(total) * 100
+		}
+
+		fmt.Printf("Coverage: %s %d/%d operations (%.1f%%)\n", coverageServiceDefinition.Metadata.ServiceID, observed, total, coverage)
+		if coverage < *minCoverageFlag {
+			fmt.Fprintf(os.Stderr, "ERROR: coverage is below the minimum of %.1f%%\n", *minCoverageFlag)
+			if exitCode == 0 {
+				exitCode = 4
+			}
+		}
+	}
+
+	if cloudTrailEventNames != nil {
+		reportCloudTrailDiscrepancies()
+	}
+
+	if knownActions != nil {
+		newActionWebhooks.Wait()
+
+		if newActions := getNewActions(); *failOnNewActionFlag && len(newActions) > 0 {
+			fmt.Fprintf(os.Stderr, "ERROR: the following actions were not seen in previous sessions:\n    %s\n", strings.Join(newActions, "\n    "))
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	return exitCode
+}
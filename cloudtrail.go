@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// cloudTrailEvent is the subset of a CloudTrail event needed to cross-reference it with captured calls, or to
+// import it as a call
+type cloudTrailEvent struct {
+	EventSource  string                 `json:"eventSource"`
+	EventName    string                 `json:"eventName"`
+	EventTime    time.Time              `json:"eventTime"`
+	AWSRegion    string                 `json:"awsRegion"`
+	ErrorCode    string                 `json:"errorCode"`
+	UserIdentity cloudTrailUserIdentity `json:"userIdentity"`
+	Resources    []struct {
+		ARN string `json:"ARN"`
+	} `json:"resources"`
+}
+
+type cloudTrailUserIdentity struct {
+	Type           string `json:"type"`
+	ARN            string `json:"arn"`
+	SessionContext struct {
+		SessionIssuer struct {
+			ARN string `json:"arn"`
+		} `json:"sessionIssuer"`
+	} `json:"sessionContext"`
+}
+
+// cloudTrailExport accepts either a CloudTrail log file or the output of aws cloudtrail lookup-events
+type cloudTrailExport struct {
+	Records []cloudTrailEvent `json:"Records"`
+	Events  []struct {
+		EventSource     string `json:"EventSource"`
+		EventName       string `json:"EventName"`
+		CloudTrailEvent string `json:"CloudTrailEvent"`
+	} `json:"Events"`
+}
+
+// parseCloudTrailEvents reads the events of a CloudTrail log file, which may be gzipped as delivered to S3, or of
+// the output of aws cloudtrail lookup-events
+func parseCloudTrailEvents(data []byte) ([]cloudTrailEvent, error) {
+	if len(data) > 1 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var export cloudTrailExport
+	err := json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, err
+	}
+
+	events := export.Records
+	for _, exportedEvent := range export.Events {
+		event := cloudTrailEvent{
+			EventSource: exportedEvent.EventSource,
+			EventName:   exportedEvent.EventName,
+		}
+		if exportedEvent.CloudTrailEvent != "" {
+			json.Unmarshal([]byte(exportedEvent.CloudTrailEvent), &event) // fills in the region, identity and resources
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// cloudTrailEventNames holds the event names seen in CloudTrail keyed by event source prefix (e.g. s3)
+var cloudTrailEventNames map[string]map[string]bool
+
+func loadCloudTrailEvents() error {
+	if *checkCloudTrailFlag == "" {
+		return nil
+	}
+
+	readServiceFiles()
+
+	eventsPath, err := homedir.Expand(*checkCloudTrailFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(eventsPath)
+	if err != nil {
+		return err
+	}
+
+	events, err := parseCloudTrailEvents(data)
+	if err != nil {
+		return fmt.Errorf("invalid CloudTrail events file: %v", err)
+	}
+
+	cloudTrailEventNames = make(map[string]map[string]bool)
+	for _, event := range events {
+		if event.EventSource == "" || event.EventName == "" {
+			continue
+		}
+		source := strings.SplitN(event.EventSource, ".", 2)[0]
+		if cloudTrailEventNames[source] == nil {
+			cloudTrailEventNames[source] = make(map[string]bool)
+		}
+		cloudTrailEventNames[source][event.EventName] = true
+	}
+
+	if len(cloudTrailEventNames) == 0 {
+		return fmt.Errorf("no events were found in the CloudTrail events file")
+	}
+
+	return nil
+}
+
+// getCallEventSource returns the CloudTrail event source prefix for a call, which is the endpoint prefix of its service
+func getCallEventSource(call Entry) string {
+	for _, serviceDefinition := range getServiceDefinitions() {
+		if serviceDefinition.Metadata.ServiceID == call.Service {
+			return serviceDefinition.Metadata.EndpointPrefix
+		}
+	}
+
+	return strings.ToLower(call.Service)
+}
+
+// getCloudTrailDiscrepancies compares the captured calls with the CloudTrail events, returning a description of each mismatch
+func getCloudTrailDiscrepancies() []string {
+	var discrepancies []string
+
+	calls := callLog.Snapshot()
+
+	matchedEvents := make(map[string]bool)
+	checkedCalls := make(map[string]bool)
+	for _, call := range calls {
+		source := getCallEventSource(call)
+		callKey := fmt.Sprintf("%s:%s", source, call.Method)
+		if checkedCalls[callKey] {
+			continue
+		}
+		checkedCalls[callKey] = true
+
+		eventNames, ok := cloudTrailEventNames[source]
+		if !ok {
+			continue // the export holds no events for this service
+		}
+		if !eventNames[call.Method] {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s.%s was captured but has no %s event in CloudTrail", call.Service, call.Method, source))
+			continue
+		}
+		matchedEvents[callKey] = true
+
+		actions := getActions(call.Service, call.Method)
+		if len(actions) == 0 {
+			continue // permissionless calls have no action to compare
+		}
+		actionMatches := false
+		for _, action := range actions {
+			actionParts := strings.SplitN(action, ":", 2)
+			if len(actionParts) == 2 && strings.EqualFold(actionParts[1], call.Method) {
+				actionMatches = true
+			}
+		}
+		if !actionMatches {
+			discrepancies = append(discrepancies, fmt.Sprintf("CloudTrail event %s.%s is authorized by %s rather than an action of the same name", source, call.Method, strings.Join(actions, ", ")))
+		}
+	}
+
+	var sources []string
+	for source := range cloudTrailEventNames {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		var unmatched []string
+		for eventName := range cloudTrailEventNames[source] {
+			if !matchedEvents[fmt.Sprintf("%s:%s", source, eventName)] {
+				unmatched = append(unmatched, eventName)
+			}
+		}
+		sort.Strings(unmatched)
+
+		for _, eventName := range unmatched {
+			discrepancies = append(discrepancies, fmt.Sprintf("CloudTrail event %s.%s does not match any captured call", source, eventName))
+		}
+	}
+
+	return discrepancies
+}
+
+// reportCloudTrailDiscrepancies prints the differences between the captured calls and the CloudTrail events
+func reportCloudTrailDiscrepancies() {
+	discrepancies := getCloudTrailDiscrepancies()
+	if len(discrepancies) == 0 {
+		fmt.Println("CloudTrail: all events match the captured calls")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "WARNING: the captured calls differ from CloudTrail:\n    %s\n", strings.Join(discrepancies, "\n    "))
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/mitchellh/go-homedir"
+)
+
+// cloudTrailEndpointPrefixes maps the event sources that differ from the endpoint prefix of their service, the
+// endpoint prefixes of a source are tried in order
+var cloudTrailEndpointPrefixes = map[string][]string{
+	"detective":   {"api.detective"},
+	"ecr":         {"api.ecr"},
+	"ecr-public":  {"api.ecr-public"},
+	"iotwireless": {"api.iotwireless"},
+	"lex":         {"models.lex", "runtime.lex", "models-v2-lex", "runtime-v2-lex"},
+	"mediatailor": {"api.mediatailor"},
+	"pricing":     {"api.pricing"},
+	"sagemaker":   {"api.sagemaker", "runtime.sagemaker", "featurestore-runtime.sagemaker"},
+	"ses":         {"email"},
+	"timestream":  {"ingest.timestream", "query.timestream"},
+}
+
+// cloudTrailEventNameOverrides maps the event names that CloudTrail records under the name of an older operation,
+// keyed by event source prefix
+var cloudTrailEventNameOverrides = map[string]map[string]string{
+	"s3": {
+		"GetBucketLifecycle":    "GetBucketLifecycleConfiguration",
+		"PutBucketLifecycle":    "PutBucketLifecycleConfiguration",
+		"GetBucketNotification": "GetBucketNotificationConfiguration",
+		"PutBucketNotification": "PutBucketNotificationConfiguration",
+	},
+}
+
+// cloudTrailVersionSuffixRegexp matches the API version some services append to event names, such as
+// CreateFunction20150331 or UpdateFunctionCode20150331v2 for Lambda and CreateDistribution2020_05_31 for CloudFront
+var cloudTrailVersionSuffixRegexp = regexp.MustCompile(`(20[0-9]{6}(v[0-9]+)?|20[0-9]{2}_[0-9]{2}_[0-9]{2})$`)
+
+// getCloudTrailOperation returns the service definition and operation of a CloudTrail event
+func getCloudTrailOperation(event cloudTrailEvent) (ServiceDefinition, string, bool) {
+	source := strings.SplitN(event.EventSource, ".", 2)[0]
+	endpointPrefixes, ok := cloudTrailEndpointPrefixes[source]
+	if !ok {
+		endpointPrefixes = []string{source}
+	}
+
+	eventName := event.EventName
+	if override, ok := cloudTrailEventNameOverrides[source][eventName]; ok {
+		eventName = override
+	}
+	names := []string{eventName}
+	if unversionedName := cloudTrailVersionSuffixRegexp.ReplaceAllString(eventName, ""); unversionedName != eventName {
+		names = append(names, unversionedName)
+	}
+
+	// several services share an endpoint prefix, such as RDS and DocDB, so the latest with the operation is used
+	for _, endpointPrefix := range endpointPrefixes {
+		for _, serviceDefinition := range getServiceDefinitions() {
+			if serviceDefinition.Metadata.EndpointPrefix != endpointPrefix {
+				continue
+			}
+			for _, name := range names {
+				if _, ok := serviceDefinition.Operations[name]; ok {
+					return serviceDefinition, name, true
+				}
+			}
+		}
+	}
+
+	return ServiceDefinition{}, "", false
+}
+
+// getCloudTrailStatusCode returns the status code of the call an event records, which is not part of the event
+func getCloudTrailStatusCode(event cloudTrailEvent) int {
+	switch {
+	case event.ErrorCode == "":
+		return 200
+	case strings.Contains(event.ErrorCode, "AccessDenied") || strings.Contains(event.ErrorCode, "Unauthorized"):
+		return 403
+	}
+
+	return 400
+}
+
+// parseCloudTrailTimeRange parses --cloudtrail-time-range, returning zero times for the sides left empty
+func parseCloudTrailTimeRange() (from time.Time, to time.Time, err error) {
+	if *cloudTrailTimeRangeFlag == "" {
+		return
+	}
+
+	timeRange := strings.Split(*cloudTrailTimeRangeFlag, ",")
+	if len(timeRange) != 2 {
+		return from, to, fmt.Errorf("--cloudtrail-time-range must be <from>,<to>")
+	}
+	if value := strings.TrimSpace(timeRange[0]); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return from, to, fmt.Errorf("invalid --cloudtrail-time-range start: %v", err)
+		}
+	}
+	if value := strings.TrimSpace(timeRange[1]); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return from, to, fmt.Errorf("invalid --cloudtrail-time-range end: %v", err)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return from, to, fmt.Errorf("the --cloudtrail-time-range end is before its start")
+	}
+
+	return from, to, nil
+}
+
+// isCloudTrailPrincipal returns whether an event was made by --cloudtrail-principal, either directly or through a
+// session of the role
+func isCloudTrailPrincipal(event cloudTrailEvent) bool {
+	if *cloudTrailPrincipalFlag == "" {
+		return true
+	}
+
+	return event.UserIdentity.ARN == *cloudTrailPrincipalFlag || event.UserIdentity.SessionContext.SessionIssuer.ARN == *cloudTrailPrincipalFlag
+}
+
+// readLocalCloudTrailFiles returns the contents of a CloudTrail log file, or of every file within a directory such
+// as a synced copy of the CloudTrail bucket
+func readLocalCloudTrailFiles(path string) ([][]byte, error) {
+	var files [][]byte
+	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filePath != path && strings.HasPrefix(info.Name(), ".")) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, data)
+		return nil
+	})
+
+	return files, err
+}
+
+// s3ClientOptions are applied to the S3 client of the import-cloudtrail command
+var s3ClientOptions []func(*s3.Options)
+
+// s3RequestTimeout bounds the listing of the bucket and each download of a log file
+const s3RequestTimeout = 60 * time.Second
+
+// newS3Client returns an S3 client for a bucket, in the region of the bucket rather than the default region so that
+// buckets in other regions can be read
+func newS3Client(bucket string) (*s3.Client, error) {
+	cfg, err := loadAWSConfig("")
+	if err != nil {
+		return nil, err
+	}
+	client := s3.NewFromConfig(cfg, s3ClientOptions...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+	region, err := manager.GetBucketRegion(ctx, client, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the region of the bucket %s: %v", bucket, err)
+	}
+	if region == cfg.Region {
+		return client, nil
+	}
+
+	return s3.NewFromConfig(cfg, append(s3ClientOptions, func(o *s3.Options) {
+		o.Region = region
+	})...), nil
+}
+
+// readS3CloudTrailFiles returns the contents of every object under an s3://bucket/prefix URI
+func readS3CloudTrailFiles(uri string) ([][]byte, error) {
+	s3URL, err := url.Parse(uri)
+	if err != nil || s3URL.Host == "" {
+		return nil, fmt.Errorf("invalid S3 URI %s, expected s3://bucket/prefix", uri)
+	}
+	bucket := s3URL.Host
+	prefix := strings.TrimPrefix(s3URL.Path, "/")
+
+	client, err := newS3Client(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+		page, err := paginator.NextPage(ctx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("could not list s3://%s/%s: %v", bucket, prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			if key := aws.ToString(object.Key); !strings.HasSuffix(key, "/") {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var files [][]byte
+	for _, key := range keys {
+		data, err := getS3Object(client, bucket, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read s3://%s/%s: %v", bucket, key, err)
+		}
+		files = append(files, data)
+	}
+
+	return files, nil
+}
+
+func getS3Object(client *s3.Client, bucket string, key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	output, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return ioutil.ReadAll(output.Body)
+}
+
+// getCloudTrailImportEntries converts the events of CloudTrail log files into calls, skipping those outside the
+// time range or made by another principal
+func getCloudTrailImportEntries(files [][]byte, from time.Time, to time.Time) []Entry {
+	var entries []Entry
+	unknownEvents := make(map[string]bool)
+	for i, data := range files {
+		events, err := parseCloudTrailEvents(data)
+		if err != nil {
+			log.Printf("WARNING: skipping CloudTrail log file %d: %v", i+1, err)
+			continue
+		}
+
+		for _, event := range events {
+			if event.EventSource == "" || event.EventName == "" || !isCloudTrailPrincipal(event) {
+				continue
+			}
+			if (!from.IsZero() && event.EventTime.Before(from)) || (!to.IsZero() && event.EventTime.After(to)) {
+				continue
+			}
+
+			serviceDef, operation, ok := getCloudTrailOperation(event)
+			if !ok {
+				unknownEvents[event.EventSource+" "+event.EventName] = true
+				continue
+			}
+
+			entry := Entry{
+				Region:              event.AWSRegion,
+				Type:                "ApiCall",
+				Service:             serviceDef.Metadata.ServiceID,
+				Method:              operation,
+				FinalHTTPStatusCode: getCloudTrailStatusCode(event),
+				Timestamp:           event.EventTime,
+			}
+			for _, resource := range event.Resources {
+				if resource.ARN != "" {
+					entry.ResourceARNs = append(entry.ResourceARNs, resource.ARN)
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(unknownEvents) > 0 {
+		var names []string
+		for name := range unknownEvents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Printf("WARNING: skipping %d CloudTrail events with no matching API operation: %s", len(names), strings.Join(names, ", "))
+	}
+
+	return entries
+}
+
+// importCloudTrail generates the policy from the events of the CloudTrail log files at a path or s3://bucket/prefix
+// URI, for the import-cloudtrail command. CloudTrail records the action and region of each call but not its
+// parameters, so the policy is generated in CSM mode.
+func importCloudTrail(source string) error {
+	from, to, err := parseCloudTrailTimeRange()
+	if err != nil {
+		return err
+	}
+
+	var files [][]byte
+	if strings.HasPrefix(source, "s3://") {
+		files, err = readS3CloudTrailFiles(source)
+	} else {
+		var path string
+		path, err = homedir.Expand(source)
+		if err != nil {
+			return err
+		}
+		files, err = readLocalCloudTrailFiles(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	entries := getCloudTrailImportEntries(files, from, to)
+	if len(entries) == 0 {
+		return fmt.Errorf("no CloudTrail events were found in %s", source)
+	}
+
+	*modeFlag = "csm"
+	for _, entry := range entries {
+		callLog.Append(entry)
+	}
+	defer printWildcardSuggestions(os.Stderr)
+
+	if isPolicyFileOutput() {
+		writePolicyToFile()
+		return nil
+	}
+
+	fmt.Println(string(getPolicyOutput()))
+	return nil
+}
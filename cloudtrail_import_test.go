@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+//go:embed testdata/cloudtrail.json
+var bTestCloudTrail []byte
+
+// getTestCloudTrailFile returns the sample CloudTrail log file, gzipped as CloudTrail delivers it
+func getTestCloudTrailFile() []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write(bTestCloudTrail)
+	writer.Close()
+
+	return buf.Bytes()
+}
+
+// writeTestCloudTrailDir writes the sample CloudTrail log file to a directory
+func writeTestCloudTrailDir(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "AWSLogs", "210987654321", "CloudTrail", "us-east-1")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "210987654321_CloudTrail_us-east-1_20240301T1000Z_sample.json.gz"), getTestCloudTrailFile(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Dir(filepath.Dir(dir))
+}
+
+func TestImportCloudTrail(t *testing.T) {
+	// lambda:CreateFunction brings in iam:PassRole, which the SAR lists as a dependent action
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		wantActions []string
+	}{
+		{
+			name:        "all events",
+			wantActions: []string{"dynamodb:GetItem", "ec2:DescribeInstances", "iam:PassRole", "lambda:CreateFunction", "s3:GetObject", "s3:PutLifecycleConfiguration"},
+		},
+		{
+			name:        "time range",
+			flags:       map[string]string{"cloudtrail-time-range": "2024-03-01T10:00:00Z,2024-03-01T12:00:00Z"},
+			wantActions: []string{"ec2:DescribeInstances", "iam:PassRole", "lambda:CreateFunction", "s3:GetObject", "s3:PutLifecycleConfiguration"},
+		},
+		{
+			name:        "open-ended time range",
+			flags:       map[string]string{"cloudtrail-time-range": "2024-03-01T11:00:00Z,"},
+			wantActions: []string{"dynamodb:GetItem", "ec2:DescribeInstances", "iam:PassRole", "lambda:CreateFunction"},
+		},
+		{
+			name:        "principal",
+			flags:       map[string]string{"cloudtrail-principal": "arn:aws:iam::210987654321:role/orders"},
+			wantActions: []string{"dynamodb:GetItem", "iam:PassRole", "lambda:CreateFunction", "s3:GetObject", "s3:PutLifecycleConfiguration"},
+		},
+		{
+			name:        "time range and principal",
+			flags:       map[string]string{"cloudtrail-time-range": ",2024-03-01T12:00:00Z", "cloudtrail-principal": "arn:aws:iam::210987654321:user/admin"},
+			wantActions: []string{"ec2:DescribeInstances"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			setTestFlag(t, "mode", "proxy")
+			file := filepath.Join(t.TempDir(), "policy.json")
+			setTestFlag(t, "output-file", file)
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+
+			if err := importCloudTrail(writeTestCloudTrailDir(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := getTestPolicyFileActions(t, file); !reflect.DeepEqual(got, tt.wantActions) {
+				t.Errorf("got actions %v, want %v", got, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestImportCloudTrailNoEvents(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+	setTestFlag(t, "cloudtrail-time-range", "2025-01-01T00:00:00Z,")
+
+	if err := importCloudTrail(writeTestCloudTrailDir(t)); err == nil {
+		t.Fatal("got no error for a time range without events")
+	}
+}
+
+// startTestS3 stands in for S3 until the end of the test
+func startTestS3(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTRAIL")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "trail-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	previous := s3ClientOptions
+	s3ClientOptions = []func(*s3.Options){func(o *s3.Options) {
+		o.EndpointResolver = s3.EndpointResolverFromURL(server.URL)
+		o.UsePathStyle = true
+		o.RetryMaxAttempts = 1
+	}}
+	t.Cleanup(func() {
+		s3ClientOptions = previous
+	})
+}
+
+func TestImportCloudTrailFromS3(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+	file := filepath.Join(t.TempDir(), "policy.json")
+	setTestFlag(t, "output-file", file)
+
+	// the bucket is in another region than the default, and its objects are listed over two pages
+	pages := map[string][]string{
+		"": {
+			"AWSLogs/210987654321/CloudTrail/",
+			"AWSLogs/210987654321/CloudTrail/us-east-1/210987654321_CloudTrail_us-east-1_20240301T1000Z_first.json.gz",
+		},
+		"page-2": {
+			"AWSLogs/210987654321/CloudTrail/us-east-1/210987654321_CloudTrail_us-east-1_20240301T1005Z_second.json.gz",
+		},
+	}
+	var gotObjects []string
+	startTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" && r.URL.Path == "/trail-bucket" {
+			w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+			return
+		}
+		if got := r.Header.Get("Authorization"); !strings.Contains(got, "Credential=AKIDTRAIL/") || !strings.Contains(got, "/eu-west-1/s3/") {
+			t.Errorf("the call is not signed for the region of the bucket: %s", got)
+		}
+
+		if r.URL.Path == "/trail-bucket" {
+			if got := r.URL.Query().Get("prefix"); got != "AWSLogs/210987654321/" {
+				t.Errorf("got prefix %q, want AWSLogs/210987654321/", got)
+			}
+			token := r.URL.Query().Get("continuation-token")
+			var contents string
+			for _, key := range pages[token] {
+				contents += fmt.Sprintf("<Contents><Key>%s</Key></Contents>", key)
+			}
+			truncated := ""
+			if token == "" {
+				truncated = "<IsTruncated>true</IsTruncated><NextContinuationToken>page-2</NextContinuationToken>"
+			}
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>trail-bucket</Name>%s%s</ListBucketResult>`, contents, truncated)
+			return
+		}
+
+		gotObjects = append(gotObjects, strings.TrimPrefix(r.URL.Path, "/trail-bucket/"))
+		w.Write(getTestCloudTrailFile())
+	})
+
+	if err := importCloudTrail("s3://trail-bucket/AWSLogs/210987654321/"); err != nil {
+		t.Fatal(err)
+	}
+
+	wantObjects := []string{pages[""][1], pages["page-2"][0]}
+	if !reflect.DeepEqual(gotObjects, wantObjects) {
+		t.Errorf("got objects %v, want %v", gotObjects, wantObjects)
+	}
+	want := []string{"dynamodb:GetItem", "ec2:DescribeInstances", "iam:PassRole", "lambda:CreateFunction", "s3:GetObject", "s3:PutLifecycleConfiguration"}
+	if got := getTestPolicyFileActions(t, file); !reflect.DeepEqual(got, want) {
+		t.Errorf("got actions %v, want %v", got, want)
+	}
+}
+
+func TestImportCloudTrailFromS3AccessDenied(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+
+	startTestS3(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("X-Amz-Bucket-Region", "us-east-1")
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	})
+
+	err := importCloudTrail("s3://trail-bucket/AWSLogs/")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("got error %v, want AccessDenied", err)
+	}
+}
+
+func TestParseImportCloudTrailCommand(t *testing.T) {
+	setTestFlag(t, "cloudtrail-time-range", "")
+	setTestFlag(t, "output-file", "")
+
+	if err := flag.CommandLine.Parse([]string{"--output-file", "policy.json", "import-cloudtrail", "s3://trail-bucket/AWSLogs/", "--cloudtrail-time-range", "2024-03-01T00:00:00Z,"}); err != nil {
+		t.Fatal(err)
+	}
+	command, args := parseCommand()
+
+	if command != "import-cloudtrail" || !reflect.DeepEqual(args, []string{"s3://trail-bucket/AWSLogs/"}) {
+		t.Errorf("got command %s %v, want import-cloudtrail [s3://trail-bucket/AWSLogs/]", command, args)
+	}
+	if *outputFileFlag != "policy.json" || *cloudTrailTimeRangeFlag != "2024-03-01T00:00:00Z," {
+		t.Errorf("got --output-file %q and --cloudtrail-time-range %q, want the flags around the command", *outputFileFlag, *cloudTrailTimeRangeFlag)
+	}
+}
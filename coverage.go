@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var coverageServiceDefinition *ServiceDefinition
+
+func normalizeServiceName(name string) string {
+	return strings.ToLower(strings.Replace(name, " ", "", -1))
+}
+
+func loadCoverageService() error {
+	if *coverageFlag == "" {
+		if *minCoverageFlag > 0 {
+			return fmt.Errorf("--min-coverage requires --coverage")
+		}
+		return nil
+	}
+
+	readServiceFiles()
+
+	// service definitions are sorted latest API version first
+	service := normalizeServiceName(*coverageFlag)
+	definitions := getServiceDefinitions()
+	for i, serviceDefinition := range definitions {
+		if normalizeServiceName(serviceDefinition.Metadata.EndpointPrefix) == service || normalizeServiceName(serviceDefinition.Metadata.ServiceID) == service {
+			coverageServiceDefinition = &definitions[i]
+			break
+		}
+	}
+
+	if coverageServiceDefinition == nil {
+		return fmt.Errorf("unknown coverage service %q", *coverageFlag)
+	}
+
+	return nil
+}
+
+// getCoverage returns the number of operations of the coverage service that were observed, and its total
+// number of operations
+func getCoverage() (int, int) {
+	service := normalizeServiceName(coverageServiceDefinition.Metadata.ServiceID)
+	observed := make(map[string]bool)
+
+	for _, entry := range callLog.Snapshot() {
+		if normalizeServiceName(entry.Service) != service {
+			continue
+		}
+		if _, ok := coverageServiceDefinition.Operations[entry.Method]; ok {
+			observed[entry.Method] = true
+		}
+	}
+
+	return len(observed), len(coverageServiceDefinition.Operations)
+}
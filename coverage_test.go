@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// loadTestCoverageService loads the --coverage service, until the end of the test
+func loadTestCoverageService(t *testing.T, service string, minCoverage string) error {
+	t.Helper()
+
+	setTestFlag(t, "coverage", service)
+	setTestFlag(t, "min-coverage", minCoverage)
+	t.Cleanup(func() {
+		coverageServiceDefinition = nil
+	})
+
+	return loadCoverageService()
+}
+
+func TestLoadCoverageService(t *testing.T) {
+	tests := []struct {
+		service     string
+		minCoverage string
+		wantService string
+		wantErr     bool
+	}{
+		{service: "ecs", minCoverage: "0", wantService: "ECS"},
+		{service: "ECS", minCoverage: "0", wantService: "ECS"},
+		{service: "Elastic Load Balancing v2", minCoverage: "0", wantService: "Elastic Load Balancing v2"},
+		{service: "", minCoverage: "0"},
+		{service: "", minCoverage: "50", wantErr: true},
+		{service: "not-a-service", minCoverage: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service+" "+tt.minCoverage, func(t *testing.T) {
+			err := loadTestCoverageService(t, tt.service, tt.minCoverage)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantService == "" {
+				if coverageServiceDefinition != nil {
+					t.Errorf("got coverage service %s, want none", coverageServiceDefinition.Metadata.ServiceID)
+				}
+				return
+			}
+			if coverageServiceDefinition == nil || coverageServiceDefinition.Metadata.ServiceID != tt.wantService {
+				t.Errorf("got coverage service %v, want %s", coverageServiceDefinition, tt.wantService)
+			}
+		})
+	}
+}
+
+func TestGetCoverage(t *testing.T) {
+	resetTestCallLog(t)
+	if err := loadTestCoverageService(t, "ecs", "0"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, call := range []Entry{
+		{Service: "ECS", Method: "ListClusters"},
+		{Service: "ECS", Method: "ListClusters"},
+		{Service: "ECS", Method: "DescribeServices"},
+		{Service: "ECS", Method: "NotAnOperation"},
+		{Service: "EC2", Method: "DescribeInstances"},
+	} {
+		call.Region = "us-east-1"
+		call.Type = "ApiCall"
+		call.FinalHTTPStatusCode = 200
+		call.Timestamp = time.Now()
+		callLog.Append(call)
+	}
+
+	observed, total := getCoverage()
+	if observed != 2 {
+		t.Errorf("got %d observed operations, want 2", observed)
+	}
+	if total != len(coverageServiceDefinition.Operations) || total < 40 {
+		t.Errorf("got %d operations in total, want the %d ECS operations", total, len(coverageServiceDefinition.Operations))
+	}
+}
+
+func TestMinCoverageExitCode(t *testing.T) {
+	tests := []struct {
+		minCoverage  string
+		wantExitCode int
+	}{
+		{minCoverage: "0", wantExitCode: 0},
+		{minCoverage: "1", wantExitCode: 0},
+		{minCoverage: "50", wantExitCode: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.minCoverage, func(t *testing.T) {
+			resetTestCallLog(t)
+			if err := loadTestCoverageService(t, "ecs", tt.minCoverage); err != nil {
+				t.Fatal(err)
+			}
+			callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "ECS", Method: "ListClusters", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+			if got := runExitChecks(); got != tt.wantExitCode {
+				t.Errorf("got exit code %d, want %d", got, tt.wantExitCode)
+			}
+		})
+	}
+}
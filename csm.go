@@ -11,6 +11,7 @@ import (
 	"runtime/pprof"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"gopkg.in/ini.v1"
@@ -56,54 +57,142 @@ func setINIConfigAndFileFlush() {
 	go func() {
 		for s := range sigc {
 			// flush to file
-			if *outputFileFlag != "" {
-				err := ioutil.WriteFile(*outputFileFlag, getPolicyDocument(), 0644)
-				if err != nil {
-					log.Fatalf("Error writing policy to %s", *outputFileFlag)
-				}
-			}
+			writePolicyToFile()
 
 			if s == syscall.SIGINT || s == syscall.SIGTERM || s == syscall.SIGQUIT {
-				if *setiniFlag {
-					// revert ini
-					cfgfile, err := homedir.Expand("~/.aws/config") // need to redeclare
-					if err != nil {
-						os.Exit(1)
-					}
-
-					cfg, err := ini.Load(cfgfile)
-					if err != nil {
-						os.Exit(1)
-					}
-
-					if *profileFlag == "default" {
-						if *modeFlag == "csm" {
-							cfg.Section("default").DeleteKey("csm_enabled")
-						} else if *modeFlag == "proxy" {
-							cfg.Section("default").DeleteKey("ca_bundle")
-						}
-					} else {
-						if *modeFlag == "csm" {
-							cfg.Section(fmt.Sprintf("profile %s", *profileFlag)).DeleteKey("csm_enabled")
-						} else if *modeFlag == "proxy" {
-							cfg.Section(fmt.Sprintf("profile %s", *profileFlag)).DeleteKey("ca_bundle")
-						}
-					}
-					cfg.SaveTo(cfgfile)
-				}
+				exitSession()
+			}
+		}
+	}()
+}
+
+// exitSession cleans up and exits the process at the end of a capture session
+func exitSession() {
+	if *setiniFlag {
+		// revert ini
+		cfgfile, err := homedir.Expand("~/.aws/config")
+		if err != nil {
+			os.Exit(1)
+		}
 
-				pprof.StopCPUProfile()
+		cfg, err := ini.Load(cfgfile)
+		if err != nil {
+			os.Exit(1)
+		}
 
-				// exit
-				os.Exit(0)
+		if *profileFlag == "default" {
+			if *modeFlag == "csm" {
+				cfg.Section("default").DeleteKey("csm_enabled")
+			} else if *modeFlag == "proxy" {
+				cfg.Section("default").DeleteKey("ca_bundle")
+			}
+		} else {
+			if *modeFlag == "csm" {
+				cfg.Section(fmt.Sprintf("profile %s", *profileFlag)).DeleteKey("csm_enabled")
+			} else if *modeFlag == "proxy" {
+				cfg.Section(fmt.Sprintf("profile %s", *profileFlag)).DeleteKey("ca_bundle")
 			}
 		}
-	}()
+		cfg.SaveTo(cfgfile)
+	}
+
+	pprof.StopCPUProfile()
+
+	printWildcardSuggestions(os.Stderr)
+
+	exportsOK := runExitExports()
+
+	// exit
+	exitCode := runExitChecks()
+	if !exportsOK && exitCode == 0 {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
 }
 
-func listenForEvents() {
-	var iamMap iamMapBase
+// policySplitCount is the number of files the policy was last split into, so the split is only reported when it
+// changes
+var policySplitCount = 1
+
+// writeSplitPolicyToFiles writes a policy document exceeding --max-policy-size to numbered files beside
+// --output-file, returning false if the policy fits in a single document
+func writeSplitPolicyToFiles() bool {
+	if *outputFormatFlag != "json" {
+		return false
+	}
+
+	files, err := writeSplitPolicyFiles(*outputFileFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return len(files) > 0
+}
+
+// writeSplitPolicyFiles writes a policy document exceeding --max-policy-size to numbered files beside a file,
+// returning the files written or none if the policy fits in a single document
+func writeSplitPolicyFiles(policyFile string) ([]string, error) {
+	policies := checkAndSplitPolicy(getPolicy(), *maxPolicySizeFlag)
+	if len(policies) == 1 {
+		policySplitCount = 1
+		return nil, nil
+	}
+
+	var files []string
+	for i, policy := range policies {
+		doc, err := json.MarshalIndent(policy, "", "    ")
+		if err != nil {
+			panic(err)
+		}
 
+		file := getCompanionFile(policyFile, fmt.Sprintf("-%d", i+1))
+		err = ioutil.WriteFile(file, doc, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("could not write the policy to %s: %v", file, err)
+		}
+		files = append(files, file)
+	}
+
+	if len(policies) != policySplitCount {
+		policySplitCount = len(policies)
+		log.Printf("WARNING: Policy document exceeded limit; split into %d files: %s.", len(files), strings.Join(files, ", "))
+	}
+
+	return files, nil
+}
+
+func writePolicyToFile() {
+	if len(selectedOutputFormats) > 0 {
+		if err := writeOutputFormats(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *outputFileFlag != "" {
+		if !writeSplitPolicyToFiles() {
+			err := ioutil.WriteFile(*outputFileFlag, getPolicyOutput(), 0644)
+			if err != nil {
+				log.Fatalf("Error writing policy to %s", *outputFileFlag)
+			}
+		}
+
+		if *splitByPrincipalFlag {
+			writePrincipalPoliciesToFiles()
+		}
+
+		for _, additionalOutput := range getAdditionalOutputs() {
+			additionalOutputFile := getAdditionalOutputFile(additionalOutput.Suffix)
+			err := ioutil.WriteFile(additionalOutputFile, additionalOutput.Output, 0644)
+			if err != nil {
+				log.Fatalf("Error writing policy to %s", additionalOutputFile)
+			}
+		}
+	}
+}
+
+// csmCallType is the type of calls recorded from CSM datagrams
+const csmCallType = "CSMCall"
+
+func listenForEvents() {
 	addr := net.UDPAddr{
 		Port: 31000,
 		IP:   net.ParseIP(*hostFlag),
@@ -118,38 +207,71 @@ func listenForEvents() {
 	}
 	defer conn.Close()
 
-	err = json.Unmarshal(bIAMMap, &iamMap)
+	err = readCSMEvents(conn)
 	if err != nil {
 		panic(err)
 	}
+}
+
+// readCSMEvents records the calls reported by the CSM datagrams received on a connection, until a read fails
+func readCSMEvents(conn net.PacketConn) error {
+	var iamMap iamMapBase
+	err := json.Unmarshal(bIAMMap, &iamMap)
+	if err != nil {
+		return err
+	}
 
 	var buf [1048576]byte
 	for {
-		rlen, _, err := conn.ReadFromUDP(buf[:])
+		rlen, _, err := conn.ReadFrom(buf[:])
 		if err != nil {
-			panic(err)
+			return err
 		}
 
-		entries := strings.Split(string(buf[0:rlen]), "\n")
+		handleCSMDatagram(buf[0:rlen], iamMap)
+	}
+}
 
-	EntryLoop:
-		for _, entry := range entries {
-			var e Entry
+// handleCSMDatagram records the calls within a CSM datagram, which holds a JSON record on each line
+func handleCSMDatagram(datagram []byte, iamMap iamMapBase) {
+	entries := strings.Split(string(datagram), "\n")
 
-			err := json.Unmarshal([]byte(entry), &e)
-			if err != nil {
-				panic(err)
+EntryLoop:
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		var e Entry
+
+		err := json.Unmarshal([]byte(entry), &e)
+		if err != nil {
+			log.Printf("WARNING: could not parse a CSM datagram: %v", err)
+			continue
+		}
+
+		// checked if permissionless
+		for _, permissionlessAction := range iamMap.SDKPermissionlessActions {
+			if strings.ToLower(permissionlessAction) == fmt.Sprintf("%s.%s", strings.ToLower(e.Service), strings.ToLower(e.Method)) {
+				continue EntryLoop
 			}
+		}
 
-			// checked if permissionless
-			for _, permissionlessAction := range iamMap.SDKPermissionlessActions {
-				if strings.ToLower(permissionlessAction) == fmt.Sprintf("%s.%s", strings.ToLower(e.Service), strings.ToLower(e.Method)) {
-					continue EntryLoop
+		// attempts are also reported, only the overall call is recorded
+		if e.Type == "ApiCall" {
+			e.Type = csmCallType
+			e.Timestamp = time.Now()
+
+			if isOutputFormatSelected("github-secret-scanning") {
+				var csmCredentials struct {
+					AccessKey string `json:"AccessKey"`
+				}
+				if json.Unmarshal([]byte(entry), &csmCredentials) == nil {
+					recordObservedAccessKey(csmCredentials.AccessKey, e.Service)
 				}
 			}
 
-			if e.Type == "ApiCall" {
-				callLog = append(callLog, e)
+			if recordCall(e) {
 				handleLoggedCall()
 			}
 		}
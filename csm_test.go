@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestCSMListener reads CSM datagrams on a loopback port until the end of the test, returning a connection
+// to send them from
+func startTestCSMListener(t *testing.T) net.Conn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		readCSMEvents(conn)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		conn.Close()
+		<-done
+	})
+
+	writer, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		writer.Close()
+	})
+
+	return writer
+}
+
+// waitForTestCalls waits for a number of calls to be recorded
+func waitForTestCalls(t *testing.T, count int) []Entry {
+	t.Helper()
+
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if callLog.Len() >= count {
+			return callLog.Snapshot()
+		}
+	}
+
+	t.Fatalf("got %d calls, want %d", callLog.Len(), count)
+	return nil
+}
+
+func TestCSMDatagrams(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "csm")
+	writer := startTestCSMListener(t)
+
+	datagrams := []string{
+		// an attempt and the overall call, as the SDKs report them
+		`{"Version":1,"ClientId":"","Type":"ApiCallAttempt","Service":"S3","Api":"ListBuckets","Timestamp":1709287200000,"Region":"us-east-1","HttpStatusCode":200}`,
+		`{"Version":1,"ClientId":"","Type":"ApiCall","Service":"S3","Api":"ListBuckets","Timestamp":1709287200000,"AttemptCount":1,"Region":"us-east-1","FinalHttpStatusCode":200,"Latency":52}`,
+		// several records in one datagram, with a malformed and a blank line
+		"{\"Version\":1,\"Type\":\"ApiCall\",\"Service\":\"EC2\",\"Api\":\"DescribeInstances\",\"Region\":\"eu-west-1\",\"FinalHttpStatusCode\":403}\n{not json\n\n" +
+			`{"Version":1,"Type":"ApiCall","Service":"DynamoDB","Api":"ListTables","Region":"ap-southeast-2","FinalHttpStatusCode":200}`,
+		// permissionless calls are not recorded
+		`{"Version":1,"Type":"ApiCall","Service":"STS","Api":"GetCallerIdentity","Region":"us-east-1","FinalHttpStatusCode":200}`,
+		`{"Version":1,"Type":"ApiCall","Service":"SQS","Api":"ListQueues","Region":"us-west-2","FinalHttpStatusCode":200}`,
+	}
+	for _, datagram := range datagrams {
+		if _, err := writer.Write([]byte(datagram)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := waitForTestCalls(t, 4)
+	var got []string
+	for _, entry := range entries {
+		if entry.Type != csmCallType {
+			t.Errorf("got type %s for %s.%s, want %s", entry.Type, entry.Service, entry.Method, csmCallType)
+		}
+		if entry.Timestamp.IsZero() {
+			t.Errorf("%s.%s has no timestamp", entry.Service, entry.Method)
+		}
+		got = append(got, strings.Join([]string{entry.Service, entry.Method, entry.Region}, " "))
+	}
+	want := []string{"S3 ListBuckets us-east-1", "EC2 DescribeInstances eu-west-1", "DynamoDB ListTables ap-southeast-2", "SQS ListQueues us-west-2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got calls %v, want %v", got, want)
+	}
+	if entries[1].FinalHTTPStatusCode != 403 {
+		t.Errorf("got status code %d for EC2, want 403", entries[1].FinalHTTPStatusCode)
+	}
+	if len(entries[0].Parameters) != 0 || len(entries[0].URIParameters) != 0 {
+		t.Errorf("got parameters %v and URI parameters %v for a CSM call, want none", entries[0].Parameters, entries[0].URIParameters)
+	}
+}
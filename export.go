@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runExitExports sends the policy to any external destinations requested for the end of a capture session,
+// returning false if any of them failed
+func runExitExports() bool {
+	ok := true
+
+	if *exportToVaultFlag != "" {
+		if err := exportPolicyToVault(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: could not export the policy to Vault: %v\n", err)
+			ok = false
+		}
+	}
+
+	if *exportToGitHubGistFlag {
+		if err := exportPolicyToGitHubGist(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: could not export the policy to a GitHub Gist: %v\n", err)
+			ok = false
+		}
+	}
+
+	if dynamoDBExportQueue != nil {
+		if err := finishDynamoDBExport(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: could not export all calls to DynamoDB: %v\n", err)
+			ok = false
+		}
+	}
+
+	return ok
+}
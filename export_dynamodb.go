@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const dynamoDBBatchSize = 25 // the BatchWriteItem limit
+const dynamoDBMaxAttempts = 8
+const dynamoDBFlushInterval = time.Second
+const dynamoDBRequestTimeout = 30 * time.Second
+
+// dynamoDBClient is the client writing to the --export-to-dynamodb table
+var dynamoDBClient *dynamodb.Client
+
+var dynamoDBExportQueue chan Entry
+var dynamoDBExportDone chan struct{}
+var dynamoDBExportErrors int
+var dynamoDBExportMutex sync.Mutex
+var dynamoDBItemCount int64
+
+func startDynamoDBExport() error {
+	if *exportToDynamoDBFlag == "" {
+		return nil
+	}
+
+	cfg, err := loadAWSConfig(*dynamoDBRegionFlag)
+	if err != nil {
+		return err
+	}
+	dynamoDBClient = dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.RetryMaxAttempts = dynamoDBMaxAttempts
+	})
+
+	dynamoDBExportQueue = make(chan Entry, 1000)
+	dynamoDBExportDone = make(chan struct{})
+	go runDynamoDBExport()
+
+	return nil
+}
+
+// queueDynamoDBExport queues a captured call to be written to the table, dropping it rather than holding up the
+// proxy if the table can't keep up
+func queueDynamoDBExport(entry Entry) {
+	select {
+	case dynamoDBExportQueue <- entry:
+	default:
+		log.Printf("WARNING: the DynamoDB export queue is full, a call was not exported")
+		dynamoDBExportMutex.Lock()
+		dynamoDBExportErrors++
+		dynamoDBExportMutex.Unlock()
+	}
+}
+
+func runDynamoDBExport() {
+	defer close(dynamoDBExportDone)
+
+	ticker := time.NewTicker(dynamoDBFlushInterval)
+	defer ticker.Stop()
+
+	var batch []Entry
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := writeDynamoDBBatch(batch); err != nil {
+			log.Printf("WARNING: could not export %d calls to DynamoDB: %v", len(batch), err)
+			dynamoDBExportMutex.Lock()
+			dynamoDBExportErrors += len(batch)
+			dynamoDBExportMutex.Unlock()
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case entry, ok := <-dynamoDBExportQueue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) == dynamoDBBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// finishDynamoDBExport writes any queued calls, returning an error if any call could not be exported
+func finishDynamoDBExport() error {
+	close(dynamoDBExportQueue)
+	<-dynamoDBExportDone
+
+	dynamoDBExportMutex.Lock()
+	defer dynamoDBExportMutex.Unlock()
+
+	if dynamoDBExportErrors > 0 {
+		return fmt.Errorf("%d calls could not be exported", dynamoDBExportErrors)
+	}
+	return nil
+}
+
+func dynamoDBString(s string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+func dynamoDBNumber(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+// getDynamoDBItem returns a call as a DynamoDB item, omitting empty attributes
+func getDynamoDBItem(entry Entry) map[string]types.AttributeValue {
+	dynamoDBExportMutex.Lock()
+	dynamoDBItemCount++
+	itemID := fmt.Sprintf("%d-%d", entry.Timestamp.UnixNano(), dynamoDBItemCount)
+	dynamoDBExportMutex.Unlock()
+
+	item := map[string]types.AttributeValue{
+		"Id":                  dynamoDBString(itemID),
+		"Timestamp":           dynamoDBString(entry.Timestamp.UTC().Format(time.RFC3339Nano)),
+		"FinalHttpStatusCode": dynamoDBNumber(int64(entry.FinalHTTPStatusCode)),
+	}
+
+	for name, value := range map[string]string{
+		"Region":           entry.Region,
+		"Type":             entry.Type,
+		"Service":          entry.Service,
+		"Api":              entry.Method,
+		"CorrelationId":    entry.CorrelationID,
+		"EventSource":      entry.EventSource,
+		"TimeBucket":       entry.TimeBucket,
+		"SourceAnnotation": entry.SourceAnnotation,
+		"SessionId":        entry.SessionID,
+	} {
+		if value != "" {
+			item[name] = dynamoDBString(value)
+		}
+	}
+
+	if entry.ResponseBodyBytes > 0 {
+		item["ResponseBodyBytes"] = dynamoDBNumber(entry.ResponseBodyBytes)
+	}
+
+	if len(entry.ResourceARNs) > 0 {
+		var list []types.AttributeValue
+		for _, arn := range entry.ResourceARNs {
+			list = append(list, dynamoDBString(arn))
+		}
+		item["ResourceARNs"] = &types.AttributeValueMemberL{Value: list}
+	}
+
+	if len(entry.Parameters) > 0 {
+		parameters := make(map[string]types.AttributeValue)
+		for name, values := range entry.Parameters {
+			var list []types.AttributeValue
+			for _, value := range values {
+				list = append(list, dynamoDBString(value))
+			}
+			parameters[name] = &types.AttributeValueMemberL{Value: list}
+		}
+		item["Parameters"] = &types.AttributeValueMemberM{Value: parameters}
+	}
+
+	for name, values := range map[string]map[string]string{
+		"URIParameters": entry.URIParameters,
+		"ConditionKeys": entry.ConditionKeys,
+		"Headers":       entry.Headers,
+	} {
+		if len(values) > 0 {
+			attributes := make(map[string]types.AttributeValue)
+			for key, value := range values {
+				attributes[key] = dynamoDBString(value)
+			}
+			item[name] = &types.AttributeValueMemberM{Value: attributes}
+		}
+	}
+
+	if *entryTTLFlag > 0 {
+		item["ExpiresAt"] = dynamoDBNumber(time.Now().Add(*entryTTLFlag).Unix())
+	}
+
+	return item
+}
+
+// writeDynamoDBBatch writes up to 25 calls with BatchWriteItem, retrying unprocessed items with exponential backoff.
+// Throttled and failed calls are retried by the client.
+func writeDynamoDBBatch(entries []Entry) error {
+	var writeRequests []types.WriteRequest
+	for _, entry := range entries {
+		writeRequests = append(writeRequests, types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: getDynamoDBItem(entry)},
+		})
+	}
+
+	backoff := 50 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		unprocessed, err := dynamoDBBatchWriteItem(writeRequests)
+		if err != nil {
+			return err
+		}
+		if len(unprocessed) == 0 {
+			return nil
+		}
+		if attempt == dynamoDBMaxAttempts {
+			return fmt.Errorf("%d items were still unprocessed after %d attempts", len(unprocessed), attempt)
+		}
+
+		writeRequests = unprocessed
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// dynamoDBBatchWriteItem makes a single BatchWriteItem call, returning the unprocessed items
+func dynamoDBBatchWriteItem(writeRequests []types.WriteRequest) ([]types.WriteRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dynamoDBRequestTimeout)
+	defer cancel()
+
+	output, err := dynamoDBClient.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{
+			*exportToDynamoDBFlag: writeRequests,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.UnprocessedItems[*exportToDynamoDBFlag], nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestGetDynamoDBItem(t *testing.T) {
+	entry := Entry{
+		Region:              "us-east-1",
+		Type:                "ProxyCall",
+		Service:             "S3",
+		Method:              "GetObject",
+		FinalHTTPStatusCode: 200,
+		Timestamp:           time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		EventSource:         "test",
+		SourceAnnotation:    "integration-test-run-42",
+		ResourceARNs:        []string{"arn:aws:s3:::orders-bucket/report.csv"},
+	}
+
+	item := getDynamoDBItem(entry)
+
+	for name, want := range map[string]types.AttributeValue{
+		"Region":              dynamoDBString("us-east-1"),
+		"Service":             dynamoDBString("S3"),
+		"Api":                 dynamoDBString("GetObject"),
+		"Timestamp":           dynamoDBString("2024-03-01T10:00:00Z"),
+		"FinalHttpStatusCode": dynamoDBNumber(200),
+		"EventSource":         dynamoDBString("test"),
+		"SourceAnnotation":    dynamoDBString("integration-test-run-42"),
+	} {
+		if !reflect.DeepEqual(item[name], want) {
+			t.Errorf("got %s %v, want %v", name, item[name], want)
+		}
+	}
+	for _, name := range []string{"CorrelationId", "SessionId", "TimeBucket", "ResponseBodyBytes"} {
+		if _, ok := item[name]; ok {
+			t.Errorf("got empty attribute %s, want it omitted", name)
+		}
+	}
+
+	// items of calls made at the same time have different IDs
+	if other := getDynamoDBItem(entry); reflect.DeepEqual(other["Id"], item["Id"]) {
+		t.Errorf("got the same ID %v for two items", item["Id"])
+	}
+}
+
+// startTestDynamoDB stands in for DynamoDB until the end of the test
+func startTestDynamoDB(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	previous := dynamoDBClient
+	dynamoDBClient = dynamodb.New(dynamodb.Options{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIDEXPORT", SecretAccessKey: "export-secret"}, nil
+		}),
+		EndpointResolver: dynamodb.EndpointResolverFromURL(server.URL),
+		RetryMaxAttempts: 1,
+	})
+	t.Cleanup(func() {
+		dynamoDBClient = previous
+	})
+}
+
+func TestWriteDynamoDBBatch(t *testing.T) {
+	setTestFlag(t, "export-to-dynamodb", "iamlive-calls")
+
+	var itemCounts []int
+	startTestDynamoDB(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Amz-Target"); got != "DynamoDB_20120810.BatchWriteItem" {
+			t.Errorf("got target %s, want DynamoDB_20120810.BatchWriteItem", got)
+		}
+		if got := r.Header.Get("Authorization"); !strings.Contains(got, "Credential=AKIDEXPORT/") {
+			t.Errorf("the call is not signed with the export credentials: %s", got)
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var input struct {
+			RequestItems map[string][]json.RawMessage
+		}
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Fatal(err)
+		}
+		requests := input.RequestItems["iamlive-calls"]
+		itemCounts = append(itemCounts, len(requests))
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if len(itemCounts) == 1 {
+			// the first item is left unprocessed, as when the table is throttled
+			w.Write([]byte(`{"UnprocessedItems":{"iamlive-calls":[` + string(requests[0]) + `]}}`))
+			return
+		}
+		w.Write([]byte(`{"UnprocessedItems":{}}`))
+	})
+
+	entries := []Entry{
+		{Region: "us-east-1", Type: "ProxyCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()},
+		{Region: "us-east-1", Type: "ProxyCall", Service: "EC2", Method: "DescribeInstances", FinalHTTPStatusCode: 200, Timestamp: time.Now()},
+	}
+	if err := writeDynamoDBBatch(entries); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 1}; !reflect.DeepEqual(itemCounts, want) {
+		t.Errorf("got BatchWriteItem calls of %v items, want %v", itemCounts, want)
+	}
+}
+
+func TestWriteDynamoDBBatchError(t *testing.T) {
+	setTestFlag(t, "export-to-dynamodb", "iamlive-calls")
+
+	startTestDynamoDB(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+	})
+
+	err := writeDynamoDBBatch([]Entry{{Region: "us-east-1", Type: "ProxyCall", Service: "S3", Method: "ListBuckets", Timestamp: time.Now()}})
+	if err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("got error %v, want ResourceNotFoundException", err)
+	}
+}
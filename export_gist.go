@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// gistClient is the client creating Gists, with the same timeout as other requests to external APIs
+var gistClient = &http.Client{Timeout: 30 * time.Second}
+
+type gistFile struct {
+	Content string `json:"content"`
+}
+
+type gistRequest struct {
+	Description string              `json:"description"`
+	Public      bool                `json:"public"`
+	Files       map[string]gistFile `json:"files"`
+}
+
+type gistResponse struct {
+	HTMLURL string `json:"html_url"`
+}
+
+// exportPolicyToGitHubGist creates a Gist holding the policy, printing its URL to stderr. The Gist is secret unless
+// --gist-private=false, and the account IDs in the policy are redacted with --redact-account-id.
+func exportPolicyToGitHubGist() error {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return fmt.Errorf("GITHUB_TOKEN is not set")
+	}
+
+	reqBody, err := json.Marshal(gistRequest{
+		Description: *gistDescriptionFlag,
+		Public:      !*gistPrivateFlag,
+		Files: map[string]gistFile{
+			"policy.json": {Content: string(redactOutputAccountIDs(getPolicyDocument()))},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", "https://api.github.com/gists", bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := gistClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("creating the Gist returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var gist gistResponse
+	if err := json.Unmarshal(respBody, &gist); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Policy uploaded to %s\n", gist.HTMLURL)
+	return nil
+}
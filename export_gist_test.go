@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestGitHub runs a handler in place of the GitHub API, until the end of the test
+func startTestGitHub(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	previous := gistClient
+	gistClient = &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	t.Cleanup(func() {
+		gistClient = previous
+	})
+}
+
+func TestExportPolicyToGitHubGist(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      map[string]string
+		wantPublic bool
+		wantRedact bool
+	}{
+		{name: "default", wantPublic: false},
+		{name: "public", flags: map[string]string{"gist-private": "false"}, wantPublic: true},
+		{name: "redacted", flags: map[string]string{"redact-account-id": "true"}, wantRedact: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			t.Setenv("GITHUB_TOKEN", "ghp_test")
+			setTestFlag(t, "account-id", "210987654321")
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+			appendTestResourceCalls()
+
+			var gist gistRequest
+			startTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/gists" || r.Header.Get("Authorization") != "token ghp_test" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				json.NewDecoder(r.Body).Decode(&gist)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"html_url": "https://gist.github.com/octocat/1"}`))
+			})
+
+			if err := exportPolicyToGitHubGist(); err != nil {
+				t.Fatal(err)
+			}
+
+			if gist.Public != tt.wantPublic {
+				t.Errorf("got public %t, want %t", gist.Public, tt.wantPublic)
+			}
+			policy := gist.Files["policy.json"].Content
+			if !strings.Contains(policy, "dynamodb:GetItem") {
+				t.Fatalf("the Gist has no policy:\n%s", policy)
+			}
+			if redacted := !strings.Contains(policy, "210987654321"); redacted != tt.wantRedact {
+				t.Errorf("got account IDs redacted %t, want %t:\n%s", redacted, tt.wantRedact, policy)
+			}
+		})
+	}
+}
+
+func TestExportPolicyToGitHubGistErrors(t *testing.T) {
+	resetTestCallLog(t)
+
+	t.Setenv("GITHUB_TOKEN", "")
+	if err := exportPolicyToGitHubGist(); err == nil || !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Errorf("got error %v without a token, want one naming GITHUB_TOKEN", err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	startTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "Validation Failed"}`))
+	})
+	if err := exportPolicyToGitHubGist(); err == nil || !strings.Contains(err.Error(), "422") {
+		t.Errorf("got error %v for a rejected Gist, want one with the status code", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type vaultMountResponse struct {
+	Data struct {
+		Path    string            `json:"path"`
+		Type    string            `json:"type"`
+		Options map[string]string `json:"options"`
+	} `json:"data"`
+}
+
+func vaultRequest(method string, path string, body interface{}) ([]byte, error) {
+	vaultAddr := os.Getenv("VAULT_ADDR")
+	if vaultAddr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(vaultAddr, "/")+"/v1/"+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if *vaultNamespaceFlag != "" {
+		req.Header.Set("X-Vault-Namespace", *vaultNamespaceFlag)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return respBody, nil
+}
+
+// getVaultKVPath returns the API path to write a secret to, which for KV v2 mounts includes data/ after the mount
+func getVaultKVPath(secretPath string) (string, error) {
+	respBody, err := vaultRequest("GET", "sys/internal/ui/mounts/"+secretPath, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var mount vaultMountResponse
+	if err := json.Unmarshal(respBody, &mount); err != nil {
+		return "", err
+	}
+
+	if mount.Data.Options["version"] == "2" && strings.HasPrefix(secretPath, mount.Data.Path) {
+		return mount.Data.Path + "data/" + strings.TrimPrefix(secretPath, mount.Data.Path), nil
+	}
+
+	return secretPath, nil
+}
+
+func exportPolicyToVault() error {
+	secretPath := strings.Trim(*exportToVaultFlag, "/")
+	kvPath, err := getVaultKVPath(secretPath)
+	if err != nil {
+		return err
+	}
+
+	secret := map[string]interface{}{
+		"policy": string(getPolicyDocument()),
+	}
+	if kvPath != secretPath {
+		secret = map[string]interface{}{
+			"data": secret,
+		}
+	}
+
+	_, err = vaultRequest("PUT", kvPath, secret)
+	return err
+}
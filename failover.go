@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var regionFailoverSource string
+var regionFailoverDestination string
+
+func parseRegionFailover() error {
+	if *simulateRegionFailoverFlag == "" {
+		return nil
+	}
+
+	for _, part := range strings.Split(*simulateRegionFailoverFlag, ",") {
+		partSplit := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(partSplit) != 2 {
+			return fmt.Errorf("invalid region failover option %q", part)
+		}
+
+		switch partSplit[0] {
+		case "src":
+			regionFailoverSource = partSplit[1]
+		case "dst":
+			regionFailoverDestination = partSplit[1]
+		default:
+			return fmt.Errorf("invalid region failover option %q", part)
+		}
+	}
+
+	if regionFailoverSource == "" || regionFailoverDestination == "" {
+		return fmt.Errorf("the region failover simulation requires both src and dst regions")
+	}
+
+	return nil
+}
+
+// getRegionFailoverNotes highlights resources that name the source region outside of the ARN region field,
+// such as bucket names, which would need updating for the failover region
+func getRegionFailoverNotes(policy IAMPolicy) []string {
+	var notes []string
+
+	for _, statement := range policy.Statement {
+		for _, resource := range getStatementResources(statement) {
+			arnSplit := strings.SplitN(resource, ":", 6)
+			if len(arnSplit) != 6 {
+				continue
+			}
+
+			if strings.Contains(arnSplit[5], regionFailoverSource) {
+				notes = append(notes, fmt.Sprintf("WARNING: %s refers to %s and may need updating for failover to %s", resource, regionFailoverSource, regionFailoverDestination))
+			}
+		}
+	}
+
+	return uniqueSlice(notes)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var includedStatusCodes map[int]bool
+
+func loadStatusCodeFilter() error {
+	if *includeOnlyStatusCodesFlag == "" {
+		return nil
+	}
+
+	includedStatusCodes = make(map[int]bool)
+	for _, statusCode := range strings.Split(*includeOnlyStatusCodesFlag, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(statusCode))
+		if err != nil {
+			return fmt.Errorf("invalid status code %q", statusCode)
+		}
+		includedStatusCodes[code] = true
+	}
+
+	return nil
+}
+
+var includedRegions map[string]bool
+
+func loadRegionFilter() error {
+	if *regionFilterFlag == "" {
+		return nil
+	}
+
+	includedRegions = make(map[string]bool)
+	for _, region := range strings.Split(*regionFilterFlag, ",") {
+		region = strings.TrimSpace(region)
+		if region == "" {
+			return fmt.Errorf("invalid region filter %q", *regionFilterFlag)
+		}
+		includedRegions[region] = true
+	}
+
+	return nil
+}
+
+// isRegionIncluded returns false if calls in the region are left out of the policy
+func isRegionIncluded(region string) bool {
+	return includedRegions == nil || includedRegions[region]
+}
+
+// readOnlyMethodPrefixes are the prefixes of the API methods that only read resources
+var readOnlyMethodPrefixes = []string{"Describe", "List", "Get"}
+
+var ignoredReadOnlyRegions map[string]bool
+
+func loadIgnoreReadOnlyRegions() error {
+	if *ignoreReadOnlyRegionFlag == "" {
+		return nil
+	}
+
+	ignoredReadOnlyRegions = make(map[string]bool)
+	for _, region := range strings.Split(*ignoreReadOnlyRegionFlag, ",") {
+		region = strings.TrimSpace(region)
+		if region == "" {
+			return fmt.Errorf("invalid read-only region filter %q", *ignoreReadOnlyRegionFlag)
+		}
+		ignoredReadOnlyRegions[region] = true
+	}
+
+	return nil
+}
+
+// isIgnoredReadOnlyCall returns true if the call only reads resources in a region whose read-only calls are not
+// recorded
+func isIgnoredReadOnlyCall(entry Entry) bool {
+	if !ignoredReadOnlyRegions[entry.Region] {
+		return false
+	}
+
+	for _, prefix := range readOnlyMethodPrefixes {
+		if strings.HasPrefix(entry.Method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var includedActionPatterns []string
+var excludedActionPatterns []string
+var includedServices map[string]bool
+var excludedServices map[string]bool
+
+// parseActionPatterns parses a comma-separated list of action glob patterns, which match case-insensitively
+func parseActionPatterns(flagName string, value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %v", flagName, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("invalid %s %q", flagName, value)
+	}
+
+	return patterns, nil
+}
+
+// parseServiceIDs parses a comma-separated list of service IDs, which match regardless of case and spaces
+func parseServiceIDs(flagName string, value string) (map[string]bool, error) {
+	services := make(map[string]bool)
+	for _, service := range strings.Split(value, ",") {
+		if service = normalizeServiceName(strings.TrimSpace(service)); service != "" {
+			services[service] = true
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("invalid %s %q", flagName, value)
+	}
+
+	return services, nil
+}
+
+func loadActionFilters() error {
+	var err error
+	if *includeActionsFlag != "" {
+		if includedActionPatterns, err = parseActionPatterns("--include-actions", *includeActionsFlag); err != nil {
+			return err
+		}
+	}
+	if *excludeActionsFlag != "" {
+		if excludedActionPatterns, err = parseActionPatterns("--exclude-actions", *excludeActionsFlag); err != nil {
+			return err
+		}
+	}
+	if *includeServicesFlag != "" {
+		if includedServices, err = parseServiceIDs("--include-services", *includeServicesFlag); err != nil {
+			return err
+		}
+	}
+	if *excludeServicesFlag != "" {
+		if excludedServices, err = parseServiceIDs("--exclude-services", *excludeServicesFlag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isServiceIncluded returns false if calls to the service are not recorded
+func isServiceIncluded(serviceID string) bool {
+	service := normalizeServiceName(serviceID)
+	if includedServices != nil && !includedServices[service] {
+		return false
+	}
+
+	return !excludedServices[service]
+}
+
+func matchesActionPattern(action string, patterns []string) bool {
+	action = strings.ToLower(action)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, action); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isActionIncluded returns false if none of the IAM actions of the call are wanted, that is each is either not
+// matched by --include-actions or is matched by --exclude-actions
+func isActionIncluded(entry Entry) bool {
+	if includedActionPatterns == nil && excludedActionPatterns == nil {
+		return true
+	}
+
+	for _, action := range getActions(entry.Service, entry.Method) {
+		if includedActionPatterns != nil && !matchesActionPattern(action, includedActionPatterns) {
+			continue
+		}
+		if !matchesActionPattern(action, excludedActionPatterns) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listServices prints the service IDs of the service definitions
+func listServices() {
+	seenServices := make(map[string]bool)
+	var services []string
+	for _, serviceDefinition := range getServiceDefinitions() {
+		serviceID := serviceDefinition.Metadata.ServiceID
+		if serviceID != "" && !seenServices[serviceID] {
+			seenServices[serviceID] = true
+			services = append(services, serviceID)
+		}
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		fmt.Println(service)
+	}
+}
+
+func isErrorEntriesExcluded() bool {
+	return *excludeErrorEntriesFlag || !*includeErrorEntriesFlag
+}
+
+// isStatusCodeRecorded returns false if calls with the status code should not be recorded
+func isStatusCodeRecorded(statusCode int) bool {
+	if isErrorEntriesExcluded() && statusCode >= 400 {
+		return false
+	}
+	if includedStatusCodes != nil && !includedStatusCodes[statusCode] {
+		return false
+	}
+
+	return true
+}
+
+// isFailedCall returns true if AWS rejected the call, so it may not reflect a permission the caller actually has
+func isFailedCall(entry Entry) bool {
+	return entry.FinalHTTPStatusCode >= 400
+}
+
+// validateFailedCallFlags checks that failed calls are recorded when the policy is to include them. Whether a
+// failed call is recorded is decided by --include-error-entries and --exclude-error-entries, whether a recorded
+// failed call is added to the policy by --include-failed-calls and --fails-only.
+func validateFailedCallFlags() error {
+	if !isErrorEntriesExcluded() {
+		return nil
+	}
+
+	if *includeFailedCallsFlag {
+		return fmt.Errorf("--include-failed-calls cannot be used with --exclude-error-entries, as failed calls are not recorded")
+	}
+	if *failsonlyFlag {
+		return fmt.Errorf("--fails-only cannot be used with --exclude-error-entries, as failed calls are not recorded")
+	}
+
+	return nil
+}
+
+// isCallInPolicy returns whether a recorded call is added to the policy. Failed calls are left out of a proxy mode
+// policy unless --include-failed-calls is set, while --fails-only keeps only the calls that did not succeed.
+func isCallInPolicy(entry Entry) bool {
+	if *failsonlyFlag {
+		return entry.FinalHTTPStatusCode < 200 || entry.FinalHTTPStatusCode > 299
+	}
+	if *modeFlag == "proxy" && isFailedCall(entry) {
+		return *includeFailedCallsFlag
+	}
+
+	return true
+}
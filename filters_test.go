@@ -0,0 +1,338 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setTestRegionFilter loads --region-filter as on start, clearing it at the end of the test
+func setTestRegionFilter(t *testing.T, regions string) error {
+	t.Helper()
+
+	setTestFlag(t, "region-filter", regions)
+	t.Cleanup(func() { includedRegions = nil })
+	return loadRegionFilter()
+}
+
+func TestRegionFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		regions     string
+		wantActions []string
+		wantErr     bool
+	}{
+		{name: "no filter", wantActions: []string{"dynamodb:ListTables", "ec2:DescribeInstances", "s3:ListAllMyBuckets"}},
+		{name: "one region", regions: "eu-west-1", wantActions: []string{"ec2:DescribeInstances"}},
+		{name: "several regions", regions: "us-east-1, us-gov-west-1", wantActions: []string{"dynamodb:ListTables", "s3:ListAllMyBuckets"}},
+		{name: "no matching region", regions: "ap-south-1"},
+		{name: "empty region", regions: "us-east-1,,eu-west-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			if err := setTestRegionFilter(t, tt.regions); (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+			recordCall(Entry{Region: "eu-west-1", Type: "ApiCall", Service: "EC2", Method: "DescribeInstances", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+			recordCall(Entry{Region: "us-gov-west-1", Type: "ApiCall", Service: "DynamoDB", Method: "ListTables", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+			if got := callLog.Len(); got != 3 {
+				t.Errorf("got %d recorded calls, want all 3", got)
+			}
+			got := getCapturedActions()
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.wantActions, ",") {
+				t.Errorf("got actions %v, want %v", got, tt.wantActions)
+			}
+		})
+	}
+}
+
+// loadTestActionFilters loads the action and service filter flags as on start, clearing them at the end of the test
+func loadTestActionFilters(t *testing.T, flags map[string]string) error {
+	t.Helper()
+
+	for _, name := range []string{"include-actions", "exclude-actions", "include-services", "exclude-services"} {
+		setTestFlag(t, name, flags[name])
+	}
+	t.Cleanup(func() {
+		includedActionPatterns = nil
+		excludedActionPatterns = nil
+		includedServices = nil
+		excludedServices = nil
+	})
+
+	return loadActionFilters()
+}
+
+func TestActionFilters(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     map[string]string
+		wantCalls []string
+		wantErr   string
+	}{
+		{
+			name:      "no filters",
+			wantCalls: []string{"S3.ListBuckets", "S3.GetObject", "S3.CopyObject", "DynamoDB.ListTables", "EC2.DescribeInstances", "CloudWatchLogs.DescribeLogGroups"},
+		},
+		{
+			name:      "include actions",
+			flags:     map[string]string{"include-actions": "s3:List*,dynamodb:ListTables"},
+			wantCalls: []string{"S3.ListBuckets", "S3.CopyObject", "DynamoDB.ListTables"},
+		},
+		{
+			name:      "include actions in any case",
+			flags:     map[string]string{"include-actions": "EC2:describe*"},
+			wantCalls: []string{"EC2.DescribeInstances"},
+		},
+		{
+			name:      "exclude actions",
+			flags:     map[string]string{"exclude-actions": "s3:Get*,logs:*"},
+			wantCalls: []string{"S3.ListBuckets", "S3.CopyObject", "DynamoDB.ListTables", "EC2.DescribeInstances"},
+		},
+		{
+			name:      "exclude every action of a call",
+			flags:     map[string]string{"exclude-actions": "s3:*"},
+			wantCalls: []string{"DynamoDB.ListTables", "EC2.DescribeInstances", "CloudWatchLogs.DescribeLogGroups"},
+		},
+		{
+			name:      "include and exclude actions",
+			flags:     map[string]string{"include-actions": "s3:*", "exclude-actions": "s3:ListAllMyBuckets,s3:GetObject"},
+			wantCalls: []string{"S3.CopyObject"},
+		},
+		{
+			name:      "include services",
+			flags:     map[string]string{"include-services": "s3, DynamoDB"},
+			wantCalls: []string{"S3.ListBuckets", "S3.GetObject", "S3.CopyObject", "DynamoDB.ListTables"},
+		},
+		{
+			name:      "include services with spaces",
+			flags:     map[string]string{"include-services": "CloudWatch Logs"},
+			wantCalls: []string{"CloudWatchLogs.DescribeLogGroups"},
+		},
+		{
+			name:      "exclude services",
+			flags:     map[string]string{"exclude-services": "S3,EC2"},
+			wantCalls: []string{"DynamoDB.ListTables", "CloudWatchLogs.DescribeLogGroups"},
+		},
+		{
+			name:      "include and exclude services",
+			flags:     map[string]string{"include-services": "S3,EC2", "exclude-services": "ec2"},
+			wantCalls: []string{"S3.ListBuckets", "S3.GetObject", "S3.CopyObject"},
+		},
+		{
+			name:      "include services and actions",
+			flags:     map[string]string{"include-services": "S3", "include-actions": "*:Describe*,s3:GetObject"},
+			wantCalls: []string{"S3.GetObject", "S3.CopyObject"},
+		},
+		{
+			name:      "exclude services and actions",
+			flags:     map[string]string{"exclude-services": "S3", "exclude-actions": "ec2:*"},
+			wantCalls: []string{"DynamoDB.ListTables", "CloudWatchLogs.DescribeLogGroups"},
+		},
+		{
+			name:      "every filter",
+			flags:     map[string]string{"include-services": "S3,DynamoDB,EC2", "exclude-services": "DynamoDB", "include-actions": "s3:*,ec2:*", "exclude-actions": "s3:PutObject*,s3:ListBucket"},
+			wantCalls: []string{"S3.ListBuckets", "S3.GetObject", "S3.CopyObject", "EC2.DescribeInstances"},
+		},
+		{
+			name:    "invalid pattern",
+			flags:   map[string]string{"include-actions": "s3:[List"},
+			wantErr: "invalid --include-actions pattern",
+		},
+		{
+			name:    "empty services",
+			flags:   map[string]string{"exclude-services": " , "},
+			wantErr: "invalid --exclude-services",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			err := loadTestActionFilters(t, tt.flags)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, call := range []string{"S3.ListBuckets", "S3.GetObject", "S3.CopyObject", "DynamoDB.ListTables", "EC2.DescribeInstances", "CloudWatchLogs.DescribeLogGroups"} {
+				callSplit := strings.SplitN(call, ".", 2)
+				recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: callSplit[0], Method: callSplit[1], FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+			}
+
+			var got []string
+			for _, entry := range callLog.Snapshot() {
+				got = append(got, entry.Service+"."+entry.Method)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("got calls %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestProxyExcludedService(t *testing.T) {
+	resetTestCallLog(t)
+	if err := loadTestActionFilters(t, map[string]string{"exclude-services": "STS"}); err != nil {
+		t.Fatal(err)
+	}
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if got := sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", queryFormHeader, "Action=GetCallerIdentity&Version=2011-06-15"); got != http.StatusOK {
+		t.Errorf("the client got status %d, want the call passed through", got)
+	}
+	sendTestRequest(t, client, "POST", "http://ec2.us-east-1.amazonaws.com/", queryFormHeader, "Action=DescribeInstances&Version=2016-11-15")
+
+	entries := callLog.Snapshot()
+	if len(entries) != 1 || entries[0].Service != "EC2" {
+		t.Errorf("got calls %+v, want only EC2", entries)
+	}
+}
+
+func TestListServices(t *testing.T) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	listServices()
+	w.Close()
+	os.Stdout = stdout
+
+	output, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if !sort.StringsAreSorted(services) {
+		t.Errorf("the services are not sorted")
+	}
+	seen := make(map[string]bool)
+	for _, service := range services {
+		if seen[service] {
+			t.Errorf("%s is listed more than once", service)
+		}
+		seen[service] = true
+	}
+	for _, service := range []string{"S3", "DynamoDB", "EC2", "CloudWatch Logs", "STS"} {
+		if !seen[service] {
+			t.Errorf("%s is not listed", service)
+		}
+	}
+}
+
+func TestIsCallInPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		flags      map[string]string
+		statusCode int
+		want       bool
+	}{
+		{name: "successful call", mode: "proxy", statusCode: 200, want: true},
+		{name: "failed call", mode: "proxy", statusCode: 403, want: false},
+		{name: "server error", mode: "proxy", statusCode: 500, want: false},
+		{name: "failed call with --include-failed-calls", mode: "proxy", flags: map[string]string{"include-failed-calls": "true"}, statusCode: 403, want: true},
+		{name: "successful call with --include-failed-calls", mode: "proxy", flags: map[string]string{"include-failed-calls": "true"}, statusCode: 200, want: true},
+		{name: "successful call with --fails-only", mode: "proxy", flags: map[string]string{"fails-only": "true"}, statusCode: 200, want: false},
+		{name: "failed call with --fails-only", mode: "proxy", flags: map[string]string{"fails-only": "true"}, statusCode: 403, want: true},
+		{name: "failed call in csm mode", mode: "csm", statusCode: 403, want: true},
+		{name: "successful call in csm mode with --fails-only", mode: "csm", flags: map[string]string{"fails-only": "true"}, statusCode: 200, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestFlag(t, "mode", tt.mode)
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+
+			if got := isCallInPolicy(Entry{FinalHTTPStatusCode: tt.statusCode}); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFailedCallFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		wantErr string
+	}{
+		{name: "defaults"},
+		{name: "include failed calls", flags: map[string]string{"include-failed-calls": "true"}},
+		{name: "exclude error entries", flags: map[string]string{"exclude-error-entries": "true"}},
+		{name: "include failed calls that are not recorded", flags: map[string]string{"include-failed-calls": "true", "exclude-error-entries": "true"}, wantErr: "--include-failed-calls cannot be used"},
+		{name: "include failed calls that are not included", flags: map[string]string{"include-failed-calls": "true", "include-error-entries": "false"}, wantErr: "--include-failed-calls cannot be used"},
+		{name: "fails only without failed calls", flags: map[string]string{"fails-only": "true", "exclude-error-entries": "true"}, wantErr: "--fails-only cannot be used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+
+			err := validateFailedCallFlags()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFailedCallsInPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		wantActions []string
+		wantCalls   int
+	}{
+		{name: "failed calls are recorded but left out of the policy", wantActions: []string{"s3:ListAllMyBuckets"}, wantCalls: 2},
+		{name: "failed calls are added with --include-failed-calls", flags: map[string]string{"include-failed-calls": "true"}, wantActions: []string{"s3:ListAllMyBuckets", "sts:GetCallerIdentity"}, wantCalls: 2},
+		{name: "failed calls are not recorded with --exclude-error-entries", flags: map[string]string{"exclude-error-entries": "true"}, wantActions: []string{"s3:ListAllMyBuckets"}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+
+			recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+			recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "STS", Method: "GetCallerIdentity", FinalHTTPStatusCode: 403, Timestamp: time.Now()})
+
+			if got := callLog.Len(); got != tt.wantCalls {
+				t.Errorf("got %d recorded calls, want %d", got, tt.wantCalls)
+			}
+			got := getCapturedActions()
+			if strings.Join(got, ",") != strings.Join(tt.wantActions, ",") {
+				t.Errorf("got actions %v, want %v", got, tt.wantActions)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// grpcTranscodedPathRegexp matches HTTP/1.1 paths transcoded from gRPC, e.g. /package.v1.Service/Method
+var grpcTranscodedPathRegexp = regexp.MustCompile(`^/([a-zA-Z_][a-zA-Z0-9_]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)+)/([A-Z][a-zA-Z0-9_]*)$`)
+
+// parseGRPCTranscodedPath returns the package (including the gRPC service) and method of a transcoded path
+func parseGRPCTranscodedPath(path string) (string, string) {
+	matches := grpcTranscodedPathRegexp.FindStringSubmatch(path)
+	if len(matches) != 3 {
+		return "", ""
+	}
+
+	return matches[1], matches[2]
+}
+
+// getGRPCPackageServiceDefinition maps a gRPC package to the service definition named by one of its segments
+func getGRPCPackageServiceDefinition(pkg string) (ServiceDefinition, bool) {
+	for _, segment := range strings.Split(strings.ToLower(pkg), ".") {
+		for _, serviceDefinition := range getServiceDefinitions() {
+			if serviceDefinition.Metadata.EndpointPrefix == segment {
+				return serviceDefinition, true
+			}
+		}
+	}
+
+	return ServiceDefinition{}, false
+}
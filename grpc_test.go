@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseGRPCTranscodedPath(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantPackage string
+		wantMethod  string
+	}{
+		{path: "/google.cloud.iam.v1.IAMPolicy/GetIamPolicy", wantPackage: "google.cloud.iam.v1.IAMPolicy", wantMethod: "GetIamPolicy"},
+		{path: "/aws.lambda.v1.Lambda/ListFunctions", wantPackage: "aws.lambda.v1.Lambda", wantMethod: "ListFunctions"},
+		{path: "/Lambda/ListFunctions"},
+		{path: "/aws.lambda.v1.Lambda/listFunctions"},
+		{path: "/aws.lambda.v1.Lambda/ListFunctions/extra"},
+		{path: "/2015-03-31/functions"},
+		{path: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pkg, method := parseGRPCTranscodedPath(tt.path)
+			if pkg != tt.wantPackage || method != tt.wantMethod {
+				t.Errorf("got %q %q, want %q %q", pkg, method, tt.wantPackage, tt.wantMethod)
+			}
+		})
+	}
+}
+
+func TestProxyGRPCTranscodedRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		body        string
+		wantService string
+		wantMethod  string
+		wantParams  map[string][]string
+	}{
+		{
+			name:        "package names the service",
+			url:         "http://grpc.us-east-1.amazonaws.com/google.cloud.iam.v1.IAMPolicy/GetIamPolicy",
+			body:        `{"resource": "projects/orders"}`,
+			wantService: "IAM",
+			wantMethod:  "GetIamPolicy",
+			wantParams:  map[string][]string{"resource": {"projects/orders"}},
+		},
+		{
+			name:        "host names the service",
+			url:         "http://lambda.us-east-1.amazonaws.com/aws.functions.v1.Functions/ListFunctions",
+			body:        `{"MaxItems": 10, "Filters": [{"Name": "runtime"}]}`,
+			wantService: "Lambda",
+			wantMethod:  "ListFunctions",
+			wantParams:  map[string][]string{"MaxItems": {"10"}, "Filters[].Name": {"runtime"}},
+		},
+		{
+			name:        "no body",
+			url:         "http://grpc.us-east-1.amazonaws.com/aws.sqs.v1.Queues/ListQueues",
+			wantService: "SQS",
+			wantMethod:  "ListQueues",
+			wantParams:  map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			var gotPath string
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte("{}"))
+			}))
+
+			sendTestRequest(t, client, "POST", tt.url, http.Header{"Content-Type": {"application/json"}}, tt.body)
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != tt.wantService || entry.Method != tt.wantMethod {
+				t.Errorf("got call %s.%s, want %s.%s", entry.Service, entry.Method, tt.wantService, tt.wantMethod)
+			}
+			if !reflect.DeepEqual(entry.Parameters, tt.wantParams) {
+				t.Errorf("got params %v, want %v", entry.Parameters, tt.wantParams)
+			}
+			if gotPath == "" {
+				t.Error("the request was not forwarded upstream")
+			}
+		})
+	}
+}
+
+func TestProxyGRPCTranscodedUnknownPackage(t *testing.T) {
+	resetTestCallLog(t)
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	sendTestRequest(t, client, "POST", "http://grpc.us-east-1.amazonaws.com/example.widgets.v1.Widgets/ListWidgets", http.Header{"Content-Type": {"application/json"}}, "{}")
+
+	if got := callLog.Len(); got != 0 {
+		t.Errorf("got %d calls for an unknown package, want none", got)
+	}
+}
+
+func TestProxyS3PathWithDots(t *testing.T) {
+	resetTestCallLog(t)
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// a path-style request to a bucket whose name looks like a gRPC package
+	sendTestRequest(t, client, "GET", "http://s3.us-east-1.amazonaws.com/my.example.bucket/Report", nil, "")
+
+	entry := getSingleTestEntry(t)
+	if entry.Service != "S3" || entry.Method != "GetObject" {
+		t.Errorf("got call %s.%s, want S3.GetObject", entry.Service, entry.Method)
+	}
+}
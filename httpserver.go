@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// callEventBufferSize is the number of calls buffered for each subscriber, such as an /events client, beyond which
+// calls are dropped for that subscriber rather than holding up the capture
+const callEventBufferSize = 256
+
+// CallEventBroker fans captured calls out to the connected /events clients and the terminal UI
+type CallEventBroker struct {
+	mutex   sync.Mutex
+	clients map[chan Entry]bool
+}
+
+var callEventBroker = &CallEventBroker{
+	clients: make(map[chan Entry]bool),
+}
+
+// Subscribe returns a channel receiving each call captured from now on
+func (b *CallEventBroker) Subscribe() chan Entry {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	client := make(chan Entry, callEventBufferSize)
+	b.clients[client] = true
+	return client
+}
+
+// Unsubscribe stops sending calls to a channel returned by Subscribe
+func (b *CallEventBroker) Unsubscribe(client chan Entry) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.clients, client)
+}
+
+// Publish sends a call to every subscribed channel without blocking
+func (b *CallEventBroker) Publish(entry Entry) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for client := range b.clients {
+		select {
+		case client <- entry:
+		default:
+			log.Printf("WARNING: a subscriber to the captured calls is not keeping up, a call was not sent to it")
+		}
+	}
+}
+
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := callEventBroker.Subscribe()
+	defer callEventBroker.Unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry := <-client:
+			data, err := json.Marshal(newPersistedCall(entry)) // includes the details left out of the CSM encoding
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: call\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func handlePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(getPolicyDocument())
+}
+
+func handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	callLog.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleConfigExample serves the example YAML config file embedded in the binary
+func handleConfigExample(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(configExample)
+}
+
+// newHTTPHandler returns the handler of the /events, /policy, /reset and /config.example.yaml endpoints
+func newHTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", handleEvents)
+	mux.HandleFunc("/policy", handlePolicy)
+	mux.HandleFunc("/reset", handleReset)
+	mux.HandleFunc("/config.example.yaml", handleConfigExample)
+
+	return mux
+}
+
+// startHTTPServer serves the endpoints of newHTTPHandler on --http-host and --http-port
+func startHTTPServer() error {
+	if *httpPortFlag == 0 {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(*httpHostFlag, strconv.Itoa(*httpPortFlag)))
+	if err != nil {
+		return fmt.Errorf("could not start the --http-port server: %v", err)
+	}
+
+	go func() {
+		if err := http.Serve(listener, newHTTPHandler()); err != nil {
+			log.Printf("WARNING: the --http-port server stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
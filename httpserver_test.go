@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openTestEventStream connects to /events, returning a reader of the stream once the client is subscribed
+func openTestEventStream(t *testing.T, serverURL string) *bufio.Reader {
+	t.Helper()
+
+	resp, err := http.Get(serverURL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		resp.Body.Close()
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got Content-Type %s, want text/event-stream", got)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("got Cache-Control %s, want no-cache", got)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// readTestEvent reads the next event from an /events stream, failing the test if none arrives within a timeout
+func readTestEvent(t *testing.T, stream *bufio.Reader, timeout time.Duration) persistedCall {
+	t.Helper()
+
+	type result struct {
+		lines []string
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		var lines []string
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			if line == "\n" {
+				results <- result{lines: lines}
+				return
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}()
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		if len(res.lines) != 2 || res.lines[0] != "event: call" || !strings.HasPrefix(res.lines[1], "data: ") {
+			t.Fatalf("got event %q, want a call", res.lines)
+		}
+		var call persistedCall
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(res.lines[1], "data: ")), &call); err != nil {
+			t.Fatal(err)
+		}
+		return call
+	case <-time.After(timeout):
+		t.Fatalf("no event arrived within %s", timeout)
+	}
+	return persistedCall{}
+}
+
+func TestEventsEndpoint(t *testing.T) {
+	resetTestCallLog(t)
+	server := httptest.NewServer(newHTTPHandler())
+	t.Cleanup(server.Close)
+
+	streams := []*bufio.Reader{openTestEventStream(t, server.URL), openTestEventStream(t, server.URL)}
+
+	for i, method := range []string{"ListBuckets", "GetBucketLocation"} {
+		recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: method, FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+		// every connected client gets each call
+		for j, stream := range streams {
+			call := readTestEvent(t, stream, 100*time.Millisecond)
+			if call.Service != "S3" || call.Method != method {
+				t.Errorf("call %d: client %d got %s.%s, want S3.%s", i, j, call.Service, call.Method, method)
+			}
+		}
+	}
+}
+
+func TestEventsEndpointDisconnect(t *testing.T) {
+	resetTestCallLog(t)
+	server := httptest.NewServer(newHTTPHandler())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// the handler unsubscribes once it notices the client has gone
+	deadline := time.Now().Add(time.Second)
+	for {
+		callEventBroker.mutex.Lock()
+		clients := len(callEventBroker.clients)
+		callEventBroker.mutex.Unlock()
+		if clients == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d subscribers after the client disconnected, want 0", clients)
+		}
+		recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCallEventBrokerSlowSubscriber(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+	broker := &CallEventBroker{clients: make(map[chan Entry]bool)}
+	slow := broker.Subscribe()
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < callEventBufferSize+1; i++ {
+			broker.Publish(Entry{Service: "S3", Method: fmt.Sprintf("Call%d", i)})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing to a subscriber that is not reading blocked")
+	}
+	if len(slow) != callEventBufferSize {
+		t.Errorf("got %d buffered calls, want %d", len(slow), callEventBufferSize)
+	}
+	if !strings.Contains(logs.String(), "is not keeping up") {
+		t.Errorf("no warning was logged for the dropped call")
+	}
+
+	broker.Unsubscribe(slow)
+	broker.Publish(Entry{Service: "S3", Method: "ListBuckets"})
+	if len(slow) != callEventBufferSize {
+		t.Errorf("a call was sent after unsubscribing")
+	}
+}
+
+func TestPolicyAndResetEndpoints(t *testing.T) {
+	resetTestCallLog(t)
+	server := httptest.NewServer(newHTTPHandler())
+	t.Cleanup(server.Close)
+	recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	getPolicy := func() string {
+		resp, err := http.Get(server.URL + "/policy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("got Content-Type %s, want application/json", got)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	if policy := getPolicy(); !strings.Contains(policy, `"s3:ListAllMyBuckets"`) {
+		t.Errorf("the policy has no s3:ListAllMyBuckets:\n%s", policy)
+	}
+
+	resp, err := http.Post(server.URL+"/reset", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("got status %d from /reset, want 204", resp.StatusCode)
+	}
+	if got := callLog.Len(); got != 0 {
+		t.Errorf("got %d calls after /reset, want 0", got)
+	}
+	if policy := getPolicy(); strings.Contains(policy, `"s3:ListAllMyBuckets"`) {
+		t.Errorf("the policy still has s3:ListAllMyBuckets after /reset:\n%s", policy)
+	}
+}
+
+func TestConfigExampleEndpoint(t *testing.T) {
+	server := httptest.NewServer(newHTTPHandler())
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/yaml" {
+		t.Errorf("got Content-Type %s, want application/yaml", got)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := ioutil.ReadFile("config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != string(want) {
+		t.Errorf("got a config that is not config.example.yaml:\n%s", body)
+	}
+
+	// the example only sets flags that exist
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(body, &config); err != nil {
+		t.Fatal(err)
+	}
+	for key := range config {
+		if flag.Lookup(key) == nil {
+			t.Errorf("the example config sets the unknown flag --%s", key)
+		}
+	}
+}
+
+func TestHTTPEndpointMethods(t *testing.T) {
+	server := httptest.NewServer(newHTTPHandler())
+	t.Cleanup(server.Close)
+
+	for _, tt := range []struct{ method, path string }{
+		{method: "POST", path: "/events"},
+		{method: "DELETE", path: "/policy"},
+		{method: "GET", path: "/reset"},
+		{method: "POST", path: "/config.example.yaml"},
+	} {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s got status %d, want 405", tt.method, tt.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestStartHTTPServer(t *testing.T) {
+	if got := flag.Lookup("http-host").DefValue; got != "127.0.0.1" {
+		t.Errorf("got --http-host default %s, want 127.0.0.1", got)
+	}
+
+	// a port that is taken fails on start
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	setTestFlag(t, "http-host", "127.0.0.1")
+	setTestFlag(t, "http-port", fmt.Sprint(listener.Addr().(*net.TCPAddr).Port))
+
+	if err := startHTTPServer(); err == nil || !strings.Contains(err.Error(), "could not start the --http-port server") {
+		t.Errorf("got error %v, want the port to be taken", err)
+	}
+}
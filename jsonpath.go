@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/mitchellh/go-homedir"
+)
+
+// JSONPathMappingRule maps requests for a custom service to an action using a path into the JSON body
+type JSONPathMappingRule struct {
+	HostPattern string `json:"host_pattern"`
+	JSONPath    string `json:"json_path"`
+	Service     string `json:"service"`
+
+	hostRegexp *regexp.Regexp
+	path       gval.Evaluable
+}
+
+var jsonPathMappingRules []JSONPathMappingRule
+
+func loadJSONPathMappings() error {
+	if *jsonPathMappingFlag == "" {
+		return nil
+	}
+
+	mappingPath, err := homedir.Expand(*jsonPathMappingFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(mappingPath)
+	if err != nil {
+		return err
+	}
+
+	var rules []JSONPathMappingRule
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return fmt.Errorf("invalid JSON path mapping file: %v", err)
+	}
+
+	for i := range rules {
+		rules[i].hostRegexp, err = regexp.Compile(rules[i].HostPattern)
+		if err != nil {
+			return fmt.Errorf("invalid host pattern %q: %v", rules[i].HostPattern, err)
+		}
+		if !strings.HasPrefix(rules[i].JSONPath, "$") {
+			return fmt.Errorf("JSON path %q must start with $", rules[i].JSONPath)
+		}
+		rules[i].path, err = jsonpath.New(rules[i].JSONPath)
+		if err != nil {
+			return fmt.Errorf("invalid JSON path %q: %v", rules[i].JSONPath, err)
+		}
+	}
+
+	jsonPathMappingRules = rules
+	return nil
+}
+
+// getJSONPathMappingRule returns the first rule matching a host. Rules map custom services only, so that a broad
+// host pattern cannot rename or remap the calls to AWS endpoints.
+func getJSONPathMappingRule(host string) *JSONPathMappingRule {
+	if isAWSHost(host) {
+		return nil
+	}
+
+	for i, rule := range jsonPathMappingRules {
+		if rule.hostRegexp.MatchString(host) {
+			return &jsonPathMappingRules[i]
+		}
+	}
+
+	return nil
+}
+
+func isJSONPathMappedService(service string) bool {
+	for _, rule := range jsonPathMappingRules {
+		if rule.Service != "" && rule.Service == service {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rule *JSONPathMappingRule) resolveAction(bodyJSON interface{}) (string, error) {
+	val, err := rule.path(context.Background(), bodyJSON)
+	if err != nil {
+		return "", fmt.Errorf("JSON path %s not found: %v", rule.JSONPath, err)
+	}
+
+	switch val.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return "", fmt.Errorf("JSON path %s does not resolve to a value", rule.JSONPath)
+	}
+
+	return fmt.Sprintf("%v", val), nil
+}
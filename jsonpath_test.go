@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestJSONPathMappings loads a rules file, until the end of the test
+func loadTestJSONPathMappings(t *testing.T, rules string) error {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "rules.json")
+	if err := ioutil.WriteFile(file, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setTestFlag(t, "json-path-mapping", file)
+	t.Cleanup(func() {
+		jsonPathMappingRules = nil
+	})
+
+	return loadJSONPathMappings()
+}
+
+func TestLoadJSONPathMappingsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "not JSON", rules: `{`, wantErr: "invalid JSON path mapping file"},
+		{name: "host pattern", rules: `[{"host_pattern": "(", "json_path": "$.Action"}]`, wantErr: "invalid host pattern"},
+		{name: "relative path", rules: `[{"host_pattern": "example", "json_path": "Action"}]`, wantErr: "must start with $"},
+		{name: "unterminated bracket", rules: `[{"host_pattern": "example", "json_path": "$.Actions[0"}]`, wantErr: "invalid JSON path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loadTestJSONPathMappings(t, tt.rules)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveAction(t *testing.T) {
+	body := `{"Action": "CreateWidget", "Request": {"Actions": ["DeleteWidget", "ListWidgets"], "Version": 2}, "Widgets": [{"Name": "a"}, {"Name": "b"}]}`
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "$.Action", want: "CreateWidget"},
+		{path: `$["Action"]`, want: "CreateWidget"},
+		{path: "$.Request.Actions[0]", want: "DeleteWidget"},
+		{path: "$.Request.Actions[1]", want: "ListWidgets"},
+		{path: `$.Request["Actions"][0]`, want: "DeleteWidget"},
+		{path: "$.Request.Version", want: "2"},
+		{path: "$.Missing", wantErr: true},
+		{path: "$.Request.Actions[5]", wantErr: true},
+		{path: "$.Request", wantErr: true},
+		{path: "$.Widgets[*].Name", wantErr: true},
+		{path: "$..Name", wantErr: true},
+	}
+
+	var bodyJSON interface{}
+	if err := json.Unmarshal([]byte(body), &bodyJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rulesDoc, _ := json.Marshal([]map[string]string{{"host_pattern": "example", "json_path": tt.path}})
+			if err := loadTestJSONPathMappings(t, string(rulesDoc)); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := jsonPathMappingRules[0].resolveAction(bodyJSON)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got action %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got action %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyJSONPathMapping(t *testing.T) {
+	err := loadTestJSONPathMappings(t, `[{"host_pattern": ".*", "json_path": "$.Request.Action", "service": "WidgetService"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		header      http.Header
+		body        string
+		wantService string
+		wantMethod  string
+	}{
+		{
+			name:        "custom service",
+			url:         "http://widgets.example.internal/",
+			header:      http.Header{"Content-Type": {"application/x-amz-json-1.1"}},
+			body:        `{"Request": {"Action": "CreateWidget"}}`,
+			wantService: "WidgetService",
+			wantMethod:  "CreateWidget",
+		},
+		{
+			name:        "aws host matching the pattern",
+			url:         "http://dynamodb.us-east-1.amazonaws.com/",
+			header:      http.Header{"Content-Type": {"application/x-amz-json-1.0"}, "X-Amz-Target": {"DynamoDB_20120810.ListTables"}},
+			body:        `{"Request": {"Action": "CreateWidget"}}`,
+			wantService: "DynamoDB",
+			wantMethod:  "ListTables",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			sendTestRequest(t, client, "POST", tt.url, tt.header, tt.body)
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != tt.wantService || entry.Method != tt.wantMethod {
+				t.Errorf("got %s.%s, want %s.%s", entry.Service, entry.Method, tt.wantService, tt.wantMethod)
+			}
+		})
+	}
+}
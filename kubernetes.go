@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// podLabelsPath is where the Kubernetes downward API volume is expected to expose the pod labels
+const podLabelsPath = "/etc/podinfo/labels"
+
+var podLabels map[string]string
+var podLabelFilterMatched = true
+
+func loadPodLabels() error {
+	if *kubernetesLabelFilterFlag == "" {
+		return nil
+	}
+
+	filterSplit := strings.SplitN(*kubernetesLabelFilterFlag, "=", 2)
+	if len(filterSplit) != 2 || filterSplit[0] == "" {
+		return fmt.Errorf("the Kubernetes label filter must be in the form key=value")
+	}
+
+	file, err := os.Open(podLabelsPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	podLabels = make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSplit := strings.SplitN(scanner.Text(), "=", 2)
+		if len(lineSplit) != 2 {
+			continue
+		}
+
+		value, err := strconv.Unquote(lineSplit[1])
+		if err != nil {
+			value = lineSplit[1]
+		}
+		podLabels[lineSplit[0]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	podLabelFilterMatched = podLabels[filterSplit[0]] == filterSplit[1]
+	if !podLabelFilterMatched {
+		log.Printf("WARNING: pod does not carry the label %s, calls will not be recorded", *kubernetesLabelFilterFlag)
+	}
+
+	return nil
+}
+
+// applyPodLabels adds the pod labels to the entry as resource tag condition keys, returning false if
+// the entry should not be recorded
+func applyPodLabels(entry *Entry) bool {
+	if !podLabelFilterMatched {
+		return false
+	}
+
+	if len(podLabels) > 0 {
+		if entry.ConditionKeys == nil {
+			entry.ConditionKeys = make(map[string]string)
+		}
+		for k, v := range podLabels {
+			entry.ConditionKeys["aws:ResourceTag/"+k] = v
+		}
+	}
+
+	return true
+}
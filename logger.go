@@ -21,7 +21,7 @@ var bIAMMap []byte
 //go:embed iam_definition.json
 var bIAMSAR []byte
 
-var callLog []Entry
+var callLog CallLog
 
 // JSON maps
 var iamMap iamMapBase
@@ -35,16 +35,35 @@ type Entry struct {
 	Method              string `json:"Api"`
 	Parameters          map[string][]string
 	URIParameters       map[string]string
-	FinalHTTPStatusCode int `json:"FinalHttpStatusCode"`
+	FinalHTTPStatusCode int       `json:"FinalHttpStatusCode"`
+	Timestamp           time.Time `json:"-"`
+	ConditionKeys       map[string]string
+	CorrelationID       string            `json:"-"`
+	EventSource         string            `json:"-"`
+	ResponseBodyBytes   int64             `json:"-"`
+	TimeBucket          string            `json:"-"`
+	SourceAnnotation    string            `json:"-"`
+	SessionID           string            `json:"-"`
+	Headers             map[string]string `json:"-"`
+	ResourceARNs        []string          `json:"-"`
+	Duration            time.Duration     `json:"-"`
+	AssumedRoleARN      string            `json:"-"`
+	CallerAccessKeyID   string            `json:"-"`
+	CallerPrincipalARN  string            `json:"-"`
+	SigningRegion       string            `json:"-"`
 }
 
 // Statement is a single statement within an IAM policy
 type Statement struct {
+	Sid      string      `json:"Sid,omitempty"`
 	Effect   string      `json:"Effect"`
 	Action   []string    `json:"Action"`
 	Resource interface{} `json:"Resource"`
 }
 
+// PolicyStatement is a statement of a generated policy, as taken by the output formatters
+type PolicyStatement = Statement
+
 // IAMPolicy is a full IAM policy
 type IAMPolicy struct {
 	Version   string      `json:"Version"`
@@ -64,6 +83,19 @@ func loadMaps() {
 }
 
 func getPolicyDocument() []byte {
+	doc, err := json.MarshalIndent(getPolicy(), "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func getPolicy() IAMPolicy {
+	return getPolicyForEntries(getPolicyEntries())
+}
+
+// getPolicyForEntries returns the policy of a set of captured calls
+func getPolicyForEntries(entries []Entry) IAMPolicy {
 	policy := IAMPolicy{
 		Version:   "2012-10-17",
 		Statement: []Statement{},
@@ -72,8 +104,8 @@ func getPolicyDocument() []byte {
 	if *modeFlag == "csm" {
 		var actions []string
 
-		for _, entry := range callLog {
-			if *failsonlyFlag && (entry.FinalHTTPStatusCode >= 200 && entry.FinalHTTPStatusCode <= 299) {
+		for _, entry := range entries {
+			if !isCallInPolicy(entry) {
 				continue
 			}
 
@@ -103,15 +135,16 @@ func getPolicyDocument() []byte {
 			Action:   actions,
 		})
 	} else if *modeFlag == "proxy" {
-		for _, entry := range callLog {
-			if *failsonlyFlag && (entry.FinalHTTPStatusCode >= 200 && entry.FinalHTTPStatusCode <= 299) {
+		for _, entry := range entries {
+			if !isCallInPolicy(entry) {
 				continue
 			}
 
-			policy.Statement = append(policy.Statement, getStatementsForProxyCall(entry)...)
+			policy.Statement = append(policy.Statement, getStatementsForProxyCall(entry, getPolicyAccountID())...)
 		}
 
 		policy = aggregatePolicy(policy)
+		policy.Statement = consolidateStatements(policy.Statement)
 
 		for i := 0; i < len(policy.Statement); i++ { // make any single wildcard resource a non-array
 			resource := policy.Statement[i].Resource.([]string)
@@ -121,11 +154,132 @@ func getPolicyDocument() []byte {
 		}
 	}
 
-	doc, err := json.MarshalIndent(policy, "", "    ")
-	if err != nil {
-		panic(err)
+	if *iamRoleNameFlag != "" {
+		setStatementSids(&policy, *iamRoleNameFlag)
+	}
+
+	if *redactAccountIDFlag {
+		redactAccountIDs(&policy)
+	}
+
+	return policy
+}
+
+var invalidSidRegexp = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// setStatementSids numbers each statement with a Sid made from the prefix, which may only be alphanumeric
+func setStatementSids(policy *IAMPolicy, prefix string) {
+	prefix = invalidSidRegexp.ReplaceAllString(prefix, "")
+	for i := range policy.Statement {
+		policy.Statement[i].Sid = fmt.Sprintf("%s%d", prefix, i+1)
+	}
+}
+
+var accountIDRegexp = regexp.MustCompile(`\b[0-9]{12}\b`)
+
+// outputARNAccountIDRegexp matches the account ID of each ARN within an output, which unlike policy resources may
+// hold other numbers such as timestamps
+var outputARNAccountIDRegexp = regexp.MustCompile(`\barn:[a-z-]*:[a-zA-Z0-9-]*:[a-z0-9-]*:[0-9]{12}\b`)
+
+// redactAccountIDs replaces any account IDs within the policy resources
+func redactAccountIDs(policy *IAMPolicy) {
+	for i, statement := range policy.Statement {
+		switch resource := statement.Resource.(type) {
+		case string:
+			policy.Statement[i].Resource = accountIDRegexp.ReplaceAllString(resource, "XXXXXXXXXXXX")
+		case []string:
+			redactedResources := []string{}
+			for _, arn := range resource {
+				redactedResources = append(redactedResources, accountIDRegexp.ReplaceAllString(arn, "XXXXXXXXXXXX"))
+			}
+			policy.Statement[i].Resource = redactedResources
+		}
+	}
+}
+
+// redactOutputAccountIDs replaces the account IDs of all ARNs within an output, such as those of trust policy
+// principals that are not policy resources
+func redactOutputAccountIDs(output []byte) []byte {
+	if !*redactAccountIDFlag {
+		return output
+	}
+
+	return outputARNAccountIDRegexp.ReplaceAllFunc(output, func(arn []byte) []byte {
+		return append(append([]byte{}, arn[:len(arn)-12]...), "XXXXXXXXXXXX"...)
+	})
+}
+
+// getPolicyEntries returns a snapshot of the calls that contribute to the policy
+func getPolicyEntries() []Entry {
+	entries := callLog.Snapshot()
+
+	if regionFailoverSource != "" {
+		for _, entry := range entries {
+			if entry.Region == regionFailoverSource {
+				entry.Region = regionFailoverDestination
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if includedRegions != nil {
+		var includedEntries []Entry
+		for _, entry := range entries {
+			if isRegionIncluded(entry.Region) {
+				includedEntries = append(includedEntries, entry)
+			}
+		}
+		entries = includedEntries
+	}
+
+	return entries
+}
+
+// getPolicyNotes returns any warnings about the current policy to be shown alongside it
+func getPolicyNotes() []string {
+	var notes []string
+
+	if regionFailoverSource != "" {
+		notes = append(notes, getRegionFailoverNotes(getPolicy())...)
+	}
+
+	if isOutputFormatSelected("gcp-iam") {
+		notes = append(notes, getGCPIAMNotes()...)
+	}
+
+	if isOutputFormatSelected("azure-rbac") {
+		notes = append(notes, getAzureRBACNotes()...)
+	}
+
+	if isOutputFormatSelected("scp") {
+		notes = append(notes, getSCPNotes()...)
+	}
+
+	if *detectPrivilegeEscalationFlag {
+		notes = append(notes, getPrivilegeEscalationNotes()...)
+	}
+
+	if *tagEntriesByTimeBucketFlag > 0 {
+		notes = append(notes, getTimeBucketTimeline()...)
+	}
+
+	if *suggestWildcardsFlag {
+		notes = append(notes, suggestWildcards(getPolicy(), iamDef)...)
+	}
+
+	return notes
+}
+
+// getStatementResources returns the resources of a statement, which may be a single string or a list
+func getStatementResources(statement Statement) []string {
+	switch resource := statement.Resource.(type) {
+	case string:
+		return []string{resource}
+	case []string:
+		return resource
+	default:
+		return nil
 	}
-	return doc
 }
 
 func removeStatementItem(slice []Statement, i int) []Statement {
@@ -153,6 +307,122 @@ func aggregatePolicy(policy IAMPolicy) IAMPolicy {
 	return policy
 }
 
+// isSameStringSet returns whether two lists hold the same values, regardless of order
+func isSameStringSet(a []string, b []string) bool {
+	a = uniqueSlice(a)
+	b = uniqueSlice(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+// consolidateStatements merges statements with the same effect and resources by combining their actions, and
+// statements with the same effect and actions by combining their resources, until no more can be merged. The
+// statements hold no conditions, so these are not compared.
+func consolidateStatements(statements []Statement) []Statement {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(statements); i++ {
+			for j := i + 1; j < len(statements); j++ {
+				if statements[i].Effect != statements[j].Effect {
+					continue
+				}
+
+				resources := getStatementResources(statements[i])
+				otherResources := getStatementResources(statements[j])
+				switch {
+				case isSameStringSet(resources, otherResources):
+					statements[i].Action = append(statements[i].Action, statements[j].Action...)
+				case isSameStringSet(statements[i].Action, statements[j].Action):
+					statements[i].Resource = append(append([]string{}, resources...), otherResources...)
+				default:
+					continue
+				}
+
+				statements[i].Action = uniqueSlice(statements[i].Action)
+				sort.Strings(statements[i].Action)
+				mergedResources := uniqueSlice(getStatementResources(statements[i]))
+				sort.Strings(mergedResources)
+				statements[i].Resource = mergedResources
+
+				statements = removeStatementItem(statements, j)
+				j--
+				merged = true
+			}
+		}
+	}
+
+	return statements
+}
+
+// recordCall adds a call to the call log, returning false if it was filtered out
+func recordCall(entry Entry) bool {
+	if !applyPodLabels(&entry) {
+		return false
+	}
+
+	if *ignorePaginationFlag && isPaginatedCall(entry) {
+		return false
+	}
+
+	if isIgnoredReadOnlyCall(entry) {
+		return false
+	}
+
+	if !isServiceIncluded(entry.Service) || !isActionIncluded(entry) {
+		return false
+	}
+
+	if !isStatusCodeRecorded(entry.FinalHTTPStatusCode) {
+		return false
+	}
+
+	entry.EventSource = *eventSourceFlag
+	entry.SourceAnnotation = *entrySourceAnnotationFlag
+	entry.SessionID = *sessionIDFlag
+	if entry.Type == "ProxyCall" {
+		if serviceDef, ok := getServiceDefinitionByID(entry.Service); ok {
+			entry.ResourceARNs = inferResourceARNs(serviceDef, entry.Method, entry.Parameters, entry.URIParameters, entry.Region, getPolicyAccountID())
+		}
+	}
+	if *tagEntriesByTimeBucketFlag > 0 {
+		entry.TimeBucket = getTimeBucket(entry.Timestamp)
+	}
+
+	callLog.Append(entry)
+
+	if persistentLog != nil {
+		persistentLog.Append(entry)
+	}
+
+	if dynamoDBExportQueue != nil {
+		queueDynamoDBExport(entry)
+	}
+
+	checkNewActions(entry)
+
+	callEventBroker.Publish(entry)
+	checkAutoStop(entry)
+
+	return true
+}
+
+var paginationTokenParams = []string{"nexttoken", "marker", "continuationtoken", "paginationtoken", "startingtoken", "nextpagetoken"}
+
+// isPaginatedCall returns true if the call is a subsequent page of a paginated call, which is assumed to need
+// no more permissions than the first page
+func isPaginatedCall(entry Entry) bool {
+	for param, values := range entry.Parameters {
+		for _, paginationTokenParam := range paginationTokenParams {
+			if strings.ToLower(param) == paginationTokenParam && len(values) > 0 && values[0] != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func handleLoggedCall() {
 	// when making many calls in parallel, the terminal can be glitchy
 	// if we flush too often, optional flush on timer
@@ -172,11 +442,18 @@ func countRune(s string, r rune) int {
 }
 
 func writePolicyToTerminal() {
-	if len(callLog) == 0 {
+	if callLog.Len() == 0 || *uiFlag { // the terminal UI shows the policy on request
 		return
 	}
 
-	policyDoc := string(getPolicyDocument())
+	policyDoc := string(getPolicyOutput())
+	for _, additionalOutput := range getAdditionalOutputs() {
+		policyDoc += "\n\n" + string(additionalOutput.Output)
+	}
+	notes := getPolicyNotes()
+	if len(notes) > 0 {
+		policyDoc += "\n\n" + strings.Join(notes, "\n")
+	}
 	policyHeight := countRune(policyDoc, '\n') + 1
 
 	goterm.Clear()
@@ -224,6 +501,7 @@ type iamDefPrivilege struct {
 	Privilege     string               `json:"privilege"`
 	ResourceTypes []iamDefResourceType `json:"resource_types"`
 	Description   string               `json:"description"`
+	AccessLevel   string               `json:"access_level"`
 }
 
 type iamDefResource struct {
@@ -331,11 +609,27 @@ func setTerminalRefresh() {
 	}()
 }
 
+func setEntryTTLPurge() {
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		for range ticker.C {
+			purgeExpiredEntries(time.Now().Add(-*entryTTLFlag))
+			writePolicyToFile()
+		}
+	}()
+}
+
+func purgeExpiredEntries(cutoff time.Time) {
+	callLog.Retain(func(entry Entry) bool {
+		return entry.Timestamp.After(cutoff)
+	})
+}
+
 type resourceType struct {
 	ResourceType string `json:"resourceType"`
 }
 
-func resolveSpecials(arn string, call Entry, mandatory bool) []string {
+func resolveSpecials(arn string, call Entry, accountID string, mandatory bool) []string {
 	startIndex := strings.Index(arn, "%%")
 	endIndex := strings.LastIndex(arn, "%%")
 
@@ -356,7 +650,7 @@ func resolveSpecials(arn string, call Entry, mandatory bool) []string {
 				return []string{arn[0:startIndex] + "*" + arn[endIndex+2:]}
 			}
 
-			fullyResolved, arns := subARNParameters(parts[1], call, true)
+			fullyResolved, arns := subARNParameters(parts[1], call, accountID, true)
 
 			if len(arns) < 1 || arns[0] == "" || !fullyResolved {
 				if parts[3] == "" {
@@ -380,7 +674,7 @@ func resolveSpecials(arn string, call Entry, mandatory bool) []string {
 				return []string{arn[0:startIndex] + "*" + arn[endIndex+2:]}
 			}
 
-			fullyResolved, arns := subARNParameters(parts[1], call, true)
+			fullyResolved, arns := subARNParameters(parts[1], call, accountID, true)
 			if len(arns) < 1 || arns[0] == "" || !fullyResolved {
 				if mandatory {
 					return []string{arn[0:startIndex] + "*" + arn[endIndex+2:]}
@@ -393,7 +687,7 @@ func resolveSpecials(arn string, call Entry, mandatory bool) []string {
 			manyParts := []string{}
 
 			for _, part := range parts[1:] {
-				fullyResolved, arns := subARNParameters(part, call, true)
+				fullyResolved, arns := subARNParameters(part, call, accountID, true)
 				if len(arns) < 1 || arns[0] == "" || !fullyResolved {
 					if mandatory {
 						return []string{arn[0:startIndex] + "*" + arn[endIndex+2:]}
@@ -410,7 +704,7 @@ func resolveSpecials(arn string, call Entry, mandatory bool) []string {
 				return []string{arn[0:startIndex] + "*" + arn[endIndex+2:]}
 			}
 
-			fullyResolved, arns := subARNParameters(parts[1], call, true)
+			fullyResolved, arns := subARNParameters(parts[1], call, accountID, true)
 
 			if len(arns) < 1 || arns[0] == "" || !fullyResolved {
 				if mandatory {
@@ -442,21 +736,31 @@ func resolveSpecials(arn string, call Entry, mandatory bool) []string {
 	return []string{arn}
 }
 
-func getStatementsForProxyCall(call Entry) (statements []Statement) {
-	lowerPriv := strings.ToLower(fmt.Sprintf("%s.%s", call.Service, call.Method))
+func getStatementsForProxyCall(call Entry, accountID string) (statements []Statement) {
+	if *iamRoleNameFlag != "" && strings.ToLower(call.Service) == "iam" && len(call.Parameters["RoleName"]) == 0 {
+		call.Parameters = copyParameters(call.Parameters)
+		call.Parameters["RoleName"] = []string{*iamRoleNameFlag} // assume the call refers to the role itself
+	}
+
+	// service IDs such as "Route 53" appear without spaces in the mappings
+	lowerPriv := fmt.Sprintf("%s.%s", normalizeServiceName(call.Service), strings.ToLower(call.Method))
 
 	for iamMapMethodName, iamMapMethods := range iamMap.SDKMethodIAMMappings {
 		if strings.ToLower(iamMapMethodName) == lowerPriv {
 			for mappedPrivIndex, mappedPriv := range iamMapMethods {
 				resources := []string{}
+				mappedPrivPrefix, mappedPrivName := "", ""
+				if mappedPrivSplit := strings.SplitN(mappedPriv.Action, ":", 2); len(mappedPrivSplit) == 2 {
+					mappedPrivPrefix, mappedPrivName = mappedPrivSplit[0], mappedPrivSplit[1]
+				}
 
 				// arn_override
 				if mappedPriv.ArnOverride.Template != "" {
-					arns := resolveSpecials(mappedPriv.ArnOverride.Template, call, false)
+					arns := resolveSpecials(mappedPriv.ArnOverride.Template, call, accountID, false)
 
 					if len(arns) == 0 || len(arns) > 1 || arns[0] != "" { // skip if empty after resolving specials
 						for _, arn := range arns {
-							fullyResolved, subbedArns := subARNParameters(arn, call, false)
+							fullyResolved, subbedArns := subARNParameters(arn, call, accountID, false)
 							for _, subbedArn := range subbedArns {
 								if mappedPrivIndex == 0 || fullyResolved {
 									resources = append(resources, subbedArn) // sub full parameters and add to resources
@@ -473,15 +777,15 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 				// resourcearn_mappings
 				if len(mappedPriv.ResourceARNMappings) > 0 {
 					for _, service := range iamDef { // in the SAR
-						if service.Prefix == strings.ToLower(call.Service) { // find the service for the call
+						if strings.EqualFold(service.Prefix, mappedPrivPrefix) { // find the service of the mapped action
 							for _, servicePrivilege := range service.Privileges {
-								if strings.ToLower(call.Method) == strings.ToLower(servicePrivilege.Privilege) { // find the method for the call
+								if strings.EqualFold(servicePrivilege.Privilege, mappedPrivName) { // find the mapped action, which may be named differently to the method (e.g. Invoke is lambda:InvokeFunction)
 									for _, resourceType := range servicePrivilege.ResourceTypes { // get all resource types for the privilege
 										for mapResType, mapResTemplate := range mappedPriv.ResourceARNMappings {
 											if strings.Replace(resourceType.ResourceType, "*", "", -1) == mapResType {
 												mandatory := strings.HasSuffix(resourceType.ResourceType, "*")
 
-												resARNMappingTemplates := resolveSpecials(mapResTemplate, call, false)
+												resARNMappingTemplates := resolveSpecials(mapResTemplate, call, accountID, false)
 												if len(resARNMappingTemplates) == 1 && resARNMappingTemplates[0] == "" {
 													continue
 												}
@@ -491,7 +795,7 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 												}
 
 												for _, resARNMappingTemplate := range resARNMappingTemplates {
-													fullyResolved, subbedArns := subARNParameters(resARNMappingTemplate, call, false)
+													fullyResolved, subbedArns := subARNParameters(resARNMappingTemplate, call, accountID, false)
 													if mandatory || fullyResolved { // check if mandatory or fully resolved
 														resources = append(resources, subbedArns...) // sub full parameters and add to resources
 													}
@@ -508,9 +812,9 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 				// resource_mappings
 				if len(resources) == 0 {
 					for _, service := range iamDef { // in the SAR
-						if service.Prefix == strings.ToLower(call.Service) { // find the service for the call
+						if strings.EqualFold(service.Prefix, mappedPrivPrefix) { // find the service of the mapped action
 							for _, servicePrivilege := range service.Privileges {
-								if strings.ToLower(call.Method) == strings.ToLower(servicePrivilege.Privilege) { // find the method for the call
+								if strings.EqualFold(servicePrivilege.Privilege, mappedPrivName) { // find the mapped action, which may be named differently to the method (e.g. Invoke is lambda:InvokeFunction)
 									for _, resourceType := range servicePrivilege.ResourceTypes { // get all resource types for the privilege
 										for _, resource := range service.Resources { // go through the service resources
 											if resource.Resource == strings.Replace(resourceType.ResourceType, "*", "", -1) && resource.Resource != "" { // match the resource type (doesn't matter if mandatory)
@@ -520,7 +824,7 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 
 												// substitute the resource_mappings
 												for resMappingVar, resMapping := range mappedPriv.ResourceMappings { // for each mapping
-													resMappingTemplates := resolveSpecials(resMapping.Template, call, false) // get a list of resolved template strings
+													resMappingTemplates := resolveSpecials(resMapping.Template, call, accountID, false) // get a list of resolved template strings
 
 													if len(resMappingTemplates) == 1 && resMappingTemplates[0] == "" {
 														continue
@@ -542,7 +846,7 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 												}
 
 												for _, arn := range arns {
-													fullyResolved, subbedArns := subARNParameters(arn, call, false)
+													fullyResolved, subbedArns := subARNParameters(arn, call, accountID, false)
 													if mandatory || fullyResolved { // check if mandatory or fully resolved
 														resources = append(resources, subbedArns...) // sub full parameters and add to resources
 													}
@@ -561,6 +865,10 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 					resources = []string{"*"}
 				}
 
+				if *inferARNsFromTagsFlag {
+					resources = inferResourceARNsFromTags(resources, mappedPriv.Action, call, accountID)
+				}
+
 				statements = append(statements, Statement{
 					Effect:   "Allow",
 					Resource: resources,
@@ -570,10 +878,61 @@ func getStatementsForProxyCall(call Entry) (statements []Statement) {
 		}
 	}
 
+	if len(statements) == 0 && isJSONPathMappedService(call.Service) { // custom services have no mappings
+		statements = append(statements, Statement{
+			Effect:   "Allow",
+			Resource: []string{"*"},
+			Action:   getActions(call.Service, call.Method),
+		})
+	}
+
 	return statements
 }
 
-func subARNParameters(arn string, call Entry, specialsOnly bool) (bool, []string) {
+// getPolicyAccountID returns the account ID of the resources in policies, wildcarded while --account-id is the
+// placeholder so that policies never name an account that isn't the caller's
+func getPolicyAccountID() string {
+	if *accountIDFlag == placeholderAccountID {
+		return "*"
+	}
+
+	return *accountIDFlag
+}
+
+// inferResourceARNs returns the specific resource ARNs that a call of an action operates on, leaving out wildcarded
+// resources
+func inferResourceARNs(svc ServiceDefinition, action string, params map[string][]string, uriparams map[string]string, region string, accountID string) []string {
+	call := Entry{
+		Region:        region,
+		Type:          "ProxyCall",
+		Service:       svc.Metadata.ServiceID,
+		Method:        action,
+		Parameters:    params,
+		URIParameters: uriparams,
+	}
+
+	var arns []string
+	for _, statement := range getStatementsForProxyCall(call, accountID) {
+		for _, resource := range getStatementResources(statement) {
+			if resource != "*" && !strings.HasSuffix(resource, "/*") {
+				arns = append(arns, resource)
+			}
+		}
+	}
+
+	return uniqueSlice(arns)
+}
+
+func copyParameters(params map[string][]string) map[string][]string {
+	copied := make(map[string][]string)
+	for k, v := range params {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+func subARNParameters(arn string, call Entry, accountID string, specialsOnly bool) (bool, []string) {
 	arns := []string{arn}
 
 	// parameter substitution
@@ -608,7 +967,6 @@ func subARNParameters(arn string, call Entry, specialsOnly bool) (bool, []string
 		return !anyMatched, arns
 	}
 
-	account := *accountIDFlag
 	partition := "aws"
 	if call.Region[0:3] == "cn-" {
 		partition = "aws-cn"
@@ -622,7 +980,7 @@ func subARNParameters(arn string, call Entry, specialsOnly bool) (bool, []string
 	for _, arn := range arns {
 		arn = regexp.MustCompile(`\$\{Partition\}`).ReplaceAllString(arn, partition)
 		arn = regexp.MustCompile(`\$\{Region\}`).ReplaceAllString(arn, call.Region)
-		arn = regexp.MustCompile(`\$\{Account\}`).ReplaceAllString(arn, account)
+		arn = regexp.MustCompile(`\$\{Account\}`).ReplaceAllString(arn, accountID)
 		unresolvedArn := arn
 		arn = regexp.MustCompile(`\$\{.+?\}`).ReplaceAllString(arn, "*") // TODO: preserve ${aws:*} variables
 		if unresolvedArn != arn {
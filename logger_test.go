@@ -0,0 +1,510 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConcurrentRecording(t *testing.T) {
+	resetTestCallLog(t)
+
+	const goroutines = 50
+	methods := []string{"ListBuckets", "GetCallerIdentity", "DescribeInstances"}
+	services := []string{"S3", "STS", "EC2"}
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < goroutines; i++ {
+			i := i
+			t.Run(fmt.Sprintf("recorder-%d", i), func(t *testing.T) {
+				t.Parallel()
+
+				entry := Entry{
+					Region:              "us-east-1",
+					Type:                "ApiCall",
+					Service:             services[i%len(services)],
+					Method:              methods[i%len(methods)],
+					FinalHTTPStatusCode: 200,
+					Timestamp:           time.Now(),
+				}
+				if !recordCall(entry) {
+					t.Errorf("call %d was not recorded", i)
+				}
+				callLog.Append(entry)
+				snapshot := callLog.Snapshot()
+				if len(snapshot) == 0 {
+					t.Errorf("the snapshot read by recorder %d is empty", i)
+				}
+
+				// the policy is slow to generate under the race detector, so only some recorders read it
+				if i%10 == 0 {
+					if policy := getPolicyForEntries(snapshot); len(policy.Statement) == 0 {
+						t.Errorf("the policy read by recorder %d has no statements", i)
+					}
+				}
+			})
+		}
+	})
+
+	if got := callLog.Len(); got != 2*goroutines {
+		t.Fatalf("got %d calls in the log, want %d", got, 2*goroutines)
+	}
+
+	actions := make(map[string]bool)
+	for _, statement := range getPolicyForEntries(callLog.Snapshot()).Statement {
+		for _, action := range statement.Action {
+			actions[action] = true
+		}
+	}
+	for _, action := range []string{"s3:ListAllMyBuckets", "sts:GetCallerIdentity", "ec2:DescribeInstances"} {
+		if !actions[action] {
+			t.Errorf("the policy is missing %s, got %v", action, actions)
+		}
+	}
+}
+
+func appendTestResourceCalls() {
+	for _, name := range []string{"a", "b", "c"} {
+		callLog.Append(Entry{
+			Region:              "us-east-1",
+			Type:                "ProxyCall",
+			Service:             "S3",
+			Method:              "GetObject",
+			URIParameters:       map[string]string{"Bucket": name, "Key": "k"},
+			FinalHTTPStatusCode: 200,
+			Timestamp:           time.Now(),
+		})
+		callLog.Append(Entry{
+			Region:              "us-east-1",
+			Type:                "ProxyCall",
+			Service:             "DynamoDB",
+			Method:              "GetItem",
+			Parameters:          map[string][]string{"TableName": {name}},
+			FinalHTTPStatusCode: 200,
+			Timestamp:           time.Now(),
+		})
+	}
+}
+
+// getTestPolicyResources returns the resources of the statements within a JSON policy document
+func getTestPolicyResources(t *testing.T, doc []byte) []string {
+	t.Helper()
+
+	var policy struct {
+		Statement []struct {
+			Resource interface{}
+		}
+	}
+	if err := json.Unmarshal(doc, &policy); err != nil {
+		t.Fatalf("the output is not valid JSON: %v", err)
+	}
+	var resources []string
+	for _, statement := range policy.Statement {
+		switch resource := statement.Resource.(type) {
+		case string:
+			resources = append(resources, resource)
+		case []interface{}:
+			for _, r := range resource {
+				resources = append(resources, r.(string))
+			}
+		}
+	}
+	return resources
+}
+
+// getTestYAMLStrings returns every string within a YAML document
+func getTestYAMLStrings(t *testing.T, doc []byte) []string {
+	t.Helper()
+
+	var root interface{}
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		t.Fatalf("the output is not valid YAML: %v\n%s", err, doc)
+	}
+
+	var stringsFound []string
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case string:
+			stringsFound = append(stringsFound, value)
+		case []interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		}
+	}
+	walk(root)
+
+	return stringsFound
+}
+
+func TestRedactAccountIDs(t *testing.T) {
+	const accountID = "210987654321"
+	const redactedTable = "arn:aws:dynamodb:us-east-1:XXXXXXXXXXXX:table/a"
+
+	tests := []struct {
+		name   string
+		format string
+		flags  map[string]string
+		// resources returns the strings of the output that should hold the redacted ARN
+		resources func(t *testing.T, output []byte) []string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			resources: func(t *testing.T, output []byte) []string {
+				return getTestPolicyResources(t, output)
+			},
+		},
+		{
+			name:   "env",
+			format: "env",
+			resources: func(t *testing.T, output []byte) []string {
+				doc := strings.TrimSuffix(strings.TrimPrefix(string(output), "export IAMLIVE_POLICY='"), "'\n")
+				return getTestPolicyResources(t, []byte(doc))
+			},
+		},
+		{
+			name:   "policy simulator input",
+			format: "aws-iam-policy-simulator-input",
+			resources: func(t *testing.T, output []byte) []string {
+				var input SimulatorInput
+				if err := json.Unmarshal(output, &input); err != nil {
+					t.Fatalf("the output is not valid JSON: %v", err)
+				}
+				return input.ResourceArns
+			},
+		},
+		{
+			name:   "cloudformation yaml",
+			format: "cloudformation-yaml",
+			resources: func(t *testing.T, output []byte) []string {
+				return getTestYAMLStrings(t, output)
+			},
+		},
+		{
+			name:   "terraform",
+			format: "terraform-hcl",
+			resources: func(t *testing.T, output []byte) []string {
+				var resources []string
+				for _, statement := range parseTerraformTestOutput(t, string(output)) {
+					resources = append(resources, statement.Resources...)
+				}
+				return resources
+			},
+		},
+		{
+			name:   "kustomize patch",
+			format: "kustomize-patch",
+			flags:  map[string]string{"role-arn": "arn:aws:iam::" + accountID + ":role/orders"},
+			resources: func(t *testing.T, output []byte) []string {
+				if !strings.Contains(string(output), "eks.amazonaws.com/role-arn: arn:aws:iam::XXXXXXXXXXXX:role/orders") {
+					t.Errorf("the role annotation is not redacted:\n%s", output)
+				}
+				return []string{redactedTable}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			setTestFlag(t, "mode", "proxy")
+			setTestFlag(t, "account-id", accountID)
+			setTestFlag(t, "redact-account-id", "true")
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+			appendTestResourceCalls()
+
+			output := getPolicyOutputForFormat(tt.format)
+			if strings.Contains(string(output), accountID) {
+				t.Errorf("the account ID is in the output:\n%s", output)
+			}
+			for _, additionalOutput := range getFormatAdditionalOutputs(tt.format) {
+				if strings.Contains(string(additionalOutput.Output), accountID) {
+					t.Errorf("the account ID is in the %s output:\n%s", additionalOutput.Suffix, additionalOutput.Output)
+				}
+			}
+
+			found := false
+			for _, resource := range tt.resources(t, output) {
+				if resource == redactedTable {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("the output has no %s resource:\n%s", redactedTable, output)
+			}
+		})
+	}
+}
+
+func TestRedactOutputAccountIDs(t *testing.T) {
+	setTestFlag(t, "redact-account-id", "true")
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{output: `"arn:aws:iam::210987654321:role/orders"`, want: `"arn:aws:iam::XXXXXXXXXXXX:role/orders"`},
+		{output: `arn:aws-us-gov:sqs:us-gov-west-1:210987654321:queue`, want: `arn:aws-us-gov:sqs:us-gov-west-1:XXXXXXXXXXXX:queue`},
+		{output: `arn:aws:s3:::bucket-210987654321/key`, want: `arn:aws:s3:::bucket-210987654321/key`},
+		{output: `"Timestamp": "210987654321"`, want: `"Timestamp": "210987654321"`},
+		{output: `arn:aws:iam::2109876543210:role/orders`, want: `arn:aws:iam::2109876543210:role/orders`},
+	}
+
+	for _, tt := range tests {
+		if got := string(redactOutputAccountIDs([]byte(tt.output))); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestInferResourceARNs(t *testing.T) {
+	tests := []struct {
+		name string
+		call Entry
+		want []string
+	}{
+		{
+			name: "S3 object",
+			call: Entry{Service: "S3", Method: "GetObject", URIParameters: map[string]string{"Bucket": "reports", "Key": "q1/summary.csv"}},
+			want: []string{"arn:aws:s3:::reports/q1/summary.csv"},
+		},
+		{
+			name: "DynamoDB table",
+			call: Entry{Service: "DynamoDB", Method: "GetItem", Parameters: map[string][]string{"TableName": {"orders"}}},
+			want: []string{"arn:aws:dynamodb:us-east-1:210987654321:table/orders"},
+		},
+		{
+			name: "Lambda function",
+			call: Entry{Service: "Lambda", Method: "Invoke", URIParameters: map[string]string{"FunctionName": "resize"}},
+			want: []string{"arn:aws:lambda:us-east-1:210987654321:function:resize"},
+		},
+		{
+			name: "KMS key",
+			call: Entry{Service: "KMS", Method: "Decrypt", Parameters: map[string][]string{"KeyId": {"1234abcd-12ab-34cd-56ef-1234567890ab"}}},
+			want: []string{"arn:aws:kms:us-east-1:210987654321:key/1234abcd-12ab-34cd-56ef-1234567890ab"},
+		},
+		{
+			name: "Secrets Manager secret",
+			call: Entry{Service: "SecretsManager", Method: "GetSecretValue", Parameters: map[string][]string{"SecretId": {"prod/db"}}},
+			want: []string{"arn:aws:secretsmanager:us-east-1:210987654321:secret:prod/db"},
+		},
+		{
+			name: "SQS queue URL",
+			call: Entry{Service: "SQS", Method: "SendMessage", Parameters: map[string][]string{"QueueUrl": {"https://sqs.us-east-1.amazonaws.com/210987654321/jobs"}}},
+			want: []string{"arn:aws:sqs:us-east-1:210987654321:jobs"},
+		},
+		{
+			name: "SNS topic",
+			call: Entry{Service: "SNS", Method: "Publish", Parameters: map[string][]string{"TopicArn": {"arn:aws:sns:us-east-1:210987654321:alerts"}}},
+			want: []string{"arn:aws:sns:us-east-1:210987654321:alerts"},
+		},
+		{
+			name: "IAM role",
+			call: Entry{Service: "IAM", Method: "GetRole", Parameters: map[string][]string{"RoleName": {"deployer"}}},
+			want: []string{"arn:aws:iam::210987654321:role/deployer"},
+		},
+		{
+			name: "CloudWatch Logs log group",
+			call: Entry{Service: "CloudWatchLogs", Method: "CreateLogStream", Parameters: map[string][]string{"logGroupName": {"app"}, "logStreamName": {"web"}}},
+			want: []string{"arn:aws:logs:us-east-1:210987654321:log-group:app"},
+		},
+		{
+			name: "EC2 instances",
+			call: Entry{Service: "EC2", Method: "TerminateInstances", Parameters: map[string][]string{"InstanceIds[]": {"i-0abc", "i-0def"}}},
+			want: []string{"arn:aws:ec2:us-east-1:210987654321:instance/i-0abc", "arn:aws:ec2:us-east-1:210987654321:instance/i-0def"},
+		},
+		{
+			name: "China partition",
+			call: Entry{Region: "cn-north-1", Service: "DynamoDB", Method: "GetItem", Parameters: map[string][]string{"TableName": {"orders"}}},
+			want: []string{"arn:aws-cn:dynamodb:cn-north-1:210987654321:table/orders"},
+		},
+		{
+			name: "no resource",
+			call: Entry{Service: "EC2", Method: "DescribeInstances"},
+		},
+		{
+			name: "missing parameter",
+			call: Entry{Service: "DynamoDB", Method: "GetItem"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.call.Type = "ProxyCall"
+			if tt.call.Region == "" {
+				tt.call.Region = "us-east-1"
+			}
+			svc := ServiceDefinition{Metadata: ServiceDefinitionMetadata{ServiceID: tt.call.Service}}
+			got := inferResourceARNs(svc, tt.call.Method, tt.call.Parameters, tt.call.URIParameters, tt.call.Region, "210987654321")
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCallResourceARNs(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+	setTestFlag(t, "account-id", "210987654321")
+
+	recordCall(Entry{Region: "us-east-1", Type: "ProxyCall", Service: "DynamoDB", Method: "GetItem", Parameters: map[string][]string{"TableName": {"orders"}}, FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	entries := callLog.Snapshot()
+	if len(entries) != 1 {
+		t.Fatalf("got %d calls, want 1", len(entries))
+	}
+	want := "arn:aws:dynamodb:us-east-1:210987654321:table/orders"
+	if fmt.Sprint(entries[0].ResourceARNs) != fmt.Sprint([]string{want}) {
+		t.Errorf("got resource ARNs %q, want %s", entries[0].ResourceARNs, want)
+	}
+	if output := string(getPolicyDocument()); !strings.Contains(output, `"`+want+`"`) || strings.Contains(output, `"*"`) {
+		t.Errorf("the policy does not use the table ARN as its resource:\n%s", output)
+	}
+}
+
+func TestRecordCallResourceARNsPlaceholderAccount(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+	setTestFlag(t, "account-id", placeholderAccountID)
+
+	recordCall(Entry{Region: "us-east-1", Type: "ProxyCall", Service: "DynamoDB", Method: "GetItem", Parameters: map[string][]string{"TableName": {"orders"}}, FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	// without --account-id, the account is wildcarded rather than naming the placeholder account
+	want := "arn:aws:dynamodb:us-east-1:*:table/orders"
+	if entries := callLog.Snapshot(); len(entries) != 1 || fmt.Sprint(entries[0].ResourceARNs) != fmt.Sprint([]string{want}) {
+		t.Errorf("got calls %+v, want one with resource ARN %s", entries, want)
+	}
+	if output := string(getPolicyDocument()); !strings.Contains(output, `"`+want+`"`) || strings.Contains(output, placeholderAccountID) {
+		t.Errorf("the policy does not wildcard the account of the table ARN:\n%s", output)
+	}
+}
+
+func TestConsolidateStatements(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []Statement
+		want       []Statement
+	}{
+		{
+			name: "same resources",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"s3:PutObject"}, Resource: []string{"arn:aws:s3:::reports/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::reports/*"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:PutObject"}, Resource: []string{"arn:aws:s3:::reports/*"}},
+			},
+		},
+		{
+			name: "same actions",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"dynamodb:GetItem"}, Resource: []string{"arn:aws:dynamodb:us-east-1:123456789012:table/orders"}},
+				{Effect: "Allow", Action: []string{"dynamodb:GetItem"}, Resource: []string{"arn:aws:dynamodb:us-east-1:123456789012:table/customers"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"dynamodb:GetItem"}, Resource: []string{"arn:aws:dynamodb:us-east-1:123456789012:table/customers", "arn:aws:dynamodb:us-east-1:123456789012:table/orders"}},
+			},
+		},
+		{
+			name: "three-way merge of actions",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"sqs:SendMessage"}, Resource: []string{"arn:aws:sqs:us-east-1:123456789012:jobs"}},
+				{Effect: "Allow", Action: []string{"sqs:ReceiveMessage"}, Resource: []string{"arn:aws:sqs:us-east-1:123456789012:jobs"}},
+				{Effect: "Allow", Action: []string{"sqs:DeleteMessage"}, Resource: []string{"arn:aws:sqs:us-east-1:123456789012:jobs"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"sqs:DeleteMessage", "sqs:ReceiveMessage", "sqs:SendMessage"}, Resource: []string{"arn:aws:sqs:us-east-1:123456789012:jobs"}},
+			},
+		},
+		{
+			name: "three-way merge of resources",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: []string{"arn:aws:kms:us-east-1:123456789012:key/c"}},
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: []string{"arn:aws:kms:us-east-1:123456789012:key/a"}},
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: []string{"arn:aws:kms:us-east-1:123456789012:key/b"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: []string{"arn:aws:kms:us-east-1:123456789012:key/a", "arn:aws:kms:us-east-1:123456789012:key/b", "arn:aws:kms:us-east-1:123456789012:key/c"}},
+			},
+		},
+		{
+			name: "merged actions then merged resources",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:PutObject"}, Resource: []string{"arn:aws:s3:::b/*"}},
+				{Effect: "Allow", Action: []string{"s3:PutObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:PutObject"}, Resource: []string{"arn:aws:s3:::a/*", "arn:aws:s3:::b/*"}},
+			},
+		},
+		{
+			name: "overlapping statements are not over-granted",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:DeleteObject"}, Resource: []string{"arn:aws:s3:::b/*"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:DeleteObject"}, Resource: []string{"arn:aws:s3:::b/*"}},
+			},
+		},
+		{
+			name: "allow and deny kept apart",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"iam:GetRole"}, Resource: "*"},
+				{Effect: "Deny", Action: []string{"iam:PassRole"}, Resource: "*"},
+				{Effect: "Allow", Action: []string{"iam:ListRoles"}, Resource: []string{"*"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"iam:GetRole", "iam:ListRoles"}, Resource: []string{"*"}},
+				{Effect: "Deny", Action: []string{"iam:PassRole"}, Resource: "*"},
+			},
+		},
+		{
+			name: "resources in a different order and duplicate actions",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"sns:Publish"}, Resource: []string{"arn:aws:sns:us-east-1:123456789012:b", "arn:aws:sns:us-east-1:123456789012:a"}},
+				{Effect: "Allow", Action: []string{"sns:Publish", "sns:GetTopicAttributes"}, Resource: []string{"arn:aws:sns:us-east-1:123456789012:a", "arn:aws:sns:us-east-1:123456789012:b"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"sns:GetTopicAttributes", "sns:Publish"}, Resource: []string{"arn:aws:sns:us-east-1:123456789012:a", "arn:aws:sns:us-east-1:123456789012:b"}},
+			},
+		},
+		{
+			name: "unrelated statements",
+			statements: []Statement{
+				{Effect: "Allow", Action: []string{"ec2:DescribeInstances"}, Resource: "*"},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+			},
+			want: []Statement{
+				{Effect: "Allow", Action: []string{"ec2:DescribeInstances"}, Resource: "*"},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := consolidateStatements(tt.statements)
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("got %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"runtime/pprof"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"gopkg.in/ini.v1"
@@ -25,8 +26,124 @@ var bindAddrFlag *string
 var caBundleFlag *string
 var caKeyFlag *string
 var accountIDFlag *string
+var jsonPathMappingFlag *string
+var outputFormatFlag *string
+var kubesealNamespaceFlag *string
+var kubesealSecretNameFlag *string
+var kubesealCertFileFlag *string
+var entryTTLFlag *time.Duration
+var kubernetesLabelFilterFlag *string
+var simulateRegionFailoverFlag *string
+var envVarNameFlag *string
+var checkRequiredActionsFlag *string
+var failIfEmptyFlag *bool
+var failIfEmptyTimeoutFlag *time.Duration
+var redactAccountIDFlag *bool
+var githubRepoFlag *string
+var githubBranchFlag *string
+var networkPolicyModeFlag *bool
+var coverageFlag *string
+var minCoverageFlag *float64
+var proxyAuthFlag *string
+var proxyAuthFileFlag *string
+var roleARNFlag *string
+var serviceAccountNameFlag *string
+var serviceAccountNamespaceFlag *string
+var inferARNsFromTagsFlag *bool
+var awsProfileFlag *string
+var auditLogFlag *string
+var ignorePaginationFlag *bool
+var requestIDHeaderFlag *string
+var eventSourceFlag *string
+var maxParamDepthFlag *int
+var exportToVaultFlag *string
+var vaultNamespaceFlag *string
+var caValidityFromExistingFlag *int
+var trackResponseSizeFlag *bool
+var includeErrorEntriesFlag *bool
+var excludeErrorEntriesFlag *bool
+var includeOnlyStatusCodesFlag *string
+var iamRoleNameFlag *string
+var ignoreDuplicateParamsFlag *bool
+var backstageComponentNameFlag *string
+var accessLogFileFlag *string
+var checkCloudTrailFlag *string
+var packerImageNameFlag *string
+var exportToGitHubGistFlag *bool
+var gistPrivateFlag *bool
+var gistDescriptionFlag *string
+var tagEntriesByTimeBucketFlag *time.Duration
+var detectPrivilegeEscalationFlag *bool
+var azureRoleNameFlag *string
+var azureAssignableScopeFlag *string
+var vaultAWSMountPathFlag *string
+var noEntryOnActionStarFlag *bool
+var actionStarLogFlag *string
+var bindInterfaceFlag *string
+var awsSingleUseCredentialsFlag *bool
+var exportToDynamoDBFlag *string
+var dynamoDBRegionFlag *string
+var includeFailedCallsFlag *bool
+var perRequestTimeoutFlag *time.Duration
+var entrySourceAnnotationFlag *string
+var cfnStackNameFlag *string
+var logRequestHeadersFlag *string
+var regionFilterFlag *string
+var ssoInstanceARNFlag *string
+var permissionSetNameFlag *string
+var sessionIDFlag *string
+var rawActionsPrefixFlag *bool
+var upstreamProxyFlag *string
+var upstreamProxyUserFlag *string
+var upstreamProxyPassFlag *string
+var configFlag *string
+var printConfigFlag *bool
+var alertOnNewActionFlag *bool
+var knownActionsFileFlag *string
+var webhookURLFlag *string
+var failOnNewActionFlag *bool
+var persistLogFlag *string
+var replaySessionIDFlag *string
+var replayExcludeSessionIDFlag *string
+var ignoreReadOnlyRegionFlag *string
+var cwRuleNameFlag *string
+var cwLogGroupNameFlag *string
+var cloudTrailTimeRangeFlag *string
+var cloudTrailPrincipalFlag *string
+var includeActionsFlag *string
+var excludeActionsFlag *string
+var includeServicesFlag *string
+var excludeServicesFlag *string
+var listServicesFlag *bool
+var caKeyTypeFlag *string
+var rotateCAFlag *bool
+var httpPortFlag *int
+var httpHostFlag *string
+var uiFlag *bool
+var timeoutFlag *time.Duration
+var maxUniquePairsFlag *int
+var diffFormatFlag *string
+var maxPolicySizeFlag *int
+var suggestWildcardsFlag *bool
+var wildcardThresholdFlag *int
+var wildcardExtraPctFlag *float64
+var localStackFlag *bool
+var localStackPortFlag *int
+var defaultRegionFlag *string
+var serviceDirFlag *string
+var metricsPortFlag *int
+var metricsHostFlag *string
+var chainRolesFlag *bool
+var principalMapFlag *string
+var splitByPrincipalFlag *bool
+var outputFormatsFlag *string
+var outputDirFlag *string
+var htmlTitleFlag *string
 var cpuProfileFlag = flag.String("cpu-profile", "", "[experimental] write a CPU profile to this file (for performance testing purposes)")
 
+// placeholderAccountID is the --account-id default, which resource ARNs replace with * (see getPolicyAccountID)
+const placeholderAccountID = "123456789012"
+
 func parseConfig() {
 	setIni := false
 	profile := "default"
@@ -35,16 +152,133 @@ func parseConfig() {
 	refreshRate := 0
 	sortAlphabetical := false
 	host := "127.0.0.1"
-	mode := "csm"
+	mode := "proxy"
 	bindAddr := "127.0.0.1:10080"
 	caBundle := "~/.iamlive/ca.pem"
 	caKey := "~/.iamlive/ca.key"
-	accountID := "123456789012"
+	accountID := placeholderAccountID
+	jsonPathMapping := ""
+	outputFormat := "json"
+	kubesealNamespace := "default"
+	kubesealSecretName := "iamlive-policy"
+	kubesealCertFile := ""
+	entryTTL := time.Duration(0)
+	kubernetesLabelFilter := ""
+	simulateRegionFailover := ""
+	envVarName := "IAMLIVE_POLICY"
+	checkRequiredActions := ""
+	failIfEmpty := false
+	failIfEmptyTimeout := time.Duration(0)
+	redactAccountID := false
+	githubRepo := ""
+	githubBranch := ""
+	networkPolicyMode := false
+	coverage := ""
+	minCoverage := 0.0
+	proxyAuth := ""
+	proxyAuthFile := ""
+	roleARN := ""
+	serviceAccountName := "default"
+	serviceAccountNamespace := "default"
+	inferARNsFromTags := false
+	awsProfile := ""
+	auditLog := ""
+	ignorePagination := false
+	requestIDHeader := ""
+	eventSource := ""
+	maxParamDepth := 20
+	exportToVault := ""
+	vaultNamespace := ""
+	caValidityFromExisting := 0
+	trackResponseSize := false
+	includeErrorEntries := true
+	excludeErrorEntries := false
+	includeOnlyStatusCodes := ""
+	iamRoleName := ""
+	ignoreDuplicateParams := false
+	backstageComponentName := "iamlive"
+	accessLogFile := ""
+	checkCloudTrail := ""
+	packerImageName := ""
+	exportToGitHubGist := false
+	gistPrivate := true
+	gistDescription := "IAM policy generated by iamlive"
+	tagEntriesByTimeBucket := time.Duration(0)
+	detectPrivilegeEscalation := false
+	azureRoleName := "iamlive"
+	azureAssignableScope := "/subscriptions/00000000-0000-0000-0000-000000000000"
+	vaultAWSMountPath := "aws"
+	noEntryOnActionStar := false
+	actionStarLog := ""
+	bindInterface := ""
+	awsSingleUseCredentials := false
+	exportToDynamoDB := ""
+	dynamoDBRegion := ""
+	includeFailedCalls := false
+	perRequestTimeout := time.Duration(0)
+	entrySourceAnnotation := ""
+	cfnStackName := "iamlive"
+	logRequestHeaders := ""
+	regionFilter := ""
+	ssoInstanceARN := ""
+	permissionSetName := "iamlive"
+	sessionID := ""
+	rawActionsPrefix := true
+	upstreamProxy := getEnvUpstreamProxy()
+	if upstreamProxy != "" {
+		configSources["upstream-proxy"] = configSourceEnv
+	}
+	upstreamProxyUser := ""
+	upstreamProxyPass := ""
+	alertOnNewAction := false
+	knownActionsFile := "~/.iamlive/known-actions"
+	webhookURL := ""
+	failOnNewAction := false
+	persistLog := ""
+	replaySessionID := ""
+	replayExcludeSessionID := ""
+	ignoreReadOnlyRegion := ""
+	cwRuleName := "iamlive"
+	cwLogGroupName := ""
+	cloudTrailTimeRange := ""
+	cloudTrailPrincipal := ""
+	includeActions := ""
+	excludeActions := ""
+	includeServices := ""
+	excludeServices := ""
+	listServices := false
+	caKeyType := "rsa"
+	rotateCA := false
+	httpPort := 0
+	httpHost := "127.0.0.1"
+	ui := false
+	timeout := time.Duration(0)
+	maxUniquePairs := 0
+	diffFormat := "text"
+	maxPolicySize := managedPolicySizeLimit
+	suggestWildcards := false
+	wildcardThreshold := 5
+	wildcardExtraPct := 20.0
+	localStack := false
+	localStackPort := 4566
+	defaultRegion := "us-east-1"
+	serviceDir := ""
+	metricsPort := 0
+	metricsHost := "127.0.0.1"
+	chainRoles := false
+	principalMap := ""
+	splitByPrincipal := false
+	outputFormats := ""
+	outputDir := ""
+	htmlTitle := "iamlive report"
 
 	cfgfile, err := homedir.Expand("~/.iamlive/config")
 	if err == nil {
 		cfg, err := ini.Load(cfgfile)
 		if err == nil {
+			for _, key := range cfg.Section("").Keys() {
+				configSources[key.Name()] = getConfigFileSource(cfgfile)
+			}
 			if cfg.Section("").HasKey("set-ini") {
 				setIni, _ = cfg.Section("").Key("set-ini").Bool()
 			}
@@ -81,12 +315,348 @@ func parseConfig() {
 			if cfg.Section("").HasKey("account-id") {
 				accountID = cfg.Section("").Key("account-id").String()
 			}
+			if cfg.Section("").HasKey("json-path-mapping") {
+				jsonPathMapping = cfg.Section("").Key("json-path-mapping").String()
+			}
+			if cfg.Section("").HasKey("output-type") {
+				outputFormat = cfg.Section("").Key("output-type").String()
+			}
+			if cfg.Section("").HasKey("output-format") {
+				outputFormat = cfg.Section("").Key("output-format").String()
+			}
+			if cfg.Section("").HasKey("kubeseal-namespace") {
+				kubesealNamespace = cfg.Section("").Key("kubeseal-namespace").String()
+			}
+			if cfg.Section("").HasKey("kubeseal-secret-name") {
+				kubesealSecretName = cfg.Section("").Key("kubeseal-secret-name").String()
+			}
+			if cfg.Section("").HasKey("kubeseal-cert-file") {
+				kubesealCertFile = cfg.Section("").Key("kubeseal-cert-file").String()
+			}
+			if cfg.Section("").HasKey("entry-ttl") {
+				entryTTL, _ = cfg.Section("").Key("entry-ttl").Duration()
+			}
+			if cfg.Section("").HasKey("kubernetes-label-filter") {
+				kubernetesLabelFilter = cfg.Section("").Key("kubernetes-label-filter").String()
+			}
+			if cfg.Section("").HasKey("simulate-region-failover") {
+				simulateRegionFailover = cfg.Section("").Key("simulate-region-failover").String()
+			}
+			if cfg.Section("").HasKey("env-var-name") {
+				envVarName = cfg.Section("").Key("env-var-name").String()
+			}
+			if cfg.Section("").HasKey("check-required-actions") {
+				checkRequiredActions = cfg.Section("").Key("check-required-actions").String()
+			}
+			if cfg.Section("").HasKey("fail-if-empty") {
+				failIfEmpty, _ = cfg.Section("").Key("fail-if-empty").Bool()
+			}
+			if cfg.Section("").HasKey("fail-if-empty-timeout") {
+				failIfEmptyTimeout, _ = cfg.Section("").Key("fail-if-empty-timeout").Duration()
+			}
+			if cfg.Section("").HasKey("redact-account-id") {
+				redactAccountID, _ = cfg.Section("").Key("redact-account-id").Bool()
+			}
+			if cfg.Section("").HasKey("github-repo") {
+				githubRepo = cfg.Section("").Key("github-repo").String()
+			}
+			if cfg.Section("").HasKey("github-branch") {
+				githubBranch = cfg.Section("").Key("github-branch").String()
+			}
+			if cfg.Section("").HasKey("network-policy-mode") {
+				networkPolicyMode, _ = cfg.Section("").Key("network-policy-mode").Bool()
+			}
+			if cfg.Section("").HasKey("coverage") {
+				coverage = cfg.Section("").Key("coverage").String()
+			}
+			if cfg.Section("").HasKey("min-coverage") {
+				minCoverage, _ = cfg.Section("").Key("min-coverage").Float64()
+			}
+			if cfg.Section("").HasKey("proxy-auth") {
+				proxyAuth = cfg.Section("").Key("proxy-auth").String()
+			}
+			if cfg.Section("").HasKey("proxy-auth-file") {
+				proxyAuthFile = cfg.Section("").Key("proxy-auth-file").String()
+			}
+			if cfg.Section("").HasKey("role-arn") {
+				roleARN = cfg.Section("").Key("role-arn").String()
+			}
+			if cfg.Section("").HasKey("service-account-name") {
+				serviceAccountName = cfg.Section("").Key("service-account-name").String()
+			}
+			if cfg.Section("").HasKey("service-account-namespace") {
+				serviceAccountNamespace = cfg.Section("").Key("service-account-namespace").String()
+			}
+			if cfg.Section("").HasKey("infer-arns-from-tags") {
+				inferARNsFromTags, _ = cfg.Section("").Key("infer-arns-from-tags").Bool()
+			}
+			if cfg.Section("").HasKey("aws-profile") {
+				awsProfile = cfg.Section("").Key("aws-profile").String()
+			}
+			if cfg.Section("").HasKey("audit-log") {
+				auditLog = cfg.Section("").Key("audit-log").String()
+			}
+			if cfg.Section("").HasKey("ignore-pagination") {
+				ignorePagination, _ = cfg.Section("").Key("ignore-pagination").Bool()
+			}
+			if cfg.Section("").HasKey("request-id-header") {
+				requestIDHeader = cfg.Section("").Key("request-id-header").String()
+			}
+			if cfg.Section("").HasKey("event-source") {
+				eventSource = cfg.Section("").Key("event-source").String()
+			}
+			if cfg.Section("").HasKey("max-param-depth") {
+				maxParamDepth, _ = cfg.Section("").Key("max-param-depth").Int()
+			}
+			if cfg.Section("").HasKey("export-to-vault") {
+				exportToVault = cfg.Section("").Key("export-to-vault").String()
+			}
+			if cfg.Section("").HasKey("vault-namespace") {
+				vaultNamespace = cfg.Section("").Key("vault-namespace").String()
+			}
+			if cfg.Section("").HasKey("ca-validity-from-existing") {
+				caValidityFromExisting, _ = cfg.Section("").Key("ca-validity-from-existing").Int()
+			}
+			if cfg.Section("").HasKey("track-response-size") {
+				trackResponseSize, _ = cfg.Section("").Key("track-response-size").Bool()
+			}
+			if cfg.Section("").HasKey("include-error-entries") {
+				includeErrorEntries, _ = cfg.Section("").Key("include-error-entries").Bool()
+			}
+			if cfg.Section("").HasKey("exclude-error-entries") {
+				excludeErrorEntries, _ = cfg.Section("").Key("exclude-error-entries").Bool()
+			}
+			if cfg.Section("").HasKey("include-only-status-codes") {
+				includeOnlyStatusCodes = cfg.Section("").Key("include-only-status-codes").String()
+			}
+			if cfg.Section("").HasKey("iam-role-name") {
+				iamRoleName = cfg.Section("").Key("iam-role-name").String()
+			}
+			if cfg.Section("").HasKey("ignore-duplicate-params") {
+				ignoreDuplicateParams, _ = cfg.Section("").Key("ignore-duplicate-params").Bool()
+			}
+			if cfg.Section("").HasKey("backstage-component-name") {
+				backstageComponentName = cfg.Section("").Key("backstage-component-name").String()
+			}
+			if cfg.Section("").HasKey("access-log-file") {
+				accessLogFile = cfg.Section("").Key("access-log-file").String()
+			}
+			if cfg.Section("").HasKey("check-cloudtrail") {
+				checkCloudTrail = cfg.Section("").Key("check-cloudtrail").String()
+			}
+			if cfg.Section("").HasKey("packer-image-name") {
+				packerImageName = cfg.Section("").Key("packer-image-name").String()
+			}
+			if cfg.Section("").HasKey("export-to-github-gist") {
+				exportToGitHubGist, _ = cfg.Section("").Key("export-to-github-gist").Bool()
+			}
+			if cfg.Section("").HasKey("gist-private") {
+				gistPrivate, _ = cfg.Section("").Key("gist-private").Bool()
+			}
+			if cfg.Section("").HasKey("gist-description") {
+				gistDescription = cfg.Section("").Key("gist-description").String()
+			}
+			if cfg.Section("").HasKey("tag-entries-by-time-bucket") {
+				tagEntriesByTimeBucket, _ = cfg.Section("").Key("tag-entries-by-time-bucket").Duration()
+			}
+			if cfg.Section("").HasKey("detect-privilege-escalation") {
+				detectPrivilegeEscalation, _ = cfg.Section("").Key("detect-privilege-escalation").Bool()
+			}
+			if cfg.Section("").HasKey("azure-role-name") {
+				azureRoleName = cfg.Section("").Key("azure-role-name").String()
+			}
+			if cfg.Section("").HasKey("azure-assignable-scope") {
+				azureAssignableScope = cfg.Section("").Key("azure-assignable-scope").String()
+			}
+			if cfg.Section("").HasKey("vault-aws-mount-path") {
+				vaultAWSMountPath = cfg.Section("").Key("vault-aws-mount-path").String()
+			}
+			if cfg.Section("").HasKey("no-entry-on-action-star") {
+				noEntryOnActionStar, _ = cfg.Section("").Key("no-entry-on-action-star").Bool()
+			}
+			if cfg.Section("").HasKey("action-star-log") {
+				actionStarLog = cfg.Section("").Key("action-star-log").String()
+			}
+			if cfg.Section("").HasKey("bind-interface") {
+				bindInterface = cfg.Section("").Key("bind-interface").String()
+			}
+			if cfg.Section("").HasKey("aws-single-use-credentials") {
+				awsSingleUseCredentials, _ = cfg.Section("").Key("aws-single-use-credentials").Bool()
+			}
+			if cfg.Section("").HasKey("export-to-dynamodb") {
+				exportToDynamoDB = cfg.Section("").Key("export-to-dynamodb").String()
+			}
+			if cfg.Section("").HasKey("dynamodb-region") {
+				dynamoDBRegion = cfg.Section("").Key("dynamodb-region").String()
+			}
+			if cfg.Section("").HasKey("include-failed-calls") {
+				includeFailedCalls, _ = cfg.Section("").Key("include-failed-calls").Bool()
+			}
+			if cfg.Section("").HasKey("per-request-timeout") {
+				perRequestTimeout, _ = cfg.Section("").Key("per-request-timeout").Duration()
+			}
+			if cfg.Section("").HasKey("entry-source-annotation") {
+				entrySourceAnnotation = cfg.Section("").Key("entry-source-annotation").String()
+			}
+			if cfg.Section("").HasKey("cfn-stack-name") {
+				cfnStackName = cfg.Section("").Key("cfn-stack-name").String()
+			}
+			if cfg.Section("").HasKey("log-request-headers") {
+				logRequestHeaders = cfg.Section("").Key("log-request-headers").String()
+			}
+			if cfg.Section("").HasKey("region-filter") {
+				regionFilter = cfg.Section("").Key("region-filter").String()
+			}
+			if cfg.Section("").HasKey("sso-instance-arn") {
+				ssoInstanceARN = cfg.Section("").Key("sso-instance-arn").String()
+			}
+			if cfg.Section("").HasKey("permission-set-name") {
+				permissionSetName = cfg.Section("").Key("permission-set-name").String()
+			}
+			if cfg.Section("").HasKey("session-id") {
+				sessionID = cfg.Section("").Key("session-id").String()
+			}
+			if cfg.Section("").HasKey("raw-actions-prefix") {
+				rawActionsPrefix, _ = cfg.Section("").Key("raw-actions-prefix").Bool()
+			}
+			if cfg.Section("").HasKey("upstream-proxy") {
+				upstreamProxy = cfg.Section("").Key("upstream-proxy").String()
+			}
+			if cfg.Section("").HasKey("upstream-proxy-user") {
+				upstreamProxyUser = cfg.Section("").Key("upstream-proxy-user").String()
+			}
+			if cfg.Section("").HasKey("upstream-proxy-pass") {
+				upstreamProxyPass = cfg.Section("").Key("upstream-proxy-pass").String()
+			}
+			if cfg.Section("").HasKey("alert-on-new-action") {
+				alertOnNewAction, _ = cfg.Section("").Key("alert-on-new-action").Bool()
+			}
+			if cfg.Section("").HasKey("known-actions-file") {
+				knownActionsFile = cfg.Section("").Key("known-actions-file").String()
+			}
+			if cfg.Section("").HasKey("webhook-url") {
+				webhookURL = cfg.Section("").Key("webhook-url").String()
+			}
+			if cfg.Section("").HasKey("fail-on-new-action") {
+				failOnNewAction, _ = cfg.Section("").Key("fail-on-new-action").Bool()
+			}
+			if cfg.Section("").HasKey("persist-log") {
+				persistLog = cfg.Section("").Key("persist-log").String()
+			}
+			if cfg.Section("").HasKey("replay-session-id") {
+				replaySessionID = cfg.Section("").Key("replay-session-id").String()
+			}
+			if cfg.Section("").HasKey("replay-exclude-session-id") {
+				replayExcludeSessionID = cfg.Section("").Key("replay-exclude-session-id").String()
+			}
+			if cfg.Section("").HasKey("ignore-read-only-region") {
+				ignoreReadOnlyRegion = cfg.Section("").Key("ignore-read-only-region").String()
+			}
+			if cfg.Section("").HasKey("cw-rule-name") {
+				cwRuleName = cfg.Section("").Key("cw-rule-name").String()
+			}
+			if cfg.Section("").HasKey("cw-log-group-name") {
+				cwLogGroupName = cfg.Section("").Key("cw-log-group-name").String()
+			}
+			if cfg.Section("").HasKey("cloudtrail-time-range") {
+				cloudTrailTimeRange = cfg.Section("").Key("cloudtrail-time-range").String()
+			}
+			if cfg.Section("").HasKey("cloudtrail-principal") {
+				cloudTrailPrincipal = cfg.Section("").Key("cloudtrail-principal").String()
+			}
+			if cfg.Section("").HasKey("include-actions") {
+				includeActions = cfg.Section("").Key("include-actions").String()
+			}
+			if cfg.Section("").HasKey("exclude-actions") {
+				excludeActions = cfg.Section("").Key("exclude-actions").String()
+			}
+			if cfg.Section("").HasKey("include-services") {
+				includeServices = cfg.Section("").Key("include-services").String()
+			}
+			if cfg.Section("").HasKey("exclude-services") {
+				excludeServices = cfg.Section("").Key("exclude-services").String()
+			}
+			if cfg.Section("").HasKey("list-services") {
+				listServices, _ = cfg.Section("").Key("list-services").Bool()
+			}
+			if cfg.Section("").HasKey("ca-key-type") {
+				caKeyType = cfg.Section("").Key("ca-key-type").String()
+			}
+			if cfg.Section("").HasKey("rotate-ca") {
+				rotateCA, _ = cfg.Section("").Key("rotate-ca").Bool()
+			}
+			if cfg.Section("").HasKey("http-port") {
+				httpPort, _ = cfg.Section("").Key("http-port").Int()
+			}
+			if cfg.Section("").HasKey("http-host") {
+				httpHost = cfg.Section("").Key("http-host").String()
+			}
+			if cfg.Section("").HasKey("ui") {
+				ui, _ = cfg.Section("").Key("ui").Bool()
+			}
+			if cfg.Section("").HasKey("timeout") {
+				timeout, _ = cfg.Section("").Key("timeout").Duration()
+			}
+			if cfg.Section("").HasKey("max-unique-pairs") {
+				maxUniquePairs, _ = cfg.Section("").Key("max-unique-pairs").Int()
+			}
+			if cfg.Section("").HasKey("diff-format") {
+				diffFormat = cfg.Section("").Key("diff-format").String()
+			}
+			if cfg.Section("").HasKey("max-policy-size") {
+				maxPolicySize, _ = cfg.Section("").Key("max-policy-size").Int()
+			}
+			if cfg.Section("").HasKey("suggest-wildcards") {
+				suggestWildcards, _ = cfg.Section("").Key("suggest-wildcards").Bool()
+			}
+			if cfg.Section("").HasKey("wildcard-threshold") {
+				wildcardThreshold, _ = cfg.Section("").Key("wildcard-threshold").Int()
+			}
+			if cfg.Section("").HasKey("wildcard-extra-pct") {
+				wildcardExtraPct, _ = cfg.Section("").Key("wildcard-extra-pct").Float64()
+			}
+			if cfg.Section("").HasKey("localstack") {
+				localStack, _ = cfg.Section("").Key("localstack").Bool()
+			}
+			if cfg.Section("").HasKey("localstack-port") {
+				localStackPort, _ = cfg.Section("").Key("localstack-port").Int()
+			}
+			if cfg.Section("").HasKey("default-region") {
+				defaultRegion = cfg.Section("").Key("default-region").String()
+			}
+			if cfg.Section("").HasKey("service-dir") {
+				serviceDir = cfg.Section("").Key("service-dir").String()
+			}
+			if cfg.Section("").HasKey("metrics-port") {
+				metricsPort, _ = cfg.Section("").Key("metrics-port").Int()
+			}
+			if cfg.Section("").HasKey("metrics-host") {
+				metricsHost = cfg.Section("").Key("metrics-host").String()
+			}
+			if cfg.Section("").HasKey("chain-roles") {
+				chainRoles, _ = cfg.Section("").Key("chain-roles").Bool()
+			}
+			if cfg.Section("").HasKey("principal-map") {
+				principalMap = cfg.Section("").Key("principal-map").String()
+			}
+			if cfg.Section("").HasKey("split-by-principal") {
+				splitByPrincipal, _ = cfg.Section("").Key("split-by-principal").Bool()
+			}
+			if cfg.Section("").HasKey("output-formats") {
+				outputFormats = cfg.Section("").Key("output-formats").String()
+			}
+			if cfg.Section("").HasKey("output-dir") {
+				outputDir = cfg.Section("").Key("output-dir").String()
+			}
+			if cfg.Section("").HasKey("html-title") {
+				htmlTitle = cfg.Section("").Key("html-title").String()
+			}
 		}
 	}
 
 	setiniFlag = flag.Bool("set-ini", setIni, "when set, the .aws/config file will be updated to use the CSM monitoring or CA bundle and removed when exiting")
 	profileFlag = flag.String("profile", profile, "use the specified profile when combined with --set-ini")
-	failsonlyFlag = flag.Bool("fails-only", failsOnly, "when set, only failed AWS calls will be added to the policy, csm mode only")
+	failsonlyFlag = flag.Bool("fails-only", failsOnly, "when set, only failed AWS calls will be added to the policy")
 	outputFileFlag = flag.String("output-file", outputFile, "specify a file that will be written to on SIGHUP or exit")
 	refreshRateFlag = flag.Int("refresh-rate", refreshRate, "instead of flushing to console every API call, do it this number of seconds")
 	sortAlphabeticalFlag = flag.Bool("sort-alphabetical", sortAlphabetical, "sort actions alphabetically")
@@ -95,13 +665,166 @@ func parseConfig() {
 	bindAddrFlag = flag.String("bind-addr", bindAddr, "[experimental] the bind address for proxy mode")
 	caBundleFlag = flag.String("ca-bundle", caBundle, "[experimental] the CA certificate bundle (PEM) to use for proxy mode")
 	caKeyFlag = flag.String("ca-key", caKey, "[experimental] the CA certificate key to use for proxy mode")
-	accountIDFlag = flag.String("account-id", accountID, "[experimental] the AWS account ID to use in policy outputs within proxy mode")
+	accountIDFlag = flag.String("account-id", accountID, "[experimental] the AWS account ID to use in policy outputs within proxy mode, resource ARNs use * while this is the placeholder")
+	jsonPathMappingFlag = flag.String("json-path-mapping", jsonPathMapping, "[experimental] a JSON file of rules mapping a host pattern and JSON body path to an action for custom services, proxy mode only")
+	outputFormatFlag = flag.String("output-format", outputFormat, "the output format of the policy (json,kubeseal,env,aws-iam-policy-simulator-input,github-oidc,spacelift,kustomize-patch,gcp-iam,aws-config-rule,terraform-import,github-copilot,backstage,packer,aws-policy-generator,azure-rbac,vault-policy,semgrep,github-secret-scanning,terraform-hcl,cloudformation-yaml,scout-suite,cdk-python,aws-sso-permission-set-cli,scp,raw-actions,open-api,aws-cloudwatch-contributor-insights,html)")
+	flag.StringVar(outputFormatFlag, "output-type", outputFormat, "an alias of --output-format")
+	kubesealNamespaceFlag = flag.String("kubeseal-namespace", kubesealNamespace, "the namespace of the secret when using the kubeseal output format")
+	kubesealSecretNameFlag = flag.String("kubeseal-secret-name", kubesealSecretName, "the name of the secret when using the kubeseal output format")
+	kubesealCertFileFlag = flag.String("kubeseal-cert-file", kubesealCertFile, "the sealed secrets controller certificate used to seal the policy when using the kubeseal output format, otherwise an unsealed secret is output")
+	entryTTLFlag = flag.Duration("entry-ttl", entryTTL, "when set, captured calls older than this duration (e.g. 1h) are purged every minute and any output file is rewritten")
+	kubernetesLabelFilterFlag = flag.String("kubernetes-label-filter", kubernetesLabelFilter, "when running as a Kubernetes sidecar, only record calls when the pod carries this label (key=value) and add the pod labels as condition keys")
+	simulateRegionFailoverFlag = flag.String("simulate-region-failover", simulateRegionFailover, "duplicate calls from one region into another (src=us-east-1,dst=us-west-2) and highlight region-specific resources")
+	envVarNameFlag = flag.String("env-var-name", envVarName, "the name of the exported variable when using the env output format")
+	checkRequiredActionsFlag = flag.String("check-required-actions", checkRequiredActions, "a file of actions (one service:action per line) that must be captured, otherwise the missing actions are printed and the exit code is 3")
+	failIfEmptyFlag = flag.Bool("fail-if-empty", failIfEmpty, "when set, exit with code 1 if no calls were captured during the session")
+	failIfEmptyTimeoutFlag = flag.Duration("fail-if-empty-timeout", failIfEmptyTimeout, "when combined with --fail-if-empty, exit if no calls were captured within this duration (e.g. 30s)")
+	redactAccountIDFlag = flag.Bool("redact-account-id", redactAccountID, "when set, account IDs within ARNs are replaced with XXXXXXXXXXXX in all outputs")
+	githubRepoFlag = flag.String("github-repo", githubRepo, "the GitHub repository (owner/repo) allowed to assume the role when using the github-oidc output format")
+	githubBranchFlag = flag.String("github-branch", githubBranch, "the branch allowed to assume the role when using the github-oidc output format, otherwise any ref is allowed")
+	networkPolicyModeFlag = flag.Bool("network-policy-mode", networkPolicyMode, "when set, also generate a Kubernetes NetworkPolicy allowing egress to the AWS IP ranges of the observed services")
+	coverageFlag = flag.String("coverage", coverage, "a service (e.g. ecs) to report the percentage of operations exercised for when exiting")
+	minCoverageFlag = flag.Float64("min-coverage", minCoverage, "when combined with --coverage, exit with code 4 if the percentage of operations exercised is below this")
+	proxyAuthFlag = flag.String("proxy-auth", proxyAuth, "[experimental] require proxy authentication with these credentials (user:password) in proxy mode")
+	proxyAuthFileFlag = flag.String("proxy-auth-file", proxyAuthFile, "[experimental] require proxy authentication with the users in this htpasswd file (bcrypt or {SHA} entries) in proxy mode")
+	roleARNFlag = flag.String("role-arn", roleARN, "the IAM role ARN to annotate the service account with when using the kustomize-patch output format, and to assume with --aws-single-use-credentials")
+	serviceAccountNameFlag = flag.String("service-account-name", serviceAccountName, "the name of the service account when using the kustomize-patch output format")
+	serviceAccountNamespaceFlag = flag.String("service-account-namespace", serviceAccountNamespace, "the namespace of the service account when using the kustomize-patch output format")
+	inferARNsFromTagsFlag = flag.Bool("infer-arns-from-tags", inferARNsFromTags, "[experimental] use the ARNs seen in tagging call responses to resolve resources more precisely, proxy mode only")
+	awsProfileFlag = flag.String("aws-profile", awsProfile, "the AWS profile to read the region and credentials from when iamlive calls AWS APIs")
+	auditLogFlag = flag.String("audit-log", auditLog, "[experimental] append a JSON line for every request seen by the proxy, including non-AWS requests, to this file")
+	ignorePaginationFlag = flag.Bool("ignore-pagination", ignorePagination, "when set, calls for subsequent pages of a paginated call are not recorded, proxy mode only")
+	requestIDHeaderFlag = flag.String("request-id-header", requestIDHeader, "a request header (e.g. X-Internal-Request-ID) to store as the correlation ID of each call, proxy mode only")
+	eventSourceFlag = flag.String("event-source", eventSource, "a label (e.g. deploy) for the pipeline phase or other source of the calls captured in this session")
+	maxParamDepthFlag = flag.Int("max-param-depth", maxParamDepth, "the maximum depth of nested JSON request bodies to read parameters from, proxy mode only")
+	exportToVaultFlag = flag.String("export-to-vault", exportToVault, "a Vault KV path (e.g. secret/iamlive/policy) to write the policy to on exit, using VAULT_ADDR and VAULT_TOKEN")
+	vaultNamespaceFlag = flag.String("vault-namespace", vaultNamespace, "the Vault Enterprise namespace to use with --export-to-vault")
+	caValidityFromExistingFlag = flag.Int("ca-validity-from-existing", caValidityFromExisting, "[experimental] re-sign the existing CA certificate with its existing key so it is valid for this many days from now")
+	trackResponseSizeFlag = flag.Bool("track-response-size", trackResponseSize, "[experimental] when set, record the size of each response body with its call, proxy mode only")
+	includeErrorEntriesFlag = flag.Bool("include-error-entries", includeErrorEntries, "when set, failed calls (4xx/5xx) are recorded in the call log, which is the default, though in proxy mode they are only added to the policy with --include-failed-calls (--include-error-entries=false is the same as --exclude-error-entries)")
+	excludeErrorEntriesFlag = flag.Bool("exclude-error-entries", excludeErrorEntries, "when set, failed calls (4xx/5xx) are not recorded, so they are left out of both the call log and the policy")
+	includeOnlyStatusCodesFlag = flag.String("include-only-status-codes", includeOnlyStatusCodes, "a comma-separated list of HTTP status codes (e.g. 200,201) to record calls for, other calls are not recorded")
+	iamRoleNameFlag = flag.String("iam-role-name", iamRoleName, "the name of the IAM role the policy is for, used for IAM calls that refer to the role and as the statement Sid prefix")
+	ignoreDuplicateParamsFlag = flag.Bool("ignore-duplicate-params", ignoreDuplicateParams, "when set, repeated identical Action and Version parameters in query protocol requests are collapsed to a single value")
+	backstageComponentNameFlag = flag.String("backstage-component-name", backstageComponentName, "the component name used in the backstage output format")
+	accessLogFileFlag = flag.String("access-log-file", accessLogFile, "the path to an access log file that every proxied request is appended to in Combined Log Format")
+	checkCloudTrailFlag = flag.String("check-cloudtrail", checkCloudTrail, "the path to a CloudTrail events JSON export to cross-reference with the captured calls at exit")
+	packerImageNameFlag = flag.String("packer-image-name", packerImageName, "the AMI name used in the amazon-ebs source block of the packer output format")
+	exportToGitHubGistFlag = flag.Bool("export-to-github-gist", exportToGitHubGist, "when set, the policy is uploaded to a GitHub Gist at exit using GITHUB_TOKEN")
+	gistPrivateFlag = flag.Bool("gist-private", gistPrivate, "the Gist created by --export-to-github-gist is secret, set to false to create a public Gist")
+	gistDescriptionFlag = flag.String("gist-description", gistDescription, "the description of the Gist created by --export-to-github-gist")
+	tagEntriesByTimeBucketFlag = flag.Duration("tag-entries-by-time-bucket", tagEntriesByTimeBucket, "when set, calls are grouped into buckets of this duration from the start of the session and a timeline of the actions first seen in each bucket is shown")
+	detectPrivilegeEscalationFlag = flag.Bool("detect-privilege-escalation", detectPrivilegeEscalation, "when set, a warning is shown for each known privilege escalation path whose actions have all been observed")
+	azureRoleNameFlag = flag.String("azure-role-name", azureRoleName, "the name of the custom role in the azure-rbac output format")
+	azureAssignableScopeFlag = flag.String("azure-assignable-scope", azureAssignableScope, "the assignable scope of the custom role in the azure-rbac output format")
+	vaultAWSMountPathFlag = flag.String("vault-aws-mount-path", vaultAWSMountPath, "the mount path of the Vault AWS secrets engine in the vault-policy output format")
+	noEntryOnActionStarFlag = flag.Bool("no-entry-on-action-star", noEntryOnActionStar, "when set, proxied calls whose action could not be resolved are dropped with a warning instead of being recorded as a * action")
+	actionStarLogFlag = flag.String("action-star-log", actionStarLog, "the path to a file that proxied requests whose action could not be resolved are appended to")
+	bindInterfaceFlag = flag.String("bind-interface", bindInterface, "[experimental] the network interface to bind to in proxy mode, using its current IP address with the port of --bind-addr")
+	awsSingleUseCredentialsFlag = flag.Bool("aws-single-use-credentials", awsSingleUseCredentials, "[experimental] when set, each proxied AWS request is re-signed with fresh credentials for --role-arn scoped by a session policy of the calls captured so far")
+	exportToDynamoDBFlag = flag.String("export-to-dynamodb", exportToDynamoDB, "the name of a DynamoDB table that each captured call is written to as an item")
+	dynamoDBRegionFlag = flag.String("dynamodb-region", dynamoDBRegion, "the region of the --export-to-dynamodb table, defaulting to the region of the AWS profile or environment")
+	includeFailedCallsFlag = flag.Bool("include-failed-calls", includeFailedCalls, "when set, the failed AWS calls (4xx/5xx) recorded in the call log are also added to the policy, proxy mode only (cannot be used with --exclude-error-entries)")
+	perRequestTimeoutFlag = flag.Duration("per-request-timeout", perRequestTimeout, "the maximum time to spend reading a request body in proxy mode, after which the request is passed through without being recorded (e.g. 5s), 0 to disable")
+	entrySourceAnnotationFlag = flag.String("entry-source-annotation", entrySourceAnnotation, "a label (e.g. integration-test-run-42) for this capture session, stored with each call so that merged or exported calls can be traced back to it")
+	cfnStackNameFlag = flag.String("cfn-stack-name", cfnStackName, "the prefix of the logical IDs of the managed policies in the cloudformation-yaml output format")
+	logRequestHeadersFlag = flag.String("log-request-headers", logRequestHeaders, "a comma-separated list of request headers (e.g. X-Amz-Target,Authorization) to store with each call, proxy mode only")
+	regionFilterFlag = flag.String("region-filter", regionFilter, "a comma-separated list of regions (e.g. us-east-1,eu-west-1) to restrict the policy to, calls in other regions are left out")
+	ssoInstanceARNFlag = flag.String("sso-instance-arn", ssoInstanceARN, "the ARN of the IAM Identity Center instance for the aws-sso-permission-set-cli output format")
+	permissionSetNameFlag = flag.String("permission-set-name", permissionSetName, "the name of the permission set created by the aws-sso-permission-set-cli output format")
+	sessionIDFlag = flag.String("session-id", sessionID, "the ID of this capture session (e.g. deployment-2024-01-15-v2.3.1), stored with each call in the audit log and DynamoDB export so that sessions sharing a destination can be told apart")
+	rawActionsPrefixFlag = flag.Bool("raw-actions-prefix", rawActionsPrefix, "include the service prefix (e.g. s3:) of each action in the raw-actions output format")
+	upstreamProxyFlag = flag.String("upstream-proxy", upstreamProxy, "the corporate HTTP proxy (e.g. http://corp-proxy:8080) to send proxied requests through, proxy mode only, defaults to HTTPS_PROXY or HTTP_PROXY with hosts in NO_PROXY connected to directly")
+	upstreamProxyUserFlag = flag.String("upstream-proxy-user", upstreamProxyUser, "the username for basic authentication with --upstream-proxy")
+	upstreamProxyPassFlag = flag.String("upstream-proxy-pass", upstreamProxyPass, "the password for basic authentication with --upstream-proxy")
+	configFlag = flag.String("config", "", "the YAML config file to load, defaults to .iamlive.yaml in the current directory then ~/.iamlive.yaml")
+	printConfigFlag = flag.Bool("print-config", false, "print the effective configuration as YAML, with the source of each setting, and exit")
+	alertOnNewActionFlag = flag.Bool("alert-on-new-action", alertOnNewAction, "alert when an action is seen that is not in --known-actions-file, logging it and calling --webhook-url")
+	knownActionsFileFlag = flag.String("known-actions-file", knownActionsFile, "the file of actions seen in previous sessions, one per line, that new actions are added to")
+	webhookURLFlag = flag.String("webhook-url", webhookURL, "the URL to POST a JSON event to when --alert-on-new-action sees a new action")
+	failOnNewActionFlag = flag.Bool("fail-on-new-action", failOnNewAction, "exit with code 1 at the end of the session if any action was not in --known-actions-file")
+	persistLogFlag = flag.String("persist-log", persistLog, "store each captured call in this SQLite database, and restore the calls stored by previous sessions on start, so that a capture session can continue across restarts")
+	replaySessionIDFlag = flag.String("replay-session-id", replaySessionID, "only replay the calls of the capture session with this --session-id")
+	replayExcludeSessionIDFlag = flag.String("replay-exclude-session-id", replayExcludeSessionID, "replay the calls of all capture sessions except the one with this --session-id")
+	ignoreReadOnlyRegionFlag = flag.String("ignore-read-only-region", ignoreReadOnlyRegion, "a comma-separated list of regions (e.g. us-east-1) whose read-only calls (Describe, List and Get methods) are not recorded, write calls in the regions are still recorded")
+	cwRuleNameFlag = flag.String("cw-rule-name", cwRuleName, "the name of the rule generated by the aws-cloudwatch-contributor-insights output format")
+	cwLogGroupNameFlag = flag.String("cw-log-group-name", cwLogGroupName, "the log group receiving CloudTrail events analyzed by the aws-cloudwatch-contributor-insights output format")
+	cloudTrailTimeRangeFlag = flag.String("cloudtrail-time-range", cloudTrailTimeRange, "only import the CloudTrail events between these RFC 3339 times, as <from>,<to> where either may be left empty")
+	cloudTrailPrincipalFlag = flag.String("cloudtrail-principal", cloudTrailPrincipal, "only import the CloudTrail events made by this IAM user or role ARN")
+	includeActionsFlag = flag.String("include-actions", includeActions, "only record calls with an IAM action matching one of these comma-separated glob patterns (e.g. s3:List*,sts:GetCallerIdentity)")
+	excludeActionsFlag = flag.String("exclude-actions", excludeActions, "do not record calls whose IAM actions all match one of these comma-separated glob patterns (e.g. s3:List*,sts:GetCallerIdentity)")
+	includeServicesFlag = flag.String("include-services", includeServices, "only record calls to these comma-separated service IDs (e.g. S3,EC2), see --list-services")
+	excludeServicesFlag = flag.String("exclude-services", excludeServices, "do not record calls to these comma-separated service IDs (e.g. STS), see --list-services")
+	listServicesFlag = flag.Bool("list-services", listServices, "print the service IDs of the known AWS services and exit")
+	caKeyTypeFlag = flag.String("ca-key-type", caKeyType, "the type of key generated for a new CA, rsa (RSA-4096) or ecdsa (ECDSA P-256, which is much faster to generate)")
+	rotateCAFlag = flag.Bool("rotate-ca", rotateCA, "back up the existing CA files as <name>.bak.<timestamp> and generate a new CA with --ca-key-type")
+	httpPortFlag = flag.Int("http-port", httpPort, "serve the captured calls as Server-Sent Events at /events, the policy at /policy and a call log reset at /reset and the example config file at /config.example.yaml on this port")
+	httpHostFlag = flag.String("http-host", httpHost, "the address the --http-port server listens on")
+	uiFlag = flag.Bool("ui", ui, "show a terminal UI with a live table of the captured calls instead of the policy")
+	timeoutFlag = flag.Duration("timeout", timeout, "stop and write the output once no AWS calls have been made for this duration (e.g. 30s), 0 to disable")
+	maxUniquePairsFlag = flag.Int("max-unique-pairs", maxUniquePairs, "stop and write the output once this many unique service and method pairs have been captured, 0 to disable")
+	diffFormatFlag = flag.String("diff-format", diffFormat, "the format of iamlive diff, text or json")
+	maxPolicySizeFlag = flag.Int("max-policy-size", maxPolicySize, "the maximum number of characters in a policy document written to --output-file, beyond which it is split into several numbered files (e.g. 10240 for inline policies)")
+	suggestWildcardsFlag = flag.Bool("suggest-wildcards", suggestWildcards, "note where more than --wildcard-threshold captured actions of a service share a verb, such as ec2:Describe*, and a wildcard would allow few other actions, printed to stderr at exit")
+	wildcardThresholdFlag = flag.Int("wildcard-threshold", wildcardThreshold, "the number of captured actions sharing a verb beyond which --suggest-wildcards suggests a wildcard")
+	wildcardExtraPctFlag = flag.Float64("wildcard-extra-pct", wildcardExtraPct, "the percentage of a service's actions, beyond those captured, that a wildcard suggested by --suggest-wildcards may allow, counting the IAM actions of the service in the Service Authorization Reference rather than its API operations")
+	localStackFlag = flag.Bool("localstack", localStack, "intercept calls to LocalStack at localhost.localstack.cloud, and localhost on --localstack-port")
+	localStackPortFlag = flag.Int("localstack-port", localStackPort, "the port of LocalStack on localhost, when --localstack is set")
+	defaultRegionFlag = flag.String("default-region", defaultRegion, "the region of calls whose endpoint does not name one, such as LocalStack calls")
+	serviceDirFlag = flag.String("service-dir", serviceDir, "a directory of service definition JSON files to use instead of those built in, reloaded as the files change")
+	metricsPortFlag = flag.Int("metrics-port", metricsPort, "serve Prometheus metrics of the captured calls at /metrics on this port")
+	metricsHostFlag = flag.String("metrics-host", metricsHost, "the address the --metrics-port server listens on")
+	chainRolesFlag = flag.Bool("chain-roles", chainRoles, "attribute calls made with the credentials of a role session captured by sts:AssumeRole to the assumed role")
+	principalMapFlag = flag.String("principal-map", principalMap, "a JSON file mapping access key IDs to the ARNs of their principals, to attribute the captured calls to principals")
+	splitByPrincipalFlag = flag.Bool("split-by-principal", splitByPrincipal, "also write a policy for each principal of --principal-map that made calls, beside --output-file")
+	outputFormatsFlag = flag.String("output-formats", outputFormats, "a comma-separated list of output formats to write in one pass, each to policy.<ext> in --output-dir")
+	outputDirFlag = flag.String("output-dir", outputDir, "the directory the --output-formats files are written to, required when more than one format is listed")
+	htmlTitleFlag = flag.String("html-title", htmlTitle, "the heading of the report written by the html output format")
 }
 
 func main() {
 	parseConfig()
 
 	flag.Parse()
+	command, commandArgs := parseCommand()
+
+	flag.Visit(func(f *flag.Flag) {
+		configSources[f.Name] = configSourceFlag
+	})
+	err := bindEnvToFlags(flag.CommandLine)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadYAMLConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printConfigFlag {
+		err = printConfig(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *listServicesFlag {
+		readServiceFiles()
+		listServices()
+		return
+	}
+	if command != "" && command != "diff" && command != "import-cloudtrail" && command != "replay-log" {
+		log.Fatalf("unknown command %q, expected diff, import-cloudtrail or replay-log", command)
+	}
+	if command == "diff" {
+		os.Exit(runPolicyDiff(commandArgs))
+	}
+	if command == "import-cloudtrail" && len(commandArgs) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: iamlive import-cloudtrail [flags] <path-or-s3-uri>")
+		os.Exit(2)
+	}
+	if command == "replay-log" && len(commandArgs) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: iamlive replay-log [flags] <path>")
+		os.Exit(2)
+	}
 
 	if *cpuProfileFlag != "" {
 		f, err := os.Create(*cpuProfileFlag)
@@ -112,18 +835,173 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	err = validateOutputFormat()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadOutputFormats()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = validateMaxPolicySize()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadPrincipalMap()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadKubesealCert()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadPodLabels()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = parseRegionFailover()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadRequiredActions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadKnownActions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadIPRanges()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadCoverageService()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadProxyAuth()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadUpstreamProxy()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadAWSProfile()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = openAuditLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = openAccessLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = openActionStarLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = openPersistentLog()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadStatusCodeFilter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = validateFailedCallFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadRegionFilter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadIgnoreReadOnlyRegions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadActionFilters()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadCloudTrailEvents()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = loadPrivilegeEscalationPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = validateSingleUseCredentials()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = startDynamoDBExport()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if command == "import-cloudtrail" {
+		loadMaps()
+		readServiceFiles()
+		err = importCloudTrail(commandArgs[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if command == "replay-log" {
+		loadMaps()
+		readServiceFiles()
+		err = replayPersistentLog(commandArgs[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if *refreshRateFlag != 0 {
 		setTerminalRefresh()
 	}
+	if *entryTTLFlag > 0 {
+		setEntryTTLPurge()
+	}
+	if *failIfEmptyFlag && *failIfEmptyTimeoutFlag > 0 {
+		setFailIfEmptyTimeout()
+	}
+	startAutoStop()
 
 	setINIConfigAndFileFlush()
 	loadMaps()
+	err = startHTTPServer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = startMetricsServer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *uiFlag {
+		startUI()
+	}
 
 	if *modeFlag == "csm" {
 		listenForEvents()
 		handleLoggedCall()
 	} else if *modeFlag == "proxy" {
 		readServiceFiles()
+		err = watchServiceDir()
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = loadJSONPathMappings()
+		if err != nil {
+			log.Fatal(err)
+		}
 		createProxy(*bindAddrFlag)
 	} else {
 		fmt.Println("ERROR: unknown mode")
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// TestMain defines the flags with their defaults and loads the embedded maps and service definitions, as main does
+// before capturing calls
+func TestMain(m *testing.M) {
+	parseConfig()
+	flag.Parse()
+	*refreshRateFlag = 1 // the policy is not redrawn on the terminal after each call, as no refresh timer is started
+	loadMaps()
+	readServiceFiles()
+
+	os.Exit(m.Run())
+}
+
+// setTestFlag sets a flag for the duration of a test
+func setTestFlag(t *testing.T, name string, value string) {
+	t.Helper()
+
+	f := flag.Lookup(name)
+	if f == nil {
+		t.Fatalf("unknown flag --%s", name)
+	}
+	previous := f.Value.String()
+	if err := f.Value.Set(value); err != nil {
+		t.Fatalf("could not set --%s to %q: %v", name, value, err)
+	}
+	t.Cleanup(func() {
+		f.Value.Set(previous)
+	})
+}
+
+// resetTestCallLog empties the call log before and after a test
+func resetTestCallLog(t *testing.T) {
+	callLog.Reset()
+	t.Cleanup(callLog.Reset)
+}
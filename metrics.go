@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusCollector exports metrics of the captured calls, read from a snapshot of the call log on each scrape.
+// The counts follow the call log, so they drop when it is reset or entries expire, which Prometheus treats as a
+// counter reset.
+type PrometheusCollector struct {
+	callsDesc           *prometheus.Desc
+	uniqueActionsDesc   *prometheus.Desc
+	requestDurationDesc *prometheus.Desc
+	responseBytesDesc   *prometheus.Desc
+}
+
+func newPrometheusCollector() *PrometheusCollector {
+	return &PrometheusCollector{
+		callsDesc: prometheus.NewDesc(
+			"iamlive_calls_total",
+			"The number of captured calls",
+			[]string{"service", "action", "region", "status_code"}, nil,
+		),
+		uniqueActionsDesc: prometheus.NewDesc(
+			"iamlive_unique_action_pairs_total",
+			"The number of unique service and action pairs captured",
+			nil, nil,
+		),
+		requestDurationDesc: prometheus.NewDesc(
+			"iamlive_proxy_request_duration_seconds",
+			"The time from a call being received by the proxy to its response being received from AWS",
+			[]string{"service"}, nil,
+		),
+		responseBytesDesc: prometheus.NewDesc(
+			"iamlive_response_bytes_total",
+			"The size of the response bodies of the captured calls in bytes",
+			[]string{"service", "action"}, nil,
+		),
+	}
+}
+
+// Describe sends the descriptions of the metrics
+func (c *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.callsDesc
+	ch <- c.uniqueActionsDesc
+	ch <- c.requestDurationDesc
+	ch <- c.responseBytesDesc
+}
+
+type callMetricLabels struct {
+	service    string
+	action     string
+	region     string
+	statusCode string
+}
+
+type actionMetricLabels struct {
+	service string
+	action  string
+}
+
+type requestDurations struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+// Collect sends the metrics of the calls currently in the call log
+func (c *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	calls := make(map[callMetricLabels]int)
+	uniqueActions := make(map[string]bool)
+	durations := make(map[string]*requestDurations)
+	responseBytes := make(map[actionMetricLabels]int64)
+
+	for _, entry := range callLog.Snapshot() {
+		calls[callMetricLabels{
+			service:    entry.Service,
+			action:     entry.Method,
+			region:     entry.Region,
+			statusCode: strconv.Itoa(entry.FinalHTTPStatusCode),
+		}]++
+		uniqueActions[entry.Service+"."+entry.Method] = true
+		responseBytes[actionMetricLabels{service: entry.Service, action: entry.Method}] += entry.ResponseBodyBytes
+
+		if entry.Type != "ProxyCall" || entry.Duration <= 0 {
+			continue
+		}
+		serviceDurations, ok := durations[entry.Service]
+		if !ok {
+			serviceDurations = &requestDurations{buckets: make(map[float64]uint64)}
+			durations[entry.Service] = serviceDurations
+		}
+		seconds := entry.Duration.Seconds()
+		serviceDurations.count++
+		serviceDurations.sum += seconds
+		for _, bucket := range prometheus.DefBuckets {
+			if seconds <= bucket {
+				serviceDurations.buckets[bucket]++ // buckets are cumulative
+			}
+		}
+	}
+
+	for labels, count := range calls {
+		ch <- prometheus.MustNewConstMetric(c.callsDesc, prometheus.CounterValue, float64(count), labels.service, labels.action, labels.region, labels.statusCode)
+	}
+	ch <- prometheus.MustNewConstMetric(c.uniqueActionsDesc, prometheus.GaugeValue, float64(len(uniqueActions)))
+	for service, serviceDurations := range durations {
+		ch <- prometheus.MustNewConstHistogram(c.requestDurationDesc, serviceDurations.count, serviceDurations.sum, serviceDurations.buckets, service)
+	}
+	for labels, bytes := range responseBytes {
+		ch <- prometheus.MustNewConstMetric(c.responseBytesDesc, prometheus.CounterValue, float64(bytes), labels.service, labels.action)
+	}
+}
+
+// startMetricsServer serves the Prometheus /metrics endpoint on --metrics-host and --metrics-port
+func startMetricsServer() error {
+	if *metricsPortFlag == 0 {
+		return nil
+	}
+
+	registry := prometheus.NewRegistry()
+	err := registry.Register(newPrometheusCollector())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(*metricsHostFlag, strconv.Itoa(*metricsPortFlag)))
+	if err != nil {
+		return fmt.Errorf("could not start the --metrics-port server: %v", err)
+	}
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Printf("WARNING: the --metrics-port server stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// startTestMetricsServer starts the --metrics-port server on a free port, returning the URL of /metrics
+func startTestMetricsServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	setTestFlag(t, "metrics-host", "127.0.0.1")
+	setTestFlag(t, "metrics-port", fmt.Sprint(port))
+	if err := startMetricsServer(); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d/metrics", port)
+}
+
+// scrapeTestMetrics scrapes /metrics, returning the metric families by name
+func scrapeTestMetrics(t *testing.T, metricsURL string) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	resp, err := http.Get(metricsURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return families
+}
+
+// getTestMetric returns the metric of a family with the labels given as name=value pairs, or nil if there is none
+func getTestMetric(families map[string]*dto.MetricFamily, name string, labels ...string) *dto.Metric {
+	family, ok := families[name]
+	if !ok {
+		return nil
+	}
+	for _, metric := range family.Metric {
+		var metricLabels []string
+		for _, label := range metric.Label {
+			metricLabels = append(metricLabels, label.GetName()+"="+label.GetValue())
+		}
+		if strings.Join(metricLabels, ",") == strings.Join(labels, ",") {
+			return metric
+		}
+	}
+	return nil
+}
+
+func appendTestMetricsCall(callType string, service string, method string, region string, statusCode int, duration time.Duration, responseBodyBytes int64) {
+	callLog.Append(Entry{
+		Region:              region,
+		Type:                callType,
+		Service:             service,
+		Method:              method,
+		FinalHTTPStatusCode: statusCode,
+		Duration:            duration,
+		ResponseBodyBytes:   responseBodyBytes,
+		Timestamp:           time.Now(),
+	})
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	resetTestCallLog(t)
+	metricsURL := startTestMetricsServer(t)
+
+	appendTestMetricsCall("ProxyCall", "S3", "ListBuckets", "us-east-1", 200, 30*time.Millisecond, 512)
+	appendTestMetricsCall("ProxyCall", "S3", "ListBuckets", "us-east-1", 200, 40*time.Millisecond, 256)
+	appendTestMetricsCall("ProxyCall", "S3", "GetObject", "us-east-1", 403, 2*time.Second, 128)
+	appendTestMetricsCall("ProxyCall", "EC2", "DescribeInstances", "eu-west-1", 200, 150*time.Millisecond, 4096)
+	appendTestMetricsCall("ApiCall", "DynamoDB", "ListTables", "us-east-1", 200, 0, 0) // CSM calls have no proxy duration
+
+	families := scrapeTestMetrics(t, metricsURL)
+
+	calls := []struct {
+		labels []string
+		want   float64
+	}{
+		{labels: []string{"action=ListBuckets", "region=us-east-1", "service=S3", "status_code=200"}, want: 2},
+		{labels: []string{"action=GetObject", "region=us-east-1", "service=S3", "status_code=403"}, want: 1},
+		{labels: []string{"action=DescribeInstances", "region=eu-west-1", "service=EC2", "status_code=200"}, want: 1},
+		{labels: []string{"action=ListTables", "region=us-east-1", "service=DynamoDB", "status_code=200"}, want: 1},
+	}
+	for _, tt := range calls {
+		metric := getTestMetric(families, "iamlive_calls_total", tt.labels...)
+		if metric == nil {
+			t.Errorf("got no iamlive_calls_total %v", tt.labels)
+			continue
+		}
+		if got := metric.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("got iamlive_calls_total %v of %g, want %g", tt.labels, got, tt.want)
+		}
+	}
+	if got := len(families["iamlive_calls_total"].Metric); got != len(calls) {
+		t.Errorf("got %d iamlive_calls_total series, want %d", got, len(calls))
+	}
+
+	if metric := getTestMetric(families, "iamlive_unique_action_pairs_total"); metric == nil || metric.GetGauge().GetValue() != 4 {
+		t.Errorf("got iamlive_unique_action_pairs_total %v, want 4", metric)
+	}
+
+	s3Durations := getTestMetric(families, "iamlive_proxy_request_duration_seconds", "service=S3")
+	if s3Durations == nil {
+		t.Fatal("got no iamlive_proxy_request_duration_seconds for S3")
+	}
+	if histogram := s3Durations.GetHistogram(); histogram.GetSampleCount() != 3 || histogram.GetSampleSum() < 2.069 || histogram.GetSampleSum() > 2.071 {
+		t.Errorf("got %d S3 durations totalling %gs, want 3 totalling 2.07s", histogram.GetSampleCount(), histogram.GetSampleSum())
+	}
+	for _, bucket := range s3Durations.GetHistogram().GetBucket() {
+		want := uint64(0)
+		switch {
+		case bucket.GetUpperBound() >= 2:
+			want = 3
+		case bucket.GetUpperBound() >= 0.04:
+			want = 2
+		case bucket.GetUpperBound() >= 0.03:
+			want = 1
+		}
+		if bucket.GetCumulativeCount() != want {
+			t.Errorf("got %d S3 durations up to %gs, want %d", bucket.GetCumulativeCount(), bucket.GetUpperBound(), want)
+		}
+	}
+	if metric := getTestMetric(families, "iamlive_proxy_request_duration_seconds", "service=EC2"); metric == nil || metric.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("got EC2 durations %v, want 1", metric)
+	}
+	if metric := getTestMetric(families, "iamlive_proxy_request_duration_seconds", "service=DynamoDB"); metric != nil {
+		t.Errorf("got DynamoDB durations %v, want none for CSM calls", metric)
+	}
+
+	responseBytes := []struct {
+		labels []string
+		want   float64
+	}{
+		{labels: []string{"action=ListBuckets", "service=S3"}, want: 768},
+		{labels: []string{"action=GetObject", "service=S3"}, want: 128},
+		{labels: []string{"action=DescribeInstances", "service=EC2"}, want: 4096},
+		{labels: []string{"action=ListTables", "service=DynamoDB"}, want: 0},
+	}
+	for _, tt := range responseBytes {
+		if metric := getTestMetric(families, "iamlive_response_bytes_total", tt.labels...); metric == nil || metric.GetCounter().GetValue() != tt.want {
+			t.Errorf("got iamlive_response_bytes_total %v of %v, want %g", tt.labels, metric, tt.want)
+		}
+	}
+
+	// each scrape reads the call log as it is then
+	appendTestMetricsCall("ProxyCall", "S3", "ListBuckets", "us-east-1", 200, 20*time.Millisecond, 64)
+	families = scrapeTestMetrics(t, metricsURL)
+	if metric := getTestMetric(families, "iamlive_calls_total", calls[0].labels...); metric == nil || metric.GetCounter().GetValue() != 3 {
+		t.Errorf("got iamlive_calls_total %v after another call, want 3", metric)
+	}
+	if metric := getTestMetric(families, "iamlive_response_bytes_total", responseBytes[0].labels...); metric == nil || metric.GetCounter().GetValue() != 832 {
+		t.Errorf("got iamlive_response_bytes_total %v after another call, want 832", metric)
+	}
+
+	callLog.Reset()
+	families = scrapeTestMetrics(t, metricsURL)
+	if metric := getTestMetric(families, "iamlive_calls_total", calls[0].labels...); metric != nil {
+		t.Errorf("got iamlive_calls_total %v after the call log was reset, want none", metric)
+	}
+	if metric := getTestMetric(families, "iamlive_unique_action_pairs_total"); metric == nil || metric.GetGauge().GetValue() != 0 {
+		t.Errorf("got iamlive_unique_action_pairs_total %v after the call log was reset, want 0", metric)
+	}
+}
+
+func TestStartMetricsServer(t *testing.T) {
+	if got := flag.Lookup("metrics-host").DefValue; got != "127.0.0.1" {
+		t.Errorf("got --metrics-host default %s, want 127.0.0.1", got)
+	}
+
+	// without --metrics-port no server is started
+	setTestFlag(t, "metrics-port", "0")
+	if err := startMetricsServer(); err != nil {
+		t.Errorf("got error %v without --metrics-port", err)
+	}
+
+	// a port that is taken fails on start
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	setTestFlag(t, "metrics-host", "127.0.0.1")
+	setTestFlag(t, "metrics-port", fmt.Sprint(listener.Addr().(*net.TCPAddr).Port))
+
+	if err := startMetricsServer(); err == nil || !strings.Contains(err.Error(), "could not start the --metrics-port server") {
+		t.Errorf("got error %v, want the port to be taken", err)
+	}
+}
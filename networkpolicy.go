@@ -0,0 +1,137 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ip-ranges.json is refreshed before each release, see the release workflow
+//go:generate curl -sSf -o ip-ranges.json https://ip-ranges.amazonaws.com/ip-ranges.json
+
+//go:embed ip-ranges.json
+var bIPRanges []byte
+
+// AWSIPRanges is the format of the published AWS IP address ranges
+type AWSIPRanges struct {
+	SyncToken string `json:"syncToken"`
+	Prefixes  []struct {
+		IPPrefix string `json:"ip_prefix"`
+		Region   string `json:"region"`
+		Service  string `json:"service"`
+	} `json:"prefixes"`
+	IPv6Prefixes []struct {
+		IPv6Prefix string `json:"ipv6_prefix"`
+		Region     string `json:"region"`
+		Service    string `json:"service"`
+	} `json:"ipv6_prefixes"`
+}
+
+// ipRanges maps an ip-ranges service (e.g. AMAZON, S3) and region to its CIDR blocks
+var ipRanges map[string]map[string][]string
+
+func loadIPRanges() error {
+	if !*networkPolicyModeFlag {
+		return nil
+	}
+
+	var ranges AWSIPRanges
+	err := json.Unmarshal(bIPRanges, &ranges)
+	if err != nil {
+		return fmt.Errorf("invalid embedded IP ranges: %v", err)
+	}
+
+	ipRanges = make(map[string]map[string][]string)
+	addRange := func(service, region, cidr string) {
+		if ipRanges[service] == nil {
+			ipRanges[service] = make(map[string][]string)
+		}
+		ipRanges[service][region] = append(ipRanges[service][region], cidr)
+	}
+	for _, prefix := range ranges.Prefixes {
+		addRange(prefix.Service, prefix.Region, prefix.IPPrefix)
+	}
+	for _, prefix := range ranges.IPv6Prefixes {
+		addRange(prefix.Service, prefix.Region, prefix.IPv6Prefix)
+	}
+
+	// without ranges the NetworkPolicy would only allow DNS, so the placeholder of development builds is refused
+	if len(ipRanges) == 0 {
+		return fmt.Errorf("--network-policy-mode cannot be used as no AWS IP ranges are embedded in this build (sync token %q), run go generate and rebuild to embed them", ranges.SyncToken)
+	}
+
+	return nil
+}
+
+// getServiceCIDRs returns the CIDR blocks for a service in a region, falling back to the ranges of all
+// Amazon services when the service isn't published separately
+func getServiceCIDRs(service, region string) []string {
+	ipRangesService := strings.ToUpper(strings.Replace(service, " ", "_", -1))
+	if cidrs, ok := ipRanges[ipRangesService][region]; ok {
+		return cidrs
+	}
+
+	return ipRanges["AMAZON"][region]
+}
+
+func getNetworkPolicyOutput() []byte {
+	cidrSet := make(map[string]bool)
+
+	for _, entry := range getPolicyEntries() {
+		region := entry.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		for _, cidr := range getServiceCIDRs(entry.Service, region) {
+			cidrSet[cidr] = true
+		}
+		for _, cidr := range getServiceCIDRs(entry.Service, "GLOBAL") {
+			cidrSet[cidr] = true
+		}
+	}
+
+	var cidrs []string
+	for cidr := range cidrSet {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+
+	var sb strings.Builder
+	sb.WriteString("apiVersion: networking.k8s.io/v1\n")
+	sb.WriteString("kind: NetworkPolicy\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString("  name: iamlive-egress\n")
+	sb.WriteString("spec:\n")
+
+	filterSplit := strings.SplitN(*kubernetesLabelFilterFlag, "=", 2)
+	if len(filterSplit) == 2 {
+		sb.WriteString("  podSelector:\n")
+		sb.WriteString("    matchLabels:\n")
+		sb.WriteString(fmt.Sprintf("      %s: %s\n", filterSplit[0], filterSplit[1]))
+	} else {
+		sb.WriteString("  podSelector: {}\n")
+	}
+
+	sb.WriteString("  policyTypes:\n")
+	sb.WriteString("  - Egress\n")
+	sb.WriteString("  egress:\n")
+	sb.WriteString("  - ports:\n")
+	sb.WriteString("    - protocol: UDP\n")
+	sb.WriteString("      port: 53\n")
+	sb.WriteString("    - protocol: TCP\n")
+	sb.WriteString("      port: 53\n")
+	if len(cidrs) > 0 {
+		sb.WriteString("  - to:\n")
+		for _, cidr := range cidrs {
+			sb.WriteString("    - ipBlock:\n")
+			sb.WriteString(fmt.Sprintf("        cidr: %s\n", cidr))
+		}
+		sb.WriteString("    ports:\n")
+		sb.WriteString("    - protocol: TCP\n")
+		sb.WriteString("      port: 443\n")
+	}
+
+	return []byte(sb.String())
+}
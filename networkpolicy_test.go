@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testIPRanges = `{
+  "syncToken": "1700000000",
+  "createDate": "2023-11-14-22-13-20",
+  "prefixes": [
+    {"ip_prefix": "52.216.0.0/15", "region": "us-east-1", "service": "S3", "network_border_group": "us-east-1"},
+    {"ip_prefix": "52.94.0.0/22", "region": "us-east-1", "service": "AMAZON", "network_border_group": "us-east-1"},
+    {"ip_prefix": "52.119.224.0/20", "region": "us-east-1", "service": "DYNAMODB", "network_border_group": "us-east-1"},
+    {"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "S3", "network_border_group": "ap-northeast-2"},
+    {"ip_prefix": "52.46.0.0/18", "region": "GLOBAL", "service": "AMAZON", "network_border_group": "GLOBAL"}
+  ],
+  "ipv6_prefixes": [
+    {"ipv6_prefix": "2600:1f18::/33", "region": "us-east-1", "service": "AMAZON", "network_border_group": "us-east-1"}
+  ]
+}`
+
+// loadTestIPRanges loads IP ranges in place of the embedded ones, until the end of the test
+func loadTestIPRanges(t *testing.T, doc string) error {
+	t.Helper()
+
+	setTestFlag(t, "network-policy-mode", "true")
+	previous := bIPRanges
+	bIPRanges = []byte(doc)
+	t.Cleanup(func() {
+		bIPRanges = previous
+		ipRanges = nil
+	})
+
+	return loadIPRanges()
+}
+
+func TestLoadIPRangesEmpty(t *testing.T) {
+	err := loadTestIPRanges(t, `{"syncToken": "0", "createDate": "", "prefixes": [], "ipv6_prefixes": []}`)
+	if err == nil || !strings.Contains(err.Error(), "no AWS IP ranges are embedded") {
+		t.Fatalf("got error %v, want one for the empty IP ranges", err)
+	}
+}
+
+func TestLoadIPRangesInvalid(t *testing.T) {
+	if err := loadTestIPRanges(t, `{"prefixes": [`); err == nil {
+		t.Fatal("got no error for invalid IP ranges")
+	}
+}
+
+func TestGetServiceCIDRs(t *testing.T) {
+	if err := loadTestIPRanges(t, testIPRanges); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		service string
+		region  string
+		want    []string
+	}{
+		{service: "S3", region: "us-east-1", want: []string{"52.216.0.0/15"}},
+		{service: "S3", region: "ap-northeast-2", want: []string{"3.5.140.0/22"}},
+		{service: "DynamoDB", region: "us-east-1", want: []string{"52.119.224.0/20"}},
+		{service: "EC2", region: "us-east-1", want: []string{"52.94.0.0/22", "2600:1f18::/33"}},
+		{service: "STS", region: "GLOBAL", want: []string{"52.46.0.0/18"}},
+		{service: "EC2", region: "eu-west-3", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service+" "+tt.region, func(t *testing.T) {
+			if got := getServiceCIDRs(tt.service, tt.region); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNetworkPolicyOutput(t *testing.T) {
+	resetTestCallLog(t)
+	if err := loadTestIPRanges(t, testIPRanges); err != nil {
+		t.Fatal(err)
+	}
+	setTestFlag(t, "kubernetes-label-filter", "app=orders")
+
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "DynamoDB", Method: "ListTables", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	var networkPolicy struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			PodSelector struct {
+				MatchLabels map[string]string `yaml:"matchLabels"`
+			} `yaml:"podSelector"`
+			Egress []struct {
+				To []struct {
+					IPBlock struct {
+						CIDR string `yaml:"cidr"`
+					} `yaml:"ipBlock"`
+				} `yaml:"to"`
+			} `yaml:"egress"`
+		} `yaml:"spec"`
+	}
+	output := getNetworkPolicyOutput()
+	if err := yaml.Unmarshal(output, &networkPolicy); err != nil {
+		t.Fatalf("the output is not valid YAML: %v\n%s", err, output)
+	}
+
+	if networkPolicy.Kind != "NetworkPolicy" {
+		t.Errorf("got kind %s, want NetworkPolicy", networkPolicy.Kind)
+	}
+	if got := networkPolicy.Spec.PodSelector.MatchLabels["app"]; got != "orders" {
+		t.Errorf("got pod selector app=%s, want app=orders", got)
+	}
+	if len(networkPolicy.Spec.Egress) != 2 {
+		t.Fatalf("got %d egress rules, want DNS and HTTPS\n%s", len(networkPolicy.Spec.Egress), output)
+	}
+
+	var cidrs []string
+	for _, to := range networkPolicy.Spec.Egress[1].To {
+		cidrs = append(cidrs, to.IPBlock.CIDR)
+	}
+	want := []string{"52.119.224.0/20", "52.216.0.0/15", "52.46.0.0/18"}
+	if !reflect.DeepEqual(cidrs, want) {
+		t.Errorf("got CIDRs %v, want %v", cidrs, want)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// NewActionEvent is the body of the webhook sent when an action is seen for the first time
+type NewActionEvent struct {
+	Event     string    `json:"event"`
+	Action    string    `json:"action"`
+	Service   string    `json:"service"`
+	Method    string    `json:"method"`
+	Region    string    `json:"region,omitempty"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+var knownActions map[string]bool
+var newActions []string
+var knownActionsMutex sync.Mutex
+var knownActionsFile *os.File
+var newActionWebhooks sync.WaitGroup
+
+// loadKnownActions reads the actions seen in previous sessions from --known-actions-file, which new actions are
+// appended to as they are seen
+func loadKnownActions() error {
+	if !*alertOnNewActionFlag && !*failOnNewActionFlag {
+		return nil
+	}
+
+	knownActionsPath, err := homedir.Expand(*knownActionsFileFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(knownActionsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	knownActions = make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		knownActions[strings.ToLower(line)] = true
+	}
+
+	err = os.MkdirAll(filepath.Dir(knownActionsPath), 0700)
+	if err != nil {
+		return err
+	}
+	knownActionsFile, err = os.OpenFile(knownActionsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		_, err = knownActionsFile.WriteString("\n")
+	}
+
+	return err
+}
+
+// checkNewActions alerts on the actions of a call that have not been seen before, and remembers them
+func checkNewActions(entry Entry) {
+	if knownActions == nil {
+		return
+	}
+
+	for _, action := range getActions(entry.Service, entry.Method) {
+		knownActionsMutex.Lock()
+		if knownActions[strings.ToLower(action)] {
+			knownActionsMutex.Unlock()
+			continue
+		}
+		knownActions[strings.ToLower(action)] = true
+		newActions = append(newActions, action)
+		knownActionsFile.WriteString(action + "\n")
+		knownActionsMutex.Unlock()
+
+		if *alertOnNewActionFlag {
+			log.Printf("ALERT: new action %s", action)
+			if *webhookURLFlag != "" {
+				newActionWebhooks.Add(1)
+				go sendNewActionWebhook(NewActionEvent{
+					Event:     "new_action",
+					Action:    action,
+					Service:   entry.Service,
+					Method:    entry.Method,
+					Region:    entry.Region,
+					SessionID: entry.SessionID,
+					Timestamp: entry.Timestamp,
+				})
+			}
+		}
+	}
+}
+
+func sendNewActionWebhook(event NewActionEvent) {
+	defer newActionWebhooks.Done()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(*webhookURLFlag, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("WARNING: could not send the new action webhook for %s: %v", event.Action, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("WARNING: the new action webhook for %s returned %d", event.Action, resp.StatusCode)
+	}
+}
+
+// getNewActions returns the actions seen for the first time in this session
+func getNewActions() []string {
+	knownActionsMutex.Lock()
+	defer knownActionsMutex.Unlock()
+
+	return append([]string{}, newActions...)
+}
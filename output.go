@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var outputFormats = []string{"json", "kubeseal", "env", "aws-iam-policy-simulator-input", "github-oidc", "spacelift", "kustomize-patch", "gcp-iam", "aws-config-rule", "terraform-import", "github-copilot", "backstage", "packer", "aws-policy-generator", "azure-rbac", "vault-policy", "semgrep", "github-secret-scanning", "terraform-hcl", "cloudformation-yaml", "scout-suite", "cdk-python", "aws-sso-permission-set-cli", "scp", "raw-actions", "open-api", "aws-cloudwatch-contributor-insights", "html"}
+
+func validateOutputFormat() error {
+	return validateOutputFormatName(*outputFormatFlag)
+}
+
+func validateOutputFormatName(name string) error {
+	for _, format := range outputFormats {
+		if name == format {
+			if format == "github-oidc" && *githubRepoFlag == "" {
+				return fmt.Errorf("the github-oidc output format requires --github-repo")
+			}
+			if format == "kustomize-patch" && *roleARNFlag == "" {
+				return fmt.Errorf("the kustomize-patch output format requires --role-arn")
+			}
+			if format == "aws-sso-permission-set-cli" && *ssoInstanceARNFlag == "" {
+				return fmt.Errorf("the aws-sso-permission-set-cli output format requires --sso-instance-arn")
+			}
+			if format == "aws-cloudwatch-contributor-insights" && *cwLogGroupNameFlag == "" {
+				return fmt.Errorf("the aws-cloudwatch-contributor-insights output format requires --cw-log-group-name")
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown output format %q", name)
+}
+
+// getPolicyOutput renders the policy document in the format selected by --output-format
+func getPolicyOutput() []byte {
+	return getPolicyOutputForFormat(*outputFormatFlag)
+}
+
+// getPolicyOutputForFormat renders the policy document in a format, with the account IDs of any ARNs redacted when
+// --redact-account-id is set
+func getPolicyOutputForFormat(format string) []byte {
+	var output []byte
+	switch format {
+	case "kubeseal":
+		output = getKubesealOutput(getPolicyDocument())
+	case "env":
+		output = getEnvOutput(getPolicyDocument())
+	case "aws-iam-policy-simulator-input":
+		output = getSimulatorInputOutput()
+	case "spacelift":
+		output = getSpaceliftOutput()
+	case "kustomize-patch":
+		output = getKustomizePatchOutput()
+	case "gcp-iam":
+		output = getGCPIAMOutput()
+	case "aws-config-rule":
+		output = getConfigRuleOutput()
+	case "terraform-import":
+		output = getTerraformImportOutput()
+	case "github-copilot":
+		output = getCopilotOutput()
+	case "backstage":
+		output = getBackstageOutput()
+	case "packer":
+		output = getPackerOutput()
+	case "aws-policy-generator":
+		output = getPolicyGeneratorOutput()
+	case "azure-rbac":
+		output = getAzureRBACOutput()
+	case "vault-policy":
+		output = getVaultPolicyOutput()
+	case "semgrep":
+		output = getSemgrepOutput()
+	case "github-secret-scanning":
+		output = getSecretScanningOutput()
+	case "terraform-hcl":
+		output = getTerraformHCLOutput()
+	case "cloudformation-yaml":
+		output = getCloudFormationOutput()
+	case "scout-suite":
+		output = getScoutSuiteOutput()
+	case "cdk-python":
+		output = getCDKPythonOutput()
+	case "aws-sso-permission-set-cli":
+		output = getSSOPermissionSetOutput()
+	case "scp":
+		output = getSCPOutput()
+	case "raw-actions":
+		output = getRawActionsOutput()
+	case "open-api":
+		output = getOpenAPIOutput()
+	case "aws-cloudwatch-contributor-insights":
+		output = getContributorInsightsOutput()
+	case "html":
+		output = getHTMLOutput()
+	default:
+		output = getPolicyDocument()
+	}
+
+	return redactOutputAccountIDs(output)
+}
+
+// AdditionalOutput is a companion document written alongside the policy
+type AdditionalOutput struct {
+	Suffix string
+	Output []byte
+}
+
+// getAdditionalOutputs returns any companion documents for the selected options, each written to the output
+// file name with its suffix added (a suffix with an extension replaces that of the output file)
+func getAdditionalOutputs() []AdditionalOutput {
+	outputs := getFormatAdditionalOutputs(*outputFormatFlag)
+
+	if *networkPolicyModeFlag {
+		outputs = append(outputs, getNetworkPolicyAdditionalOutput())
+	}
+
+	return outputs
+}
+
+// getFormatAdditionalOutputs returns the companion documents of an output format, such as the trust policy of
+// github-oidc
+func getFormatAdditionalOutputs(format string) []AdditionalOutput {
+	var outputs []AdditionalOutput
+
+	if format == "github-oidc" {
+		outputs = append(outputs, AdditionalOutput{
+			Suffix: "-trust",
+			Output: getGitHubOIDCTrustPolicy(),
+		})
+	}
+
+	if format == "kustomize-patch" {
+		outputs = append(outputs, AdditionalOutput{
+			Suffix: "-policy.json",
+			Output: getPolicyDocument(),
+		})
+	}
+
+	if format == "aws-config-rule" {
+		outputs = append(outputs, AdditionalOutput{
+			Suffix: "-lambda.py",
+			Output: getConfigRuleLambdaSource(),
+		})
+	}
+
+	for i := range outputs {
+		outputs[i].Output = redactOutputAccountIDs(outputs[i].Output)
+	}
+
+	return outputs
+}
+
+func getNetworkPolicyAdditionalOutput() AdditionalOutput {
+	return AdditionalOutput{
+		Suffix: "-networkpolicy.yaml",
+		Output: getNetworkPolicyOutput(),
+	}
+}
+
+func getAdditionalOutputFile(suffix string) string {
+	return getCompanionFile(*outputFileFlag, suffix)
+}
+
+// getCompanionFile returns the name of a companion document of a file
+func getCompanionFile(file string, suffix string) string {
+	ext := filepath.Ext(file)
+	if filepath.Ext(suffix) != "" {
+		return strings.TrimSuffix(file, ext) + suffix
+	}
+	return strings.TrimSuffix(file, ext) + suffix + ext
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed azure-mapping.json
+var bAzureMapping []byte
+
+// AzureRoleDefinition is an Azure RBAC custom role definition
+type AzureRoleDefinition struct {
+	Name             string   `json:"Name"`
+	IsCustom         bool     `json:"IsCustom"`
+	Description      string   `json:"Description"`
+	Actions          []string `json:"Actions"`
+	NotActions       []string `json:"NotActions"`
+	DataActions      []string `json:"DataActions"`
+	NotDataActions   []string `json:"NotDataActions"`
+	AssignableScopes []string `json:"AssignableScopes"`
+}
+
+type azurePermissions struct {
+	Actions     []string `json:"actions"`
+	DataActions []string `json:"dataActions"`
+}
+
+func getAzureMapping() map[string]azurePermissions {
+	var mapping map[string]azurePermissions
+	if err := json.Unmarshal(bAzureMapping, &mapping); err != nil {
+		panic(err)
+	}
+
+	lowerMapping := make(map[string]azurePermissions)
+	for action, permissions := range mapping {
+		lowerMapping[strings.ToLower(action)] = permissions
+	}
+	return lowerMapping
+}
+
+// getAzurePermissions returns the Azure control and data plane permissions approximately equivalent to the
+// captured actions, and the actions which have no mapping
+func getAzurePermissions() (azurePermissions, []string) {
+	mapping := getAzureMapping()
+
+	var permissions azurePermissions
+	var unmapped []string
+	for _, action := range getCapturedActions() {
+		if mappedPermissions, ok := mapping[strings.ToLower(action)]; ok {
+			permissions.Actions = append(permissions.Actions, mappedPermissions.Actions...)
+			permissions.DataActions = append(permissions.DataActions, mappedPermissions.DataActions...)
+		} else {
+			unmapped = append(unmapped, action)
+		}
+	}
+
+	permissions.Actions = uniqueSlice(permissions.Actions)
+	sort.Strings(permissions.Actions)
+	permissions.DataActions = uniqueSlice(permissions.DataActions)
+	sort.Strings(permissions.DataActions)
+	return permissions, unmapped
+}
+
+func getAzureRBACOutput() []byte {
+	permissions, _ := getAzurePermissions()
+
+	role := AzureRoleDefinition{
+		Name:             *azureRoleNameFlag,
+		IsCustom:         true,
+		Description:      "Generated by iamlive from observed AWS calls. Permissions are approximate equivalents of the AWS actions and must be reviewed before use.",
+		Actions:          permissions.Actions,
+		NotActions:       []string{},
+		DataActions:      permissions.DataActions,
+		NotDataActions:   []string{},
+		AssignableScopes: []string{*azureAssignableScopeFlag},
+	}
+	if role.Actions == nil {
+		role.Actions = []string{}
+	}
+	if role.DataActions == nil {
+		role.DataActions = []string{}
+	}
+
+	doc, err := json.MarshalIndent(role, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func getAzureRBACNotes() []string {
+	notes := []string{"WARNING: Azure permissions are approximate equivalents of the AWS actions and must be reviewed"}
+
+	_, unmapped := getAzurePermissions()
+	for _, action := range unmapped {
+		notes = append(notes, fmt.Sprintf("WARNING: %s has no Azure equivalent mapped", action))
+	}
+
+	return notes
+}
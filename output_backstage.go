@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getBackstageOutput renders a Backstage catalog-info.yaml component annotated with the captured permissions
+func getBackstageOutput() []byte {
+	var services []string
+	for _, action := range getCapturedActions() {
+		services = append(services, strings.ToLower(strings.SplitN(action, ":", 2)[0]))
+	}
+	services = uniqueSlice(services)
+	sort.Strings(services)
+
+	var sb strings.Builder
+	sb.WriteString("apiVersion: backstage.io/v1alpha1\n")
+	sb.WriteString("kind: Component\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString(fmt.Sprintf("  name: %s\n", *backstageComponentNameFlag))
+	sb.WriteString("  annotations:\n")
+	// the policy is base64-encoded so that it fits in a single YAML string annotation
+	sb.WriteString(fmt.Sprintf("    iamlive.tolidano.github.io/policy: %q\n", base64.StdEncoding.EncodeToString(getPolicyDocument())))
+	sb.WriteString(fmt.Sprintf("    iamlive.tolidano.github.io/services: %q\n", strings.Join(services, ",")))
+
+	return []byte(sb.String())
+}
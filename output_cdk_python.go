@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getCDKPythonOutput renders an AWS CDK construct in Python defining a managed policy with the policy statements
+func getCDKPythonOutput() []byte {
+	var sb strings.Builder
+	sb.WriteString("from aws_cdk import aws_iam\n")
+	sb.WriteString("from constructs import Construct\n")
+	sb.WriteString("\n\n")
+	sb.WriteString("class IamlivePolicy(Construct):\n")
+	sb.WriteString("    def __init__(self, scope: Construct, construct_id: str) -> None:\n")
+	sb.WriteString("        super().__init__(scope, construct_id)\n")
+	sb.WriteString("\n")
+	sb.WriteString("        self.policy = aws_iam.ManagedPolicy(\n")
+	sb.WriteString("            self,\n")
+	sb.WriteString("            \"IamlivePolicy\",\n")
+	sb.WriteString("            document=aws_iam.PolicyDocument(\n")
+	sb.WriteString("                statements=[\n")
+	for _, statement := range getPolicy().Statement {
+		effect := "ALLOW"
+		if statement.Effect == "Deny" {
+			effect = "DENY"
+		}
+
+		sb.WriteString("                    aws_iam.PolicyStatement(\n")
+		if statement.Sid != "" {
+			sb.WriteString(fmt.Sprintf("                        sid=%q,\n", statement.Sid))
+		}
+		sb.WriteString(fmt.Sprintf("                        effect=aws_iam.Effect.%s,\n", effect))
+		sb.WriteString("                        actions=[\n")
+		for _, action := range statement.Action {
+			sb.WriteString(fmt.Sprintf("                            %q,\n", action))
+		}
+		sb.WriteString("                        ],\n")
+		sb.WriteString("                        resources=[\n")
+		for _, resource := range getStatementResources(statement) {
+			sb.WriteString(fmt.Sprintf("                            %q,\n", resource))
+		}
+		sb.WriteString("                        ],\n")
+		sb.WriteString("                    ),\n")
+	}
+	sb.WriteString("                ],\n")
+	sb.WriteString("            ),\n")
+	sb.WriteString("        )\n")
+
+	return []byte(sb.String())
+}
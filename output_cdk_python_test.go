@@ -0,0 +1,139 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanTestPython tokenizes Python source with the Go scanner, which shares the Python token structure for
+// identifiers, double quoted strings, operators and brackets, returning the string literals it contains
+func scanTestPython(t *testing.T, src string) []string {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("policy.py", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(pos token.Position, msg string) {
+		t.Errorf("%s: %s", pos, msg)
+	}, 0)
+
+	var strs, brackets []string
+	closing := map[token.Token]string{token.RPAREN: "(", token.RBRACK: "["}
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch tok {
+		case token.LPAREN:
+			brackets = append(brackets, "(")
+		case token.LBRACK:
+			brackets = append(brackets, "[")
+		case token.RPAREN, token.RBRACK:
+			if len(brackets) == 0 || brackets[len(brackets)-1] != closing[tok] {
+				t.Fatalf("%s: unmatched %s", fset.Position(pos), tok)
+			}
+			brackets = brackets[:len(brackets)-1]
+		case token.LBRACE, token.RBRACE, token.DEFINE, token.LAND, token.LOR, token.NOT, token.CHAR, token.SEMICOLON:
+			// Go tokens with no meaning in the generated Python, semicolons aside as the scanner inserts those at line ends
+			if tok == token.SEMICOLON && lit == "\n" {
+				continue
+			}
+			t.Errorf("%s: unexpected %s", fset.Position(pos), tok)
+		case token.STRING:
+			if !strings.HasPrefix(lit, `"`) {
+				t.Errorf("%s: raw string %s is not a Python literal", fset.Position(pos), lit)
+				continue
+			}
+			str, err := strconv.Unquote(lit)
+			if err != nil {
+				t.Fatalf("%s: %v", fset.Position(pos), err)
+			}
+			strs = append(strs, str)
+		}
+	}
+	if len(brackets) != 0 {
+		t.Errorf("%d brackets are not closed", len(brackets))
+	}
+
+	// a block is only opened by a line ending with a colon or within brackets
+	indent := 0
+	opened := false
+	for i, line := range strings.Split(src, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		if depth%4 != 0 {
+			t.Errorf("line %d is indented by %d spaces", i+1, depth)
+		}
+		if (opened && depth > indent+4) || (!opened && depth > indent) {
+			t.Errorf("line %d is indented by %d spaces after %d", i+1, depth, indent)
+		}
+		indent = depth
+		trimmed := strings.TrimSpace(line)
+		opened = strings.HasSuffix(trimmed, ":") || strings.HasSuffix(trimmed, "(") || strings.HasSuffix(trimmed, "[")
+	}
+
+	return strs
+}
+
+func TestCDKPythonOutput(t *testing.T) {
+	resetTestCallLog(t)
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "DynamoDB", Method: "ListTables", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	output := string(getCDKPythonOutput())
+	strs := scanTestPython(t, output)
+
+	want := []string{"IamlivePolicy", "s3:ListAllMyBuckets", "dynamodb:ListTables", "*"}
+	if !reflect.DeepEqual(strs, want) {
+		t.Errorf("got strings %q, want %q", strs, want)
+	}
+	for _, construct := range []string{"class IamlivePolicy(Construct):", "aws_iam.ManagedPolicy(", "document=aws_iam.PolicyDocument(", "aws_iam.PolicyStatement(", "effect=aws_iam.Effect.ALLOW,"} {
+		if !strings.Contains(output, construct) {
+			t.Errorf("the output has no %s", construct)
+		}
+	}
+}
+
+func TestCDKPythonOutputEscaping(t *testing.T) {
+	resetTestCallLog(t)
+	callLog.Append(Entry{
+		Region:              "us-east-1",
+		Type:                "ProxyCall",
+		Service:             "S3",
+		Method:              "GetObject",
+		URIParameters:       map[string]string{"Bucket": "reports", "Key": `q1 "final"\draft é`},
+		FinalHTTPStatusCode: 200,
+		Timestamp:           time.Now(),
+	})
+
+	strs := scanTestPython(t, string(getCDKPythonOutput()))
+
+	found := false
+	for _, str := range strs {
+		if strings.HasSuffix(str, `q1 "final"\draft é`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("got strings %q, want the object key unchanged", strs)
+	}
+}
+
+func TestCDKPythonOutputNoCalls(t *testing.T) {
+	resetTestCallLog(t)
+
+	output := string(getCDKPythonOutput())
+	scanTestPython(t, output)
+
+	if !strings.Contains(output, "statements=[\n                ],") {
+		t.Errorf("the output has statements for no calls")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var cloudFormationLogicalIDRegexp = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// getCloudFormationLogicalIDPrefix returns --cfn-stack-name as an alphanumeric logical ID prefix, e.g. my-stack
+// becomes MyStack
+func getCloudFormationLogicalIDPrefix() string {
+	var prefix string
+	for _, word := range cloudFormationLogicalIDRegexp.Split(*cfnStackNameFlag, -1) {
+		if word != "" {
+			prefix += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	if prefix == "" {
+		return "Iamlive"
+	}
+
+	return prefix
+}
+
+// getCaptureTimeRange returns the timestamps of the first and last captured calls
+func getCaptureTimeRange() (time.Time, time.Time) {
+	var first, last time.Time
+	for _, entry := range callLog.Snapshot() {
+		if first.IsZero() || entry.Timestamp.Before(first) {
+			first = entry.Timestamp
+		}
+		if entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+
+	return first, last
+}
+
+type cloudFormationParameter struct {
+	Type        string `yaml:"Type"`
+	Description string `yaml:"Description"`
+	Default     string `yaml:"Default"`
+}
+
+type cloudFormationStatement struct {
+	Sid      string      `yaml:"Sid,omitempty"`
+	Effect   string      `yaml:"Effect"`
+	Action   []string    `yaml:"Action"`
+	Resource interface{} `yaml:"Resource,omitempty"`
+}
+
+type cloudFormationPolicyDocument struct {
+	Version   string                    `yaml:"Version"`
+	Statement []cloudFormationStatement `yaml:"Statement"`
+}
+
+type cloudFormationManagedPolicyProperties struct {
+	ManagedPolicyName *yaml.Node                   `yaml:"ManagedPolicyName"`
+	PolicyDocument    cloudFormationPolicyDocument `yaml:"PolicyDocument"`
+}
+
+type cloudFormationManagedPolicy struct {
+	Type       string                                `yaml:"Type"`
+	Properties cloudFormationManagedPolicyProperties `yaml:"Properties"`
+}
+
+// cloudFormationTemplate is a template of managed policies. Resources is a node so that the policies keep their
+// order and the split can be explained in a comment.
+type cloudFormationTemplate struct {
+	AWSTemplateFormatVersion string                             `yaml:"AWSTemplateFormatVersion"`
+	Description              string                             `yaml:"Description"`
+	Parameters               map[string]cloudFormationParameter `yaml:"Parameters"`
+	Resources                *yaml.Node                         `yaml:"Resources"`
+}
+
+func getCloudFormationPolicyDocument(statements []Statement) cloudFormationPolicyDocument {
+	doc := cloudFormationPolicyDocument{
+		Version:   "2012-10-17",
+		Statement: []cloudFormationStatement{},
+	}
+	for _, statement := range statements {
+		doc.Statement = append(doc.Statement, cloudFormationStatement{
+			Sid:      statement.Sid,
+			Effect:   statement.Effect,
+			Action:   statement.Action,
+			Resource: statement.Resource,
+		})
+	}
+
+	return doc
+}
+
+// getCloudFormationOutput renders a CloudFormation template of AWS::IAM::ManagedPolicy resources for the policy,
+// split into several policies if it exceeds the managed policy size limit
+func getCloudFormationOutput() []byte {
+	statements := getPolicy().Statement
+	groups := splitPolicyStatements(statements, *maxPolicySizeFlag)
+	prefix := getCloudFormationLogicalIDPrefix()
+
+	description := "IAM managed policy generated by iamlive"
+	if first, last := getCaptureTimeRange(); !first.IsZero() {
+		description = fmt.Sprintf("%s from calls captured between %s and %s", description, first.UTC().Format(time.RFC3339), last.UTC().Format(time.RFC3339))
+	}
+
+	resources := &yaml.Node{Kind: yaml.MappingNode}
+	for i, group := range groups {
+		logicalID := prefix + "Policy"
+		policyName := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!Ref", Value: "PolicyName"}
+		if len(groups) > 1 {
+			logicalID = fmt.Sprintf("%sPolicy%d", prefix, i+1)
+			policyName = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!Sub", Value: fmt.Sprintf("${PolicyName}-%d", i+1)}
+		}
+
+		policy := &yaml.Node{}
+		if err := policy.Encode(cloudFormationManagedPolicy{
+			Type: "AWS::IAM::ManagedPolicy",
+			Properties: cloudFormationManagedPolicyProperties{
+				ManagedPolicyName: policyName,
+				PolicyDocument:    getCloudFormationPolicyDocument(group),
+			},
+		}); err != nil {
+			panic(err)
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: logicalID}
+		if i == 0 && len(groups) > 1 {
+			key.HeadComment = fmt.Sprintf("The policy exceeds the %d character policy size limit, so it is split into %d policies", *maxPolicySizeFlag, len(groups))
+		}
+		resources.Content = append(resources.Content, key, policy)
+	}
+
+	template := cloudFormationTemplate{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Description:              description,
+		Parameters: map[string]cloudFormationParameter{
+			"PolicyName": {
+				Type:        "String",
+				Description: "The name of the managed policy, numbered if the policy is split",
+				Default:     strings.ToLower(prefix) + "-policy",
+			},
+		},
+		Resources: resources,
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(template); err != nil {
+		panic(err)
+	}
+	encoder.Close()
+	return []byte(sb.String())
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type cloudFormationTestStatement struct {
+	Sid      string      `yaml:"Sid"`
+	Effect   string      `yaml:"Effect"`
+	Action   []string    `yaml:"Action"`
+	Resource interface{} `yaml:"Resource"`
+}
+
+type cloudFormationTestResource struct {
+	Type       string `yaml:"Type"`
+	Properties struct {
+		ManagedPolicyName yaml.Node `yaml:"ManagedPolicyName"`
+		PolicyDocument    struct {
+			Version   string                        `yaml:"Version"`
+			Statement []cloudFormationTestStatement `yaml:"Statement"`
+		} `yaml:"PolicyDocument"`
+	} `yaml:"Properties"`
+}
+
+type cloudFormationTestTemplate struct {
+	AWSTemplateFormatVersion string `yaml:"AWSTemplateFormatVersion"`
+	Description              string `yaml:"Description"`
+	Parameters               map[string]struct {
+		Type    string `yaml:"Type"`
+		Default string `yaml:"Default"`
+	} `yaml:"Parameters"`
+	Resources yaml.Node `yaml:"Resources"`
+}
+
+// parseCloudFormationTestOutput parses the template, returning its resources in order
+func parseCloudFormationTestOutput(t *testing.T, output []byte) (cloudFormationTestTemplate, []string, []cloudFormationTestResource) {
+	t.Helper()
+
+	var template cloudFormationTestTemplate
+	if err := yaml.Unmarshal(output, &template); err != nil {
+		t.Fatalf("the output is not valid YAML: %v\n%s", err, output)
+	}
+
+	var logicalIDs []string
+	var resources []cloudFormationTestResource
+	for i := 0; i+1 < len(template.Resources.Content); i += 2 {
+		var resource cloudFormationTestResource
+		if err := template.Resources.Content[i+1].Decode(&resource); err != nil {
+			t.Fatal(err)
+		}
+		logicalIDs = append(logicalIDs, template.Resources.Content[i].Value)
+		resources = append(resources, resource)
+	}
+
+	return template, logicalIDs, resources
+}
+
+func getCloudFormationTestActions(resource cloudFormationTestResource) []string {
+	var actions []string
+	for _, statement := range resource.Properties.PolicyDocument.Statement {
+		actions = append(actions, statement.Action...)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+func TestCloudFormationOutput(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+
+	template, logicalIDs, resources := parseCloudFormationTestOutput(t, getCloudFormationOutput())
+
+	if template.AWSTemplateFormatVersion != "2010-09-09" {
+		t.Errorf("got template version %s, want 2010-09-09", template.AWSTemplateFormatVersion)
+	}
+	if !strings.Contains(template.Description, "from calls captured between") {
+		t.Errorf("the description has no capture time range: %s", template.Description)
+	}
+	if parameter := template.Parameters["PolicyName"]; parameter.Type != "String" || parameter.Default != "iamlive-policy" {
+		t.Errorf("got PolicyName parameter %+v, want a String defaulting to iamlive-policy", parameter)
+	}
+	if !reflect.DeepEqual(logicalIDs, []string{"IamlivePolicy"}) {
+		t.Fatalf("got resources %v, want IamlivePolicy", logicalIDs)
+	}
+
+	resource := resources[0]
+	if resource.Type != "AWS::IAM::ManagedPolicy" {
+		t.Errorf("got type %s, want AWS::IAM::ManagedPolicy", resource.Type)
+	}
+	if name := resource.Properties.ManagedPolicyName; name.Tag != "!Ref" || name.Value != "PolicyName" {
+		t.Errorf("got ManagedPolicyName %s %s, want !Ref PolicyName", name.Tag, name.Value)
+	}
+	if version := resource.Properties.PolicyDocument.Version; version != "2012-10-17" {
+		t.Errorf("got policy version %s, want 2012-10-17", version)
+	}
+
+	wantStatements := []cloudFormationTestStatement{
+		{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []interface{}{"arn:aws:s3:::a/k", "arn:aws:s3:::b/k", "arn:aws:s3:::c/k"}},
+		{Effect: "Allow", Action: []string{"dynamodb:GetItem"}, Resource: []interface{}{"arn:aws:dynamodb:us-east-1:*:table/a", "arn:aws:dynamodb:us-east-1:*:table/b", "arn:aws:dynamodb:us-east-1:*:table/c"}},
+	}
+	if got := resource.Properties.PolicyDocument.Statement; !reflect.DeepEqual(got, wantStatements) {
+		t.Errorf("got statements %+v, want %+v", got, wantStatements)
+	}
+}
+
+func TestCloudFormationOutputStatements(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		calls       []Entry
+		wantActions []string
+		wantSid     string
+	}{
+		{
+			name:        "no calls",
+			wantActions: nil,
+		},
+		{
+			name:        "single action",
+			calls:       []Entry{{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200}},
+			wantActions: []string{"s3:ListAllMyBuckets"},
+		},
+		{
+			name: "multiple services",
+			calls: []Entry{
+				{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200},
+				{Region: "us-east-1", Type: "ApiCall", Service: "EC2", Method: "DescribeInstances", FinalHTTPStatusCode: 200},
+			},
+			wantActions: []string{"ec2:DescribeInstances", "s3:ListAllMyBuckets"},
+		},
+		{
+			name:        "sids",
+			flags:       map[string]string{"iam-role-name": "orders-role"},
+			calls:       []Entry{{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200}},
+			wantActions: []string{"s3:ListAllMyBuckets"},
+			wantSid:     "ordersrole1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+			for _, call := range tt.calls {
+				call.Timestamp = time.Now()
+				callLog.Append(call)
+			}
+
+			_, _, resources := parseCloudFormationTestOutput(t, getCloudFormationOutput())
+			if len(resources) != 1 {
+				t.Fatalf("got %d resources, want 1", len(resources))
+			}
+			if got := getCloudFormationTestActions(resources[0]); !reflect.DeepEqual(got, tt.wantActions) {
+				t.Errorf("got actions %v, want %v", got, tt.wantActions)
+			}
+			if tt.wantSid != "" {
+				if got := resources[0].Properties.PolicyDocument.Statement[0].Sid; got != tt.wantSid {
+					t.Errorf("got sid %s, want %s", got, tt.wantSid)
+				}
+			}
+		})
+	}
+}
+
+func TestCloudFormationOutputSplit(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "max-policy-size", "250")
+	setTestFlag(t, "cfn-stack-name", "orders-service")
+	appendTestResourceCalls()
+
+	output := getCloudFormationOutput()
+	template, logicalIDs, resources := parseCloudFormationTestOutput(t, output)
+
+	if !reflect.DeepEqual(logicalIDs, []string{"OrdersServicePolicy1", "OrdersServicePolicy2"}) {
+		t.Fatalf("got resources %v, want OrdersServicePolicy1 and OrdersServicePolicy2", logicalIDs)
+	}
+	if comment := template.Resources.Content[0].HeadComment; !strings.Contains(comment, "split into 2 policies") {
+		t.Errorf("got comment %q, want one explaining the split", comment)
+	}
+	if got := template.Parameters["PolicyName"].Default; got != "ordersservice-policy" {
+		t.Errorf("got default policy name %s, want ordersservice-policy", got)
+	}
+
+	wantActions := [][]string{{"s3:GetObject"}, {"dynamodb:GetItem"}}
+	for i, resource := range resources {
+		if name := resource.Properties.ManagedPolicyName; name.Tag != "!Sub" || name.Value != fmt.Sprintf("${PolicyName}-%d", i+1) {
+			t.Errorf("got ManagedPolicyName %s %s for policy %d", name.Tag, name.Value, i+1)
+		}
+		if got := getCloudFormationTestActions(resource); !reflect.DeepEqual(got, wantActions[i]) {
+			t.Errorf("got actions %v for policy %d, want %v", got, i+1, wantActions[i])
+		}
+	}
+}
+
+func TestCloudFormationPolicyDocumentQuoting(t *testing.T) {
+	statements := []Statement{
+		{Sid: "Quote", Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{`arn:aws:s3:::bucket/a: "b" #c`, "arn:aws:s3:::bucket/*"}},
+		{Effect: "Allow", Action: []string{"s3:ListAllMyBuckets"}, Resource: "*"},
+	}
+
+	doc, err := yaml.Marshal(getCloudFormationPolicyDocument(statements))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		Statement []cloudFormationTestStatement `yaml:"Statement"`
+	}
+	if err := yaml.Unmarshal(doc, &parsed); err != nil {
+		t.Fatalf("the policy document is not valid YAML: %v\n%s", err, doc)
+	}
+	want := []cloudFormationTestStatement{
+		{Sid: "Quote", Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []interface{}{`arn:aws:s3:::bucket/a: "b" #c`, "arn:aws:s3:::bucket/*"}},
+		{Effect: "Allow", Action: []string{"s3:ListAllMyBuckets"}, Resource: "*"},
+	}
+	if !reflect.DeepEqual(parsed.Statement, want) {
+		t.Errorf("got statements %+v, want %+v\n%s", parsed.Statement, want, doc)
+	}
+}
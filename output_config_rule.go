@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const configRuleLambdaSource = `
+config = boto3.client("config")
+
+
+def get_allowed_actions(policy_document):
+    statements = policy_document.get("Statement", [])
+    if isinstance(statements, dict):
+        statements = [statements]
+    for statement in statements:
+        if statement.get("Effect") != "Allow":
+            continue
+        actions = statement.get("Action", [])
+        if isinstance(actions, str):
+            actions = [actions]
+        for action in actions:
+            yield action
+
+
+def get_configuration_item(invoking_event):
+    if invoking_event["messageType"] != "OversizedConfigurationItemChangeNotification":
+        return invoking_event["configurationItem"]
+
+    summary = invoking_event["configurationItemSummary"]
+    item = config.get_resource_config_history(
+        resourceType=summary["resourceType"], resourceId=summary["resourceId"], limit=1
+    )["configurationItems"][0]
+    return {
+        "resourceType": item["resourceType"],
+        "resourceId": item["resourceId"],
+        "configurationItemStatus": item["configurationItemStatus"],
+        "configurationItemCaptureTime": item["configurationItemCaptureTime"],
+        "configuration": json.loads(item["configuration"]),
+    }
+
+
+def evaluate(configuration_item):
+    if configuration_item["resourceType"] != "AWS::IAM::Policy":
+        return "NOT_APPLICABLE", None
+    if configuration_item["configurationItemStatus"] == "ResourceDeleted":
+        return "NOT_APPLICABLE", None
+
+    for version in configuration_item["configuration"].get("policyVersionList", []):
+        if not version.get("isDefaultVersion"):
+            continue
+        document = json.loads(urllib.parse.unquote(version["document"]))
+        unobserved = sorted(set(action for action in get_allowed_actions(document) if action.lower() not in OBSERVED_ACTIONS))
+        if unobserved:
+            return "NON_COMPLIANT", "Actions not observed by iamlive: " + ", ".join(unobserved)
+        return "COMPLIANT", None
+
+    return "NOT_APPLICABLE", None
+
+
+def lambda_handler(event, context):
+    configuration_item = get_configuration_item(json.loads(event["invokingEvent"]))
+    compliance, annotation = evaluate(configuration_item)
+
+    evaluation = {
+        "ComplianceResourceType": configuration_item["resourceType"],
+        "ComplianceResourceId": configuration_item["resourceId"],
+        "ComplianceType": compliance,
+        "OrderingTimestamp": configuration_item["configurationItemCaptureTime"],
+    }
+    if annotation:
+        evaluation["Annotation"] = annotation[:256]
+
+    config.put_evaluations(Evaluations=[evaluation], ResultToken=event["resultToken"])
+`
+
+const configRuleTemplate = `AWSTemplateFormatVersion: "2010-09-09"
+Description: AWS Config rule checking that IAM policies only allow the actions observed by iamlive
+Resources:
+  RuleFunctionRole:
+    Type: AWS::IAM::Role
+    Properties:
+      AssumeRolePolicyDocument:
+        Version: "2012-10-17"
+        Statement:
+          - Effect: Allow
+            Principal:
+              Service: lambda.amazonaws.com
+            Action: sts:AssumeRole
+      ManagedPolicyArns:
+        - !Sub arn:${AWS::Partition}:iam::aws:policy/service-role/AWSConfigRulesExecutionRole
+        - !Sub arn:${AWS::Partition}:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole
+  RuleFunction:
+    Type: AWS::Lambda::Function
+    Properties:
+      Runtime: python3.12
+      Handler: index.lambda_handler
+      Timeout: 60
+      Role: !GetAtt RuleFunctionRole.Arn
+      Code:
+        ZipFile: |
+%s
+  RuleFunctionPermission:
+    Type: AWS::Lambda::Permission
+    Properties:
+      FunctionName: !GetAtt RuleFunction.Arn
+      Action: lambda:InvokeFunction
+      Principal: config.amazonaws.com
+      SourceAccount: !Ref AWS::AccountId
+  ConfigRule:
+    Type: AWS::Config::ConfigRule
+    DependsOn: RuleFunctionPermission
+    Properties:
+      ConfigRuleName: iamlive-observed-actions
+      Scope:
+        ComplianceResourceTypes:
+          - AWS::IAM::Policy
+      Source:
+        Owner: CUSTOM_LAMBDA
+        SourceIdentifier: !GetAtt RuleFunction.Arn
+        SourceDetails:
+          - EventSource: aws.config
+            MessageType: ConfigurationItemChangeNotification
+          - EventSource: aws.config
+            MessageType: OversizedConfigurationItemChangeNotification
+`
+
+// getConfigRuleLambdaSource renders the Python source of a Config rule checking policies against the observed actions
+func getConfigRuleLambdaSource() []byte {
+	var sb strings.Builder
+	sb.WriteString("import json\n")
+	sb.WriteString("import urllib.parse\n\n")
+	sb.WriteString("import boto3\n\n")
+	sb.WriteString("# Generated by iamlive from the observed AWS calls\n")
+	sb.WriteString("OBSERVED_ACTIONS = set([\n")
+	for _, action := range getCapturedActions() {
+		sb.WriteString(fmt.Sprintf("    %q,\n", strings.ToLower(action)))
+	}
+	sb.WriteString("])\n")
+	sb.WriteString(configRuleLambdaSource)
+
+	return []byte(sb.String())
+}
+
+// getConfigRuleOutput renders a CloudFormation template deploying the Config rule with its source inline
+func getConfigRuleOutput() []byte {
+	source := strings.TrimRight(string(getConfigRuleLambdaSource()), "\n")
+
+	var indented []string
+	for _, line := range strings.Split(source, "\n") {
+		if line == "" {
+			indented = append(indented, "")
+		} else {
+			indented = append(indented, "          "+line)
+		}
+	}
+
+	return []byte(fmt.Sprintf(configRuleTemplate, strings.Join(indented, "\n")))
+}
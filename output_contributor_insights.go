@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// ContributorInsightsRule is the input of the PutInsightRule API
+type ContributorInsightsRule struct {
+	RuleName       string `json:"RuleName"`
+	RuleState      string `json:"RuleState"`
+	RuleDefinition string `json:"RuleDefinition"`
+}
+
+type contributorInsightsRuleBody struct {
+	Schema struct {
+		Name    string `json:"Name"`
+		Version int    `json:"Version"`
+	} `json:"Schema"`
+	LogGroupNames []string                        `json:"LogGroupNames"`
+	LogFormat     string                          `json:"LogFormat"`
+	Contribution  contributorInsightsContribution `json:"Contribution"`
+	AggregateOn   string                          `json:"AggregateOn"`
+}
+
+type contributorInsightsContribution struct {
+	Keys    []string                    `json:"Keys"`
+	Filters []contributorInsightsFilter `json:"Filters"`
+}
+
+type contributorInsightsFilter struct {
+	Match string   `json:"Match"`
+	In    []string `json:"In"`
+}
+
+// getContributorInsightsOutput renders a Contributor Insights rule counting the CloudTrail events of the observed
+// calls in --cw-log-group-name by event source and name, as input for aws cloudwatch put-insight-rule
+func getContributorInsightsOutput() []byte {
+	var eventSources []string
+	var eventNames []string
+	for _, entry := range getPolicyEntries() {
+		if entry.Method == "*" {
+			continue
+		}
+		eventSources = append(eventSources, getCallEventSource(entry)+".amazonaws.com")
+		eventNames = append(eventNames, entry.Method)
+	}
+	eventSources = uniqueSlice(eventSources)
+	sort.Strings(eventSources)
+	eventNames = uniqueSlice(eventNames)
+	sort.Strings(eventNames)
+
+	var body contributorInsightsRuleBody
+	body.Schema.Name = "CloudWatchLogRule"
+	body.Schema.Version = 1
+	body.LogGroupNames = []string{*cwLogGroupNameFlag}
+	body.LogFormat = "JSON"
+	body.Contribution = contributorInsightsContribution{
+		Keys: []string{"$.eventSource", "$.eventName"},
+		Filters: []contributorInsightsFilter{
+			{Match: "$.eventSource", In: eventSources},
+			{Match: "$.eventName", In: eventNames},
+		},
+	}
+	body.AggregateOn = "Count"
+
+	ruleDefinition, err := json.Marshal(body)
+	if err != nil {
+		panic(err)
+	}
+
+	doc, err := json.MarshalIndent(ContributorInsightsRule{
+		RuleName:       *cwRuleNameFlag,
+		RuleState:      "ENABLED",
+		RuleDefinition: string(ruleDefinition),
+	}, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getCopilotOutput renders a markdown prompt asking an assistant such as GitHub Copilot to explain the policy
+func getCopilotOutput() []byte {
+	capturedActions := getCapturedActions()
+	actionCounts := make(map[string]int)
+	for _, action := range capturedActions {
+		actionCounts[strings.SplitN(action, ":", 2)[0]]++
+	}
+
+	callCounts := make(map[string]int)
+	totalCalls := 0
+	for _, entry := range callLog.Snapshot() {
+		totalCalls++
+		actions := getActions(entry.Service, entry.Method)
+		if len(actions) > 0 {
+			callCounts[strings.SplitN(actions[0], ":", 2)[0]]++
+		}
+	}
+
+	var services []string
+	for service := range actionCounts {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	var sb strings.Builder
+	sb.WriteString("The following AWS IAM policy was inferred from captured API calls. Please explain what this application does based on its permissions, and point out any permissions that look broader than the application needs.\n\n")
+	sb.WriteString("## Policy\n\n")
+	sb.WriteString("```json\n")
+	sb.WriteString(string(getPolicyDocument()))
+	sb.WriteString("\n```\n\n")
+	sb.WriteString("## Services\n\n")
+	sb.WriteString("| Service | Actions | Calls |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for _, service := range services {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d |\n", service, actionCounts[service], callCounts[service]))
+	}
+	sb.WriteString("\n## Statistics\n\n")
+	sb.WriteString(fmt.Sprintf("- Captured calls: %d\n", totalCalls))
+	sb.WriteString(fmt.Sprintf("- Unique actions: %d\n", len(capturedActions)))
+	sb.WriteString(fmt.Sprintf("- Services: %d\n", len(services)))
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// getEnvOutput renders the policy as a shell export, suitable for eval $(iamlive ...)
+func getEnvOutput(policyDoc []byte) []byte {
+	compactDoc := new(bytes.Buffer)
+	if err := json.Compact(compactDoc, policyDoc); err != nil {
+		panic(err)
+	}
+
+	escapedDoc := strings.ReplaceAll(compactDoc.String(), "'", `'\''`)
+	return []byte(fmt.Sprintf("export %s='%s'\n", *envVarNameFlag, escapedDoc))
+}
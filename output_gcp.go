@@ -0,0 +1,81 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed gcp-mapping.json
+var bGCPMapping []byte
+
+// GCPRole is a GCP IAM custom role definition
+type GCPRole struct {
+	Title               string   `json:"title"`
+	Description         string   `json:"description"`
+	Stage               string   `json:"stage"`
+	IncludedPermissions []string `json:"includedPermissions"`
+}
+
+func getGCPMapping() map[string][]string {
+	var mapping map[string][]string
+	if err := json.Unmarshal(bGCPMapping, &mapping); err != nil {
+		panic(err)
+	}
+
+	lowerMapping := make(map[string][]string)
+	for action, permissions := range mapping {
+		lowerMapping[strings.ToLower(action)] = permissions
+	}
+	return lowerMapping
+}
+
+// getGCPPermissions returns the GCP permissions approximately equivalent to the captured actions, and the
+// actions which have no mapping
+func getGCPPermissions() ([]string, []string) {
+	mapping := getGCPMapping()
+
+	var permissions []string
+	var unmapped []string
+	for _, action := range getCapturedActions() {
+		if mappedPermissions, ok := mapping[strings.ToLower(action)]; ok {
+			permissions = append(permissions, mappedPermissions...)
+		} else {
+			unmapped = append(unmapped, action)
+		}
+	}
+
+	permissions = uniqueSlice(permissions)
+	sort.Strings(permissions)
+	return permissions, unmapped
+}
+
+func getGCPIAMOutput() []byte {
+	permissions, _ := getGCPPermissions()
+
+	role := GCPRole{
+		Title:               "iamlive",
+		Description:         "Generated by iamlive from observed AWS calls. Permissions are approximate equivalents of the AWS actions and must be reviewed before use.",
+		Stage:               "ALPHA",
+		IncludedPermissions: permissions,
+	}
+
+	doc, err := json.MarshalIndent(role, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func getGCPIAMNotes() []string {
+	notes := []string{"WARNING: GCP permissions are approximate equivalents of the AWS actions and must be reviewed"}
+
+	_, unmapped := getGCPPermissions()
+	for _, action := range unmapped {
+		notes = append(notes, fmt.Sprintf("WARNING: %s has no GCP equivalent mapped", action))
+	}
+
+	return notes
+}
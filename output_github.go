@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const githubOIDCProvider = "token.actions.githubusercontent.com"
+
+// TrustPolicyStatement is a single statement within an IAM role trust policy
+type TrustPolicyStatement struct {
+	Effect    string                       `json:"Effect"`
+	Principal map[string]string            `json:"Principal"`
+	Action    string                       `json:"Action"`
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
+}
+
+// TrustPolicy is a full IAM role trust policy
+type TrustPolicy struct {
+	Version   string                 `json:"Version"`
+	Statement []TrustPolicyStatement `json:"Statement"`
+}
+
+func getGitHubOIDCTrustPolicy() []byte {
+	subject := fmt.Sprintf("repo:%s:*", *githubRepoFlag)
+	if *githubBranchFlag != "" {
+		subject = fmt.Sprintf("repo:%s:ref:refs/heads/%s", *githubRepoFlag, *githubBranchFlag)
+	}
+
+	policy := TrustPolicy{
+		Version: "2012-10-17",
+		Statement: []TrustPolicyStatement{
+			{
+				Effect: "Allow",
+				Principal: map[string]string{
+					"Federated": fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", *accountIDFlag, githubOIDCProvider),
+				},
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Condition: map[string]map[string]string{
+					"StringEquals": {
+						githubOIDCProvider + ":aud": "sts.amazonaws.com",
+					},
+					"StringLike": {
+						githubOIDCProvider + ":sub": subject,
+					},
+				},
+			},
+		},
+	}
+
+	doc, err := json.MarshalIndent(policy, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
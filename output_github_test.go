@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func getTestTrustPolicy(t *testing.T) TrustPolicy {
+	t.Helper()
+
+	var trust []byte
+	for _, output := range getFormatAdditionalOutputs("github-oidc") {
+		if output.Suffix == "-trust" {
+			trust = output.Output
+		}
+	}
+	if trust == nil {
+		t.Fatal("the github-oidc output format has no -trust document")
+	}
+
+	var policy TrustPolicy
+	if err := json.Unmarshal(trust, &policy); err != nil {
+		t.Fatalf("the trust policy is not valid JSON: %v\n%s", err, trust)
+	}
+	if len(policy.Statement) != 1 {
+		t.Fatalf("got %d trust policy statements, want 1", len(policy.Statement))
+	}
+	return policy
+}
+
+func TestGitHubOIDCTrustPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		branch        string
+		redact        string
+		wantFederated string
+		wantSubject   string
+	}{
+		{
+			name:          "any ref",
+			redact:        "false",
+			wantFederated: "arn:aws:iam::210987654321:oidc-provider/token.actions.githubusercontent.com",
+			wantSubject:   "repo:octo-org/orders:*",
+		},
+		{
+			name:          "branch",
+			branch:        "main",
+			redact:        "false",
+			wantFederated: "arn:aws:iam::210987654321:oidc-provider/token.actions.githubusercontent.com",
+			wantSubject:   "repo:octo-org/orders:ref:refs/heads/main",
+		},
+		{
+			name:          "redacted account",
+			redact:        "true",
+			wantFederated: "arn:aws:iam::XXXXXXXXXXXX:oidc-provider/token.actions.githubusercontent.com",
+			wantSubject:   "repo:octo-org/orders:*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestFlag(t, "account-id", "210987654321")
+			setTestFlag(t, "github-repo", "octo-org/orders")
+			setTestFlag(t, "github-branch", tt.branch)
+			setTestFlag(t, "redact-account-id", tt.redact)
+
+			statement := getTestTrustPolicy(t).Statement[0]
+			if statement.Action != "sts:AssumeRoleWithWebIdentity" {
+				t.Errorf("got action %s, want sts:AssumeRoleWithWebIdentity", statement.Action)
+			}
+			if got := statement.Principal["Federated"]; got != tt.wantFederated {
+				t.Errorf("got federated principal %s, want %s", got, tt.wantFederated)
+			}
+			if got := statement.Condition["StringEquals"]["token.actions.githubusercontent.com:aud"]; got != "sts.amazonaws.com" {
+				t.Errorf("got audience %s, want sts.amazonaws.com", got)
+			}
+			if got := statement.Condition["StringLike"]["token.actions.githubusercontent.com:sub"]; got != tt.wantSubject {
+				t.Errorf("got subject %s, want %s", got, tt.wantSubject)
+			}
+		})
+	}
+}
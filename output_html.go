@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed assets/report.html
+var bHTMLReportTemplate string
+
+//go:embed assets/report.js
+var bHTMLReportScript string
+
+//go:embed assets/chartjs.min.js
+var bHTMLReportChartJS string
+
+// htmlTimelineBucketSize is the length of time each bar of the report timeline covers
+const htmlTimelineBucketSize = 10 * time.Second
+
+// htmlTimelineBarWidth is the width in pixels the timeline allows for each bar, so that long sessions scroll rather
+// than squeezing the bars together
+const htmlTimelineBarWidth = 12
+
+// htmlGraphSize is the width and height in pixels of the service interaction graph, and htmlGraphNodeRadius the
+// radius of each service in it
+const htmlGraphSize = 420
+const htmlGraphNodeRadius = 40
+
+// htmlServiceColours are the colours of the services in the charts, reused when there are more services
+var htmlServiceColours = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+// htmlJSONTokenRegexp matches the strings, with the colon that follows an object key, and the literals of a JSON
+// document
+var htmlJSONTokenRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"(\s*:)?|\btrue\b|\bfalse\b|\bnull\b|-?[0-9]+(?:\.[0-9]+)?`)
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(bHTMLReportTemplate))
+
+type htmlService struct {
+	Name   string
+	Colour string
+}
+
+// htmlTimelineDataset is the calls to a service in each bar of the timeline, as a Chart.js dataset
+type htmlTimelineDataset struct {
+	Label           string `json:"label"`
+	BackgroundColor string `json:"backgroundColor"`
+	Data            []int  `json:"data"`
+}
+
+// htmlTimeline is the data of the Chart.js timeline, with the details shown beneath it
+type htmlTimeline struct {
+	Labels     []string              `json:"labels"`
+	Datasets   []htmlTimelineDataset `json:"datasets"`
+	Width      int                   `json:"-"`
+	MaxCount   int                   `json:"-"`
+	BucketSize time.Duration         `json:"-"`
+	Start      string                `json:"-"`
+}
+
+type htmlGraphNode struct {
+	Name   string
+	X      int
+	Y      int
+	Radius int
+	Colour string
+}
+
+type htmlGraphEdge struct {
+	X1    int
+	Y1    int
+	X2    int
+	Y2    int
+	Width int
+	Title string
+}
+
+type htmlGraph struct {
+	Nodes []htmlGraphNode
+	Edges []htmlGraphEdge
+	Size  int
+}
+
+type htmlRoleChain struct {
+	RoleARN  string
+	Children []htmlRoleChain
+}
+
+type htmlReport struct {
+	Title             string
+	Generated         string
+	UniqueActionCount int
+	Calls             []Entry
+	Timeline          htmlTimeline
+	ServiceGraph      htmlGraph
+	RoleChains        []htmlRoleChain
+	Policy            template.HTML
+	ChartJS           template.JS
+	Script            template.JS
+}
+
+// getHTMLServices returns the services called, in the order of their first call, each with its colour in the charts
+func getHTMLServices(entries []Entry) []htmlService {
+	var services []htmlService
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if seen[entry.Service] {
+			continue
+		}
+		seen[entry.Service] = true
+		services = append(services, htmlService{
+			Name:   entry.Service,
+			Colour: htmlServiceColours[len(services)%len(htmlServiceColours)],
+		})
+	}
+
+	return services
+}
+
+// getHTMLTimeline stacks the calls to each service in bars of 10 seconds from the first call, including the bars
+// without calls so that the timeline is to scale
+func getHTMLTimeline(entries []Entry) htmlTimeline {
+	timeline := htmlTimeline{
+		BucketSize: htmlTimelineBucketSize,
+	}
+
+	var start time.Time
+	var timedEntries []Entry
+	bucketCounts := make(map[int]map[string]int)
+	lastBucket := 0
+	for _, entry := range entries {
+		if entry.Timestamp.IsZero() {
+			continue // calls replayed from older logs may have no timestamp
+		}
+		if start.IsZero() {
+			start = entry.Timestamp
+		}
+		timedEntries = append(timedEntries, entry)
+
+		bucket := int(entry.Timestamp.Sub(start) / htmlTimelineBucketSize)
+		if bucketCounts[bucket] == nil {
+			bucketCounts[bucket] = make(map[string]int)
+		}
+		bucketCounts[bucket][entry.Service]++
+		lastBucket = bucket
+	}
+	if start.IsZero() {
+		return timeline
+	}
+
+	for bucket := 0; bucket <= lastBucket; bucket++ {
+		timeline.Labels = append(timeline.Labels, start.Add(time.Duration(bucket)*htmlTimelineBucketSize).Format("15:04:05"))
+
+		total := 0
+		for _, count := range bucketCounts[bucket] {
+			total += count
+		}
+		if total > timeline.MaxCount {
+			timeline.MaxCount = total
+		}
+	}
+
+	for _, service := range getHTMLServices(timedEntries) {
+		dataset := htmlTimelineDataset{
+			Label:           service.Name,
+			BackgroundColor: service.Colour,
+			Data:            make([]int, lastBucket+1),
+		}
+		for bucket := range dataset.Data {
+			dataset.Data[bucket] = bucketCounts[bucket][service.Name]
+		}
+		timeline.Datasets = append(timeline.Datasets, dataset)
+	}
+
+	timeline.Width = len(timeline.Labels) * htmlTimelineBarWidth
+	timeline.Start = start.Format(time.RFC3339)
+	return timeline
+}
+
+// getHTMLServiceGraph places the services called around a circle, linking each service to the services that the
+// same caller called next, so that the report shows how the workload moved between services. Calls without a known
+// caller, such as those captured in CSM mode, are taken to be made by the same caller.
+func getHTMLServiceGraph(entries []Entry) htmlGraph {
+	graph := htmlGraph{
+		Size: htmlGraphSize,
+	}
+	services := getHTMLServices(entries)
+	if len(services) == 0 {
+		return graph
+	}
+
+	centre := float64(htmlGraphSize) / 2
+	orbit := centre - htmlGraphNodeRadius - 4
+	if len(services) == 1 {
+		orbit = 0
+	}
+	positions := make(map[string][2]float64)
+	for i, service := range services {
+		angle := 2*math.Pi*float64(i)/float64(len(services)) - math.Pi/2
+		x, y := centre+orbit*math.Cos(angle), centre+orbit*math.Sin(angle)
+		positions[service.Name] = [2]float64{x, y}
+		graph.Nodes = append(graph.Nodes, htmlGraphNode{
+			Name:   service.Name,
+			X:      int(math.Round(x)),
+			Y:      int(math.Round(y)),
+			Radius: htmlGraphNodeRadius,
+			Colour: service.Colour,
+		})
+	}
+
+	type serviceLink struct {
+		from string
+		to   string
+	}
+	linkCounts := make(map[serviceLink]int)
+	var links []serviceLink
+	lastServices := make(map[string]string) // keyed by caller
+	for _, entry := range entries {
+		lastService, ok := lastServices[entry.CallerAccessKeyID]
+		lastServices[entry.CallerAccessKeyID] = entry.Service
+		if !ok || lastService == entry.Service {
+			continue
+		}
+
+		link := serviceLink{from: lastService, to: entry.Service}
+		if linkCounts[link] == 0 {
+			links = append(links, link)
+		}
+		linkCounts[link]++
+	}
+
+	maxCount := 0
+	for _, count := range linkCounts {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	for _, link := range links {
+		from, to := positions[link.from], positions[link.to]
+		dx, dy := to[0]-from[0], to[1]-from[1]
+		length := math.Hypot(dx, dy)
+		ux, uy := dx/length, dy/length
+		// shift each link to its right, so that the links in both directions between two services are apart
+		ox, oy := -uy*5, ux*5
+
+		count := linkCounts[link]
+		calls := "times"
+		if count == 1 {
+			calls = "time"
+		}
+		graph.Edges = append(graph.Edges, htmlGraphEdge{
+			X1:    int(math.Round(from[0] + ux*htmlGraphNodeRadius + ox)),
+			Y1:    int(math.Round(from[1] + uy*htmlGraphNodeRadius + oy)),
+			X2:    int(math.Round(to[0] - ux*(htmlGraphNodeRadius+2) + ox)),
+			Y2:    int(math.Round(to[1] - uy*(htmlGraphNodeRadius+2) + oy)),
+			Width: 1 + 4*count/maxCount,
+			Title: fmt.Sprintf("%s to %s: %d %s", link.from, link.to, count, calls),
+		})
+	}
+
+	return graph
+}
+
+// getHTMLRoleChains returns the roles recorded by --chain-roles as trees, from the roles assumed with other
+// credentials to the roles their sessions assumed
+func getHTMLRoleChains() []htmlRoleChain {
+	parents := getRoleChainParents()
+	children := make(map[string][]string)
+	var roots []string
+	for role, parent := range parents {
+		if _, ok := parents[parent]; parent == "" || !ok {
+			roots = append(roots, role)
+		} else {
+			children[parent] = append(children[parent], role)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var build func(roles []string) []htmlRoleChain
+	build = func(roles []string) []htmlRoleChain {
+		sort.Strings(roles)
+		var chains []htmlRoleChain
+		for _, role := range roles {
+			if seen[role] {
+				continue
+			}
+			seen[role] = true
+			chains = append(chains, htmlRoleChain{
+				RoleARN:  role,
+				Children: build(children[role]),
+			})
+		}
+		return chains
+	}
+
+	return build(roots)
+}
+
+// highlightJSON escapes a JSON document for HTML, wrapping its keys, strings and literals in spans to be coloured
+func highlightJSON(doc []byte) template.HTML {
+	var sb strings.Builder
+	text := string(doc)
+	last := 0
+	for _, match := range htmlJSONTokenRegexp.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(html.EscapeString(text[last:match[0]]))
+
+		token := text[match[0]:match[1]]
+		class := "literal"
+		if match[2] >= 0 {
+			class = "key"
+			token = text[match[0]:match[2]]
+		} else if strings.HasPrefix(token, `"`) {
+			class = "string"
+		}
+		sb.WriteString(`<span class="` + class + `">` + html.EscapeString(token) + `</span>`)
+		if match[2] >= 0 {
+			sb.WriteString(html.EscapeString(text[match[2]:match[3]]))
+		}
+
+		last = match[1]
+	}
+	sb.WriteString(html.EscapeString(text[last:]))
+
+	return template.HTML(sb.String())
+}
+
+// getHTMLOutput renders a self-contained report of the captured calls and the policy
+func getHTMLOutput() []byte {
+	entries := callLog.Snapshot()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	report := htmlReport{
+		Title:             *htmlTitleFlag,
+		Generated:         time.Now().Format(time.RFC3339),
+		UniqueActionCount: len(getCapturedActions()),
+		Calls:             entries,
+		Timeline:          getHTMLTimeline(entries),
+		ServiceGraph:      getHTMLServiceGraph(entries),
+		RoleChains:        getHTMLRoleChains(),
+		Policy:            highlightJSON(getPolicyDocument()),
+		ChartJS:           template.JS(bHTMLReportChartJS),
+		Script:            template.JS(bHTMLReportScript),
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, report); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testHTMLRowRegexp = regexp.MustCompile(`<tr><td data-sort="[0-9]+">([^<]*)</td><td>([^<]*)</td><td>([^<]*)</td><td>([^<]*)</td><td data-sort="[0-9]+">([^<]*)</td></tr>`)
+
+func TestHTMLOutputRows(t *testing.T) {
+	resetTestCallLog(t)
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	// appended out of order, as calls finishing at different times are
+	callLog.Append(Entry{Region: "eu-west-1", Type: "ApiCall", Service: "EC2", Method: "DescribeInstances", FinalHTTPStatusCode: 403, Timestamp: start.Add(5 * time.Second)})
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: start})
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "DynamoDB", Method: "ListTables", FinalHTTPStatusCode: 200, Timestamp: start.Add(12 * time.Second)})
+
+	output := string(getHTMLOutput())
+
+	var rows [][]string
+	for _, match := range testHTMLRowRegexp.FindAllStringSubmatch(output, -1) {
+		rows = append(rows, match[1:])
+	}
+	want := [][]string{
+		{"2024-03-01T10:00:00Z", "S3", "ListBuckets", "us-east-1", "200"},
+		{"2024-03-01T10:00:05Z", "EC2", "DescribeInstances", "eu-west-1", "403"},
+		{"2024-03-01T10:00:12Z", "DynamoDB", "ListTables", "us-east-1", "200"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows %v, want %v", rows, want)
+	}
+
+	// the failed EC2 call is listed, but is not in the policy
+	if !strings.Contains(output, `<div class="value">2</div><div class="label">Unique actions</div>`) || !strings.Contains(output, `<div class="value">3</div><div class="label">Total calls</div>`) {
+		t.Errorf("the summary cards do not count 2 actions and 3 calls")
+	}
+	if strings.Contains(output, "src=") || strings.Contains(output, "href=") {
+		t.Errorf("the report loads external assets")
+	}
+	if !strings.Contains(output, bHTMLReportChartJS) {
+		t.Errorf("the report does not include Chart.js")
+	}
+	if !strings.Contains(output, `var timeline = {"labels":["10:00:00","10:00:10"],"datasets":[{"label":"S3",`) {
+		t.Errorf("the report does not include the timeline data")
+	}
+	if !strings.Contains(output, "<title>S3 to EC2: 1 time</title>") || !strings.Contains(output, "<title>EC2 to DynamoDB: 1 time</title>") {
+		t.Errorf("the report does not include the service interactions")
+	}
+}
+
+func TestHTMLOutputEscaping(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "html-title", `Orders <script>alert("x")</script>`)
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "<b>ListBuckets</b>", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	output := string(getHTMLOutput())
+
+	if strings.Contains(output, `<script>alert`) || strings.Contains(output, "<b>ListBuckets") {
+		t.Errorf("the report does not escape the title and calls")
+	}
+	if !strings.Contains(output, "<h1>Orders &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</h1>") {
+		t.Errorf("the report has no escaped heading")
+	}
+}
+
+func TestGetHTMLTimeline(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Service: "S3", Timestamp: start},
+		{Service: "S3", Timestamp: start.Add(3 * time.Second)},
+		{Service: "EC2", Timestamp: start.Add(9 * time.Second)},
+		{Service: "EC2"}, // replayed without a timestamp
+		{Service: "S3", Timestamp: start.Add(25 * time.Second)},
+	}
+
+	timeline := getHTMLTimeline(entries)
+
+	if timeline.MaxCount != 3 {
+		t.Errorf("got a tallest bar of %d calls, want 3", timeline.MaxCount)
+	}
+	if timeline.Width != 3*htmlTimelineBarWidth {
+		t.Errorf("got width %d, want 3 buckets", timeline.Width)
+	}
+	if want := []string{"10:00:00", "10:00:10", "10:00:20"}; !reflect.DeepEqual(timeline.Labels, want) {
+		t.Errorf("got labels %v, want %v", timeline.Labels, want)
+	}
+	want := []htmlTimelineDataset{
+		{Label: "S3", BackgroundColor: htmlServiceColours[0], Data: []int{2, 0, 1}},
+		{Label: "EC2", BackgroundColor: htmlServiceColours[1], Data: []int{1, 0, 0}},
+	}
+	if !reflect.DeepEqual(timeline.Datasets, want) {
+		t.Errorf("got datasets %+v, want %+v", timeline.Datasets, want)
+	}
+
+	if empty := getHTMLTimeline(nil); len(empty.Labels) != 0 || empty.Width != 0 {
+		t.Errorf("got timeline %+v for no calls, want none", empty)
+	}
+}
+
+func TestGetHTMLServiceGraph(t *testing.T) {
+	// two callers interleaved, the second starting with EC2
+	entries := []Entry{
+		{Service: "S3", CallerAccessKeyID: "AKIDORDERS"},
+		{Service: "EC2", CallerAccessKeyID: "AKIDDEPLOY"},
+		{Service: "S3", CallerAccessKeyID: "AKIDORDERS"},
+		{Service: "DynamoDB", CallerAccessKeyID: "AKIDORDERS"},
+		{Service: "S3", CallerAccessKeyID: "AKIDDEPLOY"},
+		{Service: "S3", CallerAccessKeyID: "AKIDORDERS"},
+		{Service: "DynamoDB", CallerAccessKeyID: "AKIDORDERS"},
+	}
+
+	graph := getHTMLServiceGraph(entries)
+
+	var nodes []string
+	for _, node := range graph.Nodes {
+		nodes = append(nodes, node.Name)
+	}
+	if want := []string{"S3", "EC2", "DynamoDB"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("got services %v, want %v", nodes, want)
+	}
+	if first := graph.Nodes[0]; first.X != htmlGraphSize/2 || first.Y != htmlGraphNodeRadius+4 {
+		t.Errorf("got the first service at %d,%d, want it at the top", first.X, first.Y)
+	}
+
+	var edges []string
+	for _, edge := range graph.Edges {
+		edges = append(edges, fmt.Sprintf("%s (%d)", edge.Title, edge.Width))
+	}
+	want := []string{"S3 to DynamoDB: 2 times (5)", "EC2 to S3: 1 time (3)", "DynamoDB to S3: 1 time (3)"}
+	if !reflect.DeepEqual(edges, want) {
+		t.Errorf("got links %v, want %v", edges, want)
+	}
+
+	single := getHTMLServiceGraph([]Entry{{Service: "S3"}, {Service: "S3"}})
+	if len(single.Nodes) != 1 || single.Nodes[0].X != htmlGraphSize/2 || single.Nodes[0].Y != htmlGraphSize/2 || len(single.Edges) != 0 {
+		t.Errorf("got graph %+v for a single service, want it centred without links", single)
+	}
+}
+
+func TestHighlightJSON(t *testing.T) {
+	got := string(highlightJSON([]byte(`{"Resource": "<a>", "Count": 2, "Ok": true}`)))
+	want := `{<span class="key">&#34;Resource&#34;</span>: <span class="string">&#34;&lt;a&gt;&#34;</span>, <span class="key">&#34;Count&#34;</span>: <span class="literal">2</span>, <span class="key">&#34;Ok&#34;</span>: <span class="literal">true</span>}`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+var kubesealPublicKey *rsa.PublicKey
+
+func loadKubesealCert() error {
+	if !isOutputFormatSelected("kubeseal") || *kubesealCertFileFlag == "" {
+		return nil
+	}
+
+	certPath, err := homedir.Expand(*kubesealCertFileFlag)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM data found in %s", certPath)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("the sealed secrets certificate must contain an RSA public key")
+	}
+	kubesealPublicKey = publicKey
+
+	return nil
+}
+
+// sealValue encrypts a value the same way kubeseal does for the strict scope, with an RSA-OAEP
+// encrypted session key followed by the AES-GCM encrypted value
+func sealValue(publicKey *rsa.PublicKey, namespace, name string, plaintext []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	label := []byte(fmt.Sprintf("%s/%s", namespace, name))
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 2)
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+
+	zeroNonce := make([]byte, aead.NonceSize()) // the session key is never reused
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+func getKubesealOutput(policyDoc []byte) []byte {
+	namespace := *kubesealNamespaceFlag
+	name := *kubesealSecretNameFlag
+
+	var sb strings.Builder
+	if kubesealPublicKey == nil {
+		sb.WriteString("# seal with: kubeseal --format yaml < secret.yaml > sealedsecret.yaml\n")
+		sb.WriteString("apiVersion: v1\n")
+		sb.WriteString("kind: Secret\n")
+		sb.WriteString("metadata:\n")
+		sb.WriteString(fmt.Sprintf("  name: %s\n", name))
+		sb.WriteString(fmt.Sprintf("  namespace: %s\n", namespace))
+		sb.WriteString("type: Opaque\n")
+		sb.WriteString("data:\n")
+		sb.WriteString(fmt.Sprintf("  policy: %s\n", base64.StdEncoding.EncodeToString(policyDoc)))
+		return []byte(sb.String())
+	}
+
+	sealed, err := sealValue(kubesealPublicKey, namespace, name, policyDoc)
+	if err != nil {
+		panic(err)
+	}
+
+	sb.WriteString("apiVersion: bitnami.com/v1alpha1\n")
+	sb.WriteString("kind: SealedSecret\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString(fmt.Sprintf("  name: %s\n", name))
+	sb.WriteString(fmt.Sprintf("  namespace: %s\n", namespace))
+	sb.WriteString("spec:\n")
+	sb.WriteString("  encryptedData:\n")
+	sb.WriteString(fmt.Sprintf("    policy: %s\n", base64.StdEncoding.EncodeToString(sealed)))
+	sb.WriteString("  template:\n")
+	sb.WriteString("    metadata:\n")
+	sb.WriteString(fmt.Sprintf("      name: %s\n", name))
+	sb.WriteString(fmt.Sprintf("      namespace: %s\n", namespace))
+	sb.WriteString("    type: Opaque\n")
+	return []byte(sb.String())
+}
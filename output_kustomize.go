@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getKustomizePatchOutput renders a strategic merge patch annotating a ServiceAccount with the IAM role for IRSA
+func getKustomizePatchOutput() []byte {
+	var sb strings.Builder
+	sb.WriteString("apiVersion: v1\n")
+	sb.WriteString("kind: ServiceAccount\n")
+	sb.WriteString("metadata:\n")
+	sb.WriteString(fmt.Sprintf("  name: %s\n", *serviceAccountNameFlag))
+	sb.WriteString(fmt.Sprintf("  namespace: %s\n", *serviceAccountNamespaceFlag))
+	sb.WriteString("  annotations:\n")
+	sb.WriteString(fmt.Sprintf("    eks.amazonaws.com/role-arn: %s\n", *roleARNFlag))
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type openAPIDocument struct {
+	OpenAPI string                            `yaml:"openapi"`
+	Info    openAPIInfo                       `yaml:"info"`
+	Tags    []openAPITag                      `yaml:"tags,omitempty"`
+	Paths   map[string]map[string]interface{} `yaml:"paths"`
+}
+
+type openAPIInfo struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+type openAPITag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type openAPIServer struct {
+	URL string `yaml:"url"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Summary     string                     `yaml:"summary"`
+	Tags        []string                   `yaml:"tags"`
+	Deprecated  bool                       `yaml:"deprecated,omitempty"`
+	Parameters  []openAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `yaml:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string        `yaml:"name"`
+	In       string        `yaml:"in"`
+	Required bool          `yaml:"required,omitempty"`
+	Schema   openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Type       string                   `yaml:"type"`
+	Enum       []string                 `yaml:"enum,omitempty"`
+	Properties map[string]openAPISchema `yaml:"properties,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema openAPISchema `yaml:"schema"`
+}
+
+type openAPIResponse struct {
+	Description string `yaml:"description"`
+}
+
+// observedOperation collects the calls made to a single operation of a service
+type observedOperation struct {
+	serviceDef ServiceDefinition
+	name       string
+	regions    map[string]bool
+	params     map[string]bool
+}
+
+// getServiceDefinitionByID returns the latest service definition with a service ID (e.g. Lambda)
+func getServiceDefinitionByID(serviceID string) (ServiceDefinition, bool) {
+	for _, serviceDefinition := range getServiceDefinitions() {
+		if serviceDefinition.Metadata.ServiceID == serviceID {
+			return serviceDefinition, true
+		}
+	}
+
+	return ServiceDefinition{}, false
+}
+
+func getObservedOperations() []*observedOperation {
+	operations := make(map[string]*observedOperation)
+	for _, entry := range getPolicyEntries() {
+		serviceDef, ok := getServiceDefinitionByID(entry.Service)
+		if !ok {
+			continue
+		}
+		if _, ok := serviceDef.Operations[entry.Method]; !ok {
+			continue
+		}
+
+		key := serviceDef.Metadata.EndpointPrefix + "." + entry.Method
+		if operations[key] == nil {
+			operations[key] = &observedOperation{
+				serviceDef: serviceDef,
+				name:       entry.Method,
+				regions:    make(map[string]bool),
+				params:     make(map[string]bool),
+			}
+		}
+		if entry.Region != "" {
+			operations[key].regions[entry.Region] = true
+		}
+		for param := range entry.Parameters {
+			operations[key].params[param] = true
+		}
+	}
+
+	var keys []string
+	for key := range operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sortedOperations []*observedOperation
+	for _, key := range keys {
+		sortedOperations = append(sortedOperations, operations[key])
+	}
+	return sortedOperations
+}
+
+// getOperationHTTP returns the HTTP binding of an operation, filling in the defaults left out of the minified
+// service definitions
+func getOperationHTTP(operation ServiceOperation) ServiceHttp {
+	binding := operation.Http
+	if binding.Method == "" {
+		binding.Method = http.MethodPost
+	}
+	if binding.RequestURI == "" {
+		binding.RequestURI = "/"
+	}
+	if binding.ResponseCode == 0 {
+		binding.ResponseCode = http.StatusOK
+	}
+
+	return binding
+}
+
+// getOpenAPIType returns the OpenAPI type of a member of a service shape
+func getOpenAPIType(member ServiceStructure, shapes map[string]ServiceStructure) string {
+	switch resolveShape(member, shapes).Type {
+	case "structure", "map":
+		return "object"
+	case "list":
+		return "array"
+	case "boolean":
+		return "boolean"
+	case "integer", "long":
+		return "integer"
+	case "float", "double":
+		return "number"
+	}
+
+	return "string"
+}
+
+// getOpenAPIPath returns the path an operation is addressed by. Operations of the query and JSON protocols share
+// the path /, so they are told apart by their Action parameter or X-Amz-Target header.
+func getOpenAPIPath(operation *observedOperation) string {
+	metadata := operation.serviceDef.Metadata
+	switch metadata.Protocol {
+	case "rest-json", "rest-xml":
+		return restURITemplateRegexp.ReplaceAllString(getOperationHTTP(operation.serviceDef.Operations[operation.name]).RequestURI, "{$1}")
+	case "json":
+		return "/#X-Amz-Target=" + metadata.TargetPrefix + "." + operation.name
+	}
+
+	return "/?Action=" + operation.name
+}
+
+func getOpenAPIServers(endpointPrefix string, observedRegions map[string]bool) []openAPIServer {
+	var regions []string
+	for region := range observedRegions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var servers []openAPIServer
+	for _, region := range regions {
+		servers = append(servers, openAPIServer{
+			URL: fmt.Sprintf("https://%s.%s.amazonaws.com", endpointPrefix, region),
+		})
+	}
+	return servers
+}
+
+// getOpenAPIOperation describes an observed operation, with its path labels and the parameters seen in its calls
+func getOpenAPIOperation(operation *observedOperation) openAPIOperation {
+	serviceDef := operation.serviceDef
+	serviceOperation := serviceDef.Operations[operation.name]
+	binding := getOperationHTTP(serviceOperation)
+
+	openAPIOp := openAPIOperation{
+		OperationID: serviceDef.Metadata.EndpointPrefix + "." + operation.name,
+		Summary:     operation.name,
+		Tags:        []string{serviceDef.Metadata.ServiceID},
+		Deprecated:  serviceOperation.Deprecated,
+		Responses: map[string]openAPIResponse{
+			strconv.Itoa(binding.ResponseCode): {Description: "Successful response"},
+		},
+	}
+
+	for _, label := range restURITemplateRegexp.FindAllStringSubmatch(binding.RequestURI, -1) {
+		openAPIOp.Parameters = append(openAPIOp.Parameters, openAPIParameter{
+			Name:     label[1],
+			In:       "path",
+			Required: true,
+			Schema:   openAPISchema{Type: "string"},
+		})
+	}
+
+	var params []string
+	for param := range operation.params {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	input := resolveShape(serviceOperation.Input, serviceDef.Shapes)
+	bodyProperties := make(map[string]openAPISchema)
+	for _, param := range params {
+		switch serviceDef.Metadata.Protocol {
+		case "rest-json", "rest-xml":
+			member, ok := input.Members[param]
+			if ok && (member.Location == "querystring" || member.Location == "header") {
+				in := "query"
+				if member.Location == "header" {
+					in = "header"
+				}
+				openAPIOp.Parameters = append(openAPIOp.Parameters, openAPIParameter{
+					Name:   member.LocationName,
+					In:     in,
+					Schema: openAPISchema{Type: getOpenAPIType(member, serviceDef.Shapes)},
+				})
+				continue
+			}
+			if ok && member.Location != "" {
+				continue
+			}
+			fallthrough
+		case "json":
+			topLevelParam := getTopLevelParam(param)
+			bodyProperties[topLevelParam] = openAPISchema{Type: getOpenAPIType(input.Members[topLevelParam], serviceDef.Shapes)}
+		default:
+			openAPIOp.Parameters = append(openAPIOp.Parameters, openAPIParameter{Name: param, In: "query", Schema: openAPISchema{Type: "string"}})
+		}
+	}
+
+	switch serviceDef.Metadata.Protocol {
+	case "json":
+		openAPIOp.Parameters = append(openAPIOp.Parameters, openAPIParameter{
+			Name:     "X-Amz-Target",
+			In:       "header",
+			Required: true,
+			Schema:   openAPISchema{Type: "string", Enum: []string{serviceDef.Metadata.TargetPrefix + "." + operation.name}},
+		})
+	case "query", "ec2":
+		openAPIOp.Parameters = append(openAPIOp.Parameters, openAPIParameter{
+			Name:     "Action",
+			In:       "query",
+			Required: true,
+			Schema:   openAPISchema{Type: "string", Enum: []string{operation.name}},
+		}, openAPIParameter{
+			Name:     "Version",
+			In:       "query",
+			Required: true,
+			Schema:   openAPISchema{Type: "string", Enum: []string{serviceDef.Metadata.APIVersion}},
+		})
+	}
+
+	if len(bodyProperties) > 0 && serviceDef.Metadata.Protocol != "rest-xml" {
+		openAPIOp.RequestBody = &openAPIRequestBody{
+			Content: map[string]openAPIMediaType{
+				"application/json": {Schema: openAPISchema{Type: "object", Properties: bodyProperties}},
+			},
+		}
+	}
+
+	return openAPIOp
+}
+
+// getTopLevelParam returns the top-level member of a flattened parameter name (e.g. Filters for Filters[].Name)
+func getTopLevelParam(param string) string {
+	if i := strings.IndexAny(param, ".["); i > 0 {
+		return param[:i]
+	}
+	return param
+}
+
+// getOpenAPIOutput renders the observed operations as an OpenAPI 3.0 specification, with a path for each
+// operation served from the regional endpoints of its service
+func getOpenAPIOutput() []byte {
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "Observed AWS APIs",
+			Description: "The AWS API operations called by the application, generated by iamlive",
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]map[string]interface{}),
+	}
+
+	pathServices := make(map[string]string)
+	pathRegions := make(map[string]map[string]bool)
+	seenTags := make(map[string]bool)
+	for _, operation := range getObservedOperations() {
+		metadata := operation.serviceDef.Metadata
+
+		// paths such as /tags/{ResourceArn} are shared by several services, which have different servers
+		path := getOpenAPIPath(operation)
+		if service, ok := pathServices[path]; ok && service != metadata.EndpointPrefix {
+			path += "#" + metadata.EndpointPrefix
+		}
+		pathServices[path] = metadata.EndpointPrefix
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]interface{})
+			pathRegions[path] = make(map[string]bool)
+		}
+		for region := range operation.regions {
+			pathRegions[path][region] = true
+		}
+		doc.Paths[path][strings.ToLower(getOperationHTTP(operation.serviceDef.Operations[operation.name]).Method)] = getOpenAPIOperation(operation)
+
+		if !seenTags[metadata.ServiceID] {
+			seenTags[metadata.ServiceID] = true
+			doc.Tags = append(doc.Tags, openAPITag{Name: metadata.ServiceID, Description: metadata.ServiceFullName})
+		}
+	}
+
+	for path, regions := range pathRegions {
+		if servers := getOpenAPIServers(pathServices[path], regions); len(servers) > 0 {
+			doc.Paths[path]["servers"] = servers
+		}
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		panic(err)
+	}
+	encoder.Close()
+	return []byte(sb.String())
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getPackerOutput renders a Packer HCL2 file that looks up the instance profile used for builds and embeds the policy
+func getPackerOutput() []byte {
+	instanceProfileName := "iamlive"
+	if *iamRoleNameFlag != "" {
+		instanceProfileName = *iamRoleNameFlag
+	}
+
+	var sb strings.Builder
+	sb.WriteString("locals {\n")
+	sb.WriteString("  iamlive_policy = <<EOT\n")
+	sb.Write(getPolicyDocument())
+	sb.WriteString("\nEOT\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("data \"aws_iam_instance_profile\" \"iamlive\" {\n")
+	sb.WriteString(fmt.Sprintf("  name = %q\n", instanceProfileName))
+	sb.WriteString("}\n")
+
+	if *packerImageNameFlag != "" {
+		sb.WriteString("\nsource \"amazon-ebs\" \"iamlive\" {\n")
+		sb.WriteString(fmt.Sprintf("  ami_name             = %q\n", *packerImageNameFlag))
+		sb.WriteString(fmt.Sprintf("  region               = %q\n", getAWSRegion()))
+		sb.WriteString("  iam_instance_profile = data.aws_iam_instance_profile.iamlive.name\n")
+		sb.WriteString("}\n")
+	}
+
+	return []byte(sb.String())
+}
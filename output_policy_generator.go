@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+const awsPolicyGeneratorURL = "https://awspolicygen.s3.amazonaws.com/policygen.html"
+
+// getPolicyGeneratorOutput renders AWS Policy Generator URLs pre-filled with the captured actions, one per service
+// to keep each URL to a length browsers accept
+func getPolicyGeneratorOutput() []byte {
+	var services []string
+	serviceActions := make(map[string][]string)
+	for _, action := range getCapturedActions() {
+		service := strings.SplitN(action, ":", 2)[0]
+		if _, ok := serviceActions[service]; !ok {
+			services = append(services, service)
+		}
+		serviceActions[service] = append(serviceActions[service], action)
+	}
+
+	var urls []string
+	for _, service := range services {
+		query := url.Values{
+			"Effect":  []string{"Allow"},
+			"Service": []string{service},
+			"Action":  serviceActions[service],
+		}
+		urls = append(urls, awsPolicyGeneratorURL+"?"+query.Encode())
+	}
+
+	return []byte(strings.Join(urls, "\n"))
+}
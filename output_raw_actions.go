@@ -0,0 +1,25 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// getRawActionsOutput renders the captured actions one per line, for piping into other tools
+func getRawActionsOutput() []byte {
+	var actions []string
+	for _, action := range getCapturedActions() {
+		if !*rawActionsPrefixFlag {
+			action = action[strings.Index(action, ":")+1:]
+		}
+		actions = append(actions, action)
+	}
+
+	actions = uniqueSlice(actions)
+	sort.Strings(actions)
+	if len(actions) == 0 {
+		return []byte{}
+	}
+
+	return []byte(strings.Join(actions, "\n") + "\n")
+}
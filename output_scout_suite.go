@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// destructiveActionPrefixes are the prefixes of actions that irreversibly remove resources or data
+var destructiveActionPrefixes = []string{"Delete", "Terminate", "Destroy", "Purge"}
+
+// ScoutSuiteFinding is a finding in the format of a ScoutSuite rule result
+type ScoutSuiteFinding struct {
+	CheckedItems  int      `json:"checked_items"`
+	DashboardName string   `json:"dashboard_name"`
+	Description   string   `json:"description"`
+	FlaggedItems  int      `json:"flagged_items"`
+	Items         []string `json:"items"`
+	Level         string   `json:"level"`
+	Path          string   `json:"path"`
+	Rationale     string   `json:"rationale"`
+	Service       string   `json:"service"`
+}
+
+// getActionAccessLevel returns the access level (e.g. Permissions management) of an action in the SAR
+func getActionAccessLevel(action string) string {
+	actionSplit := strings.SplitN(action, ":", 2)
+	if len(actionSplit) != 2 {
+		return ""
+	}
+
+	for _, service := range iamDef {
+		if !strings.EqualFold(service.Prefix, actionSplit[0]) {
+			continue
+		}
+		for _, privilege := range service.Privileges {
+			if strings.EqualFold(privilege.Privilege, actionSplit[1]) {
+				return privilege.AccessLevel
+			}
+		}
+	}
+
+	return ""
+}
+
+func isDestructiveAction(action string) bool {
+	actionSplit := strings.SplitN(action, ":", 2)
+	if len(actionSplit) != 2 {
+		return false
+	}
+
+	for _, prefix := range destructiveActionPrefixes {
+		if strings.HasPrefix(actionSplit[1], prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newScoutSuiteFinding(description string, rationale string, level string, checkedItems int, items []string) ScoutSuiteFinding {
+	items = uniqueSlice(items)
+	sort.Strings(items)
+
+	return ScoutSuiteFinding{
+		CheckedItems:  checkedItems,
+		DashboardName: "Actions",
+		Description:   description,
+		FlaggedItems:  len(items),
+		Items:         items,
+		Level:         level,
+		Path:          "iam.iamlive.actions.id",
+		Rationale:     rationale,
+		Service:       "IAM",
+	}
+}
+
+// getScoutSuiteOutput renders the issues found in the policy as ScoutSuite IAM findings, so that they can be shown
+// in an existing ScoutSuite report
+func getScoutSuiteOutput() []byte {
+	policy := getPolicy()
+	actions := getCapturedActions()
+
+	var wildcardItems []string
+	for _, statement := range policy.Statement {
+		wildcardResource := false
+		for _, resource := range getStatementResources(statement) {
+			if resource == "*" {
+				wildcardResource = true
+			}
+		}
+		for _, action := range statement.Action {
+			if wildcardResource || strings.Contains(action, "*") {
+				wildcardItems = append(wildcardItems, action)
+			}
+		}
+	}
+
+	var escalationItems []string
+	for _, path := range getPrivilegeEscalationPaths() {
+		escalationItems = append(escalationItems, path.Name)
+	}
+
+	var destructiveItems []string
+	var adminItems []string
+	for _, action := range actions {
+		if isDestructiveAction(action) {
+			destructiveItems = append(destructiveItems, action)
+		}
+		if getActionAccessLevel(action) == "Permissions management" {
+			adminItems = append(adminItems, action)
+		}
+	}
+
+	findings := map[string]ScoutSuiteFinding{
+		"iamlive-overly-permissive-wildcard": newScoutSuiteFinding(
+			"Actions allowed on all resources or with a wildcard",
+			"Wildcards allow access to resources and actions that the observed calls did not need.",
+			"warning", len(actions), wildcardItems,
+		),
+		"iamlive-privilege-escalation": newScoutSuiteFinding(
+			"Actions allowing privilege escalation",
+			"The policy allows every action of a known privilege escalation path.",
+			"danger", len(privilegeEscalationPaths), escalationItems,
+		),
+		"iamlive-destructive-action": newScoutSuiteFinding(
+			"Destructive actions",
+			"These actions irreversibly delete or terminate resources.",
+			"warning", len(actions), destructiveItems,
+		),
+		"iamlive-admin-action": newScoutSuiteFinding(
+			"Permissions management actions",
+			"These actions can change who has access to resources, including the principal itself.",
+			"danger", len(actions), adminItems,
+		),
+	}
+
+	doc, err := json.MarshalIndent(map[string]interface{}{
+		"iam": map[string]interface{}{
+			"findings": findings,
+		},
+	}, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// scpSizeLimit is the maximum number of characters, excluding whitespace, in a service control policy
+const scpSizeLimit = 5120
+
+// scpExemptTag is the principal tag that exempts a principal from the service control policy in an emergency
+const scpExemptTag = "aws:PrincipalTag/exempt"
+
+// SCPStatement is a statement within a service control policy
+type SCPStatement struct {
+	Sid       string                       `json:"Sid"`
+	Effect    string                       `json:"Effect"`
+	NotAction []string                     `json:"NotAction"`
+	Resource  string                       `json:"Resource"`
+	Condition map[string]map[string]string `json:"Condition"`
+}
+
+// ServiceControlPolicy is an AWS Organizations service control policy
+type ServiceControlPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []SCPStatement `json:"Statement"`
+}
+
+// getAllActions returns every action in the SAR
+func getAllActions() []string {
+	var actions []string
+	for _, service := range iamDef {
+		for _, privilege := range service.Privileges {
+			actions = append(actions, service.Prefix+":"+privilege.Privilege)
+		}
+	}
+
+	return actions
+}
+
+// isKnownAction returns whether an action, which may contain wildcards, matches any of the known actions
+func isKnownAction(action string, knownActions map[string]bool) bool {
+	action = strings.ToLower(action)
+	if !strings.Contains(action, "*") {
+		return knownActions[action]
+	}
+
+	actionRegexp := wildcardToRegexp(action)
+	for knownAction := range knownActions {
+		if actionRegexp.MatchString(knownAction) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSCP returns a service control policy that denies every action other than the captured actions, unless the
+// principal is tagged as exempt. Captured actions matching none of allActions are left out, as they would have no
+// effect in NotAction.
+func getSCP(captured []string, allActions []string) ServiceControlPolicy {
+	knownActions := make(map[string]bool)
+	for _, action := range allActions {
+		knownActions[strings.ToLower(action)] = true
+	}
+
+	var notActions []string
+	for _, action := range uniqueSlice(captured) {
+		if len(knownActions) > 0 && !isKnownAction(action, knownActions) {
+			continue
+		}
+		notActions = append(notActions, action)
+	}
+	sort.Strings(notActions)
+
+	return ServiceControlPolicy{
+		Version: "2012-10-17",
+		Statement: []SCPStatement{
+			{
+				Sid:       "DenyUncapturedActions",
+				Effect:    "Deny",
+				NotAction: notActions,
+				Resource:  "*",
+				Condition: map[string]map[string]string{
+					"StringNotEquals": {
+						scpExemptTag: "true",
+					},
+				},
+			},
+		},
+	}
+}
+
+// FormatSCP renders a deny-list service control policy allowing only the captured actions
+func FormatSCP(captured []string, allActions []string) string {
+	doc, err := json.MarshalIndent(getSCP(captured, allActions), "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return string(doc)
+}
+
+// getSCPSize returns the number of characters in a service control policy, as counted by AWS Organizations
+func getSCPSize(policy ServiceControlPolicy) int {
+	doc, err := json.Marshal(policy)
+	if err != nil {
+		panic(err)
+	}
+	return len(doc)
+}
+
+// getSCPOutput renders the captured actions as a deny-list service control policy
+func getSCPOutput() []byte {
+	return []byte(FormatSCP(getCapturedActions(), getAllActions()))
+}
+
+// getSCPNotes warns when the service control policy is too large to be attached
+func getSCPNotes() []string {
+	size := getSCPSize(getSCP(getCapturedActions(), getAllActions()))
+	if size <= scpSizeLimit {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("WARNING: the service control policy is %d characters, over the %d character limit; run with --suggest-wildcards to find groups of actions a wildcard prefix (e.g. s3:Get*) could replace", size, scpSizeLimit)}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatSCP(t *testing.T) {
+	allActions := []string{"s3:GetObject", "s3:GetObjectAcl", "s3:PutObject", "ec2:DescribeInstances"}
+	captured := []string{"s3:PutObject", "ec2:DescribeInstances", "s3:PutObject", "s3:Get*", "custom:DoThing"}
+
+	var policy ServiceControlPolicy
+	if err := json.Unmarshal([]byte(FormatSCP(captured, allActions)), &policy); err != nil {
+		t.Fatalf("the SCP is not valid JSON: %v", err)
+	}
+	if len(policy.Statement) != 1 {
+		t.Fatalf("got %d statements, want 1", len(policy.Statement))
+	}
+
+	statement := policy.Statement[0]
+	if statement.Effect != "Deny" || statement.Resource != "*" {
+		t.Errorf("got effect %s on %s, want Deny on *", statement.Effect, statement.Resource)
+	}
+	want := []string{"ec2:DescribeInstances", "s3:Get*", "s3:PutObject"}
+	if !reflect.DeepEqual(statement.NotAction, want) {
+		t.Errorf("got NotAction %v, want %v", statement.NotAction, want)
+	}
+	if got := statement.Condition["StringNotEquals"][scpExemptTag]; got != "true" {
+		t.Errorf("got exempt condition %q, want true", got)
+	}
+}
+
+func TestGetSCPNotes(t *testing.T) {
+	resetTestCallLog(t)
+	callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+	if notes := getSCPNotes(); len(notes) != 0 {
+		t.Errorf("got notes %v for a small SCP, want none", notes)
+	}
+
+	// a call to every EC2 action puts the SCP over the size limit
+	for _, service := range iamDef {
+		if service.Prefix != "ec2" {
+			continue
+		}
+		for _, privilege := range service.Privileges {
+			callLog.Append(Entry{Region: "us-east-1", Type: "ApiCall", Service: "EC2", Method: privilege.Privilege, FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+		}
+	}
+	notes := getSCPNotes()
+	if len(notes) != 1 || !strings.Contains(notes[0], fmt.Sprintf("over the %d character limit", scpSizeLimit)) || !strings.Contains(notes[0], "--suggest-wildcards") {
+		t.Errorf("got notes %v, want a warning over the size limit suggesting --suggest-wildcards", notes)
+	}
+}
+
+func TestOutputTypeAlias(t *testing.T) {
+	setTestFlag(t, "output-format", "json")
+
+	if err := flag.CommandLine.Parse([]string{"--output-type", "scp"}); err != nil {
+		t.Fatal(err)
+	}
+	if *outputFormatFlag != "scp" {
+		t.Errorf("got --output-format %s after --output-type scp, want scp", *outputFormatFlag)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// observedAccessKeys maps the access key IDs seen signing requests to the services they were used for
+var observedAccessKeys = make(map[string]map[string]bool)
+var observedAccessKeysMutex sync.Mutex
+
+// getRequestAccessKeyID returns the access key ID that signed a request, from its Authorization header or the
+// X-Amz-Credential parameter of a presigned URL
+func getRequestAccessKeyID(req *http.Request) string {
+	if auth, err := parseSigV4Authorization(req.Header.Get("Authorization")); err == nil {
+		return auth.AccessKeyID
+	}
+
+	return strings.SplitN(req.URL.Query().Get("X-Amz-Credential"), "/", 2)[0]
+}
+
+func recordObservedAccessKey(accessKeyID string, service string) {
+	if accessKeyID == "" || service == "" {
+		return
+	}
+
+	observedAccessKeysMutex.Lock()
+	defer observedAccessKeysMutex.Unlock()
+
+	if observedAccessKeys[accessKeyID] == nil {
+		observedAccessKeys[accessKeyID] = make(map[string]bool)
+	}
+	observedAccessKeys[accessKeyID][normalizeServiceName(service)] = true
+}
+
+// recordRequestAccessKey records the access key that signed a proxied request, before it can be re-signed
+func recordRequestAccessKey(req *http.Request, entry *Entry) {
+	service := ""
+	if entry != nil {
+		service = entry.Service
+	} else if auth, err := parseSigV4Authorization(req.Header.Get("Authorization")); err == nil {
+		service = auth.Service
+	}
+
+	recordObservedAccessKey(getRequestAccessKeyID(req), service)
+}
+
+// redactAccessKeyID keeps only the first and last four characters of an access key ID
+func redactAccessKeyID(accessKeyID string) string {
+	if len(accessKeyID) <= 8 {
+		return strings.Repeat("*", len(accessKeyID))
+	}
+
+	return accessKeyID[:4] + strings.Repeat("*", len(accessKeyID)-8) + accessKeyID[len(accessKeyID)-4:]
+}
+
+// getSecretScanningOutput renders a markdown justification for bypassing GitHub secret scanning push protection,
+// listing the access keys that signed the captured calls
+func getSecretScanningOutput() []byte {
+	observedAccessKeysMutex.Lock()
+	var accessKeyIDs []string
+	servicesByAccessKeyID := make(map[string][]string)
+	for accessKeyID, services := range observedAccessKeys {
+		accessKeyIDs = append(accessKeyIDs, accessKeyID)
+		for service := range services {
+			servicesByAccessKeyID[accessKeyID] = append(servicesByAccessKeyID[accessKeyID], service)
+		}
+		sort.Strings(servicesByAccessKeyID[accessKeyID])
+	}
+	observedAccessKeysMutex.Unlock()
+	sort.Strings(accessKeyIDs)
+
+	var sb strings.Builder
+	sb.WriteString("# Push protection bypass justification\n\n")
+	sb.WriteString("**Secret type:** Amazon AWS Access Key ID\n\n")
+	sb.WriteString("**Bypass reason:** It's used in tests\n\n")
+	sb.WriteString("## Access keys\n\n")
+	if len(accessKeyIDs) == 0 {
+		sb.WriteString("No access keys were observed.\n\n")
+	} else {
+		sb.WriteString("| Access key ID | Services |\n")
+		sb.WriteString("| --- | --- |\n")
+		for _, accessKeyID := range accessKeyIDs {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", redactAccessKeyID(accessKeyID), strings.Join(servicesByAccessKeyID[accessKeyID], ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("## Statement\n\n")
+	sb.WriteString("The access keys listed above were observed by iamlive while capturing the AWS calls made by this project's tests. They are test credentials that grant no access to production resources, and committing them does not expose a secret.\n")
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var botoFirstCapRegexp = regexp.MustCompile(`(.)([A-Z][a-z]+)`)
+var botoEndCapRegexp = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+var botoSpecialCaseRegexp = regexp.MustCompile(`[A-Z]{2,}s$`)
+
+// getBotoMethodName returns the boto3 client method name for an operation, as transformed by botocore
+func getBotoMethodName(operation string) string {
+	if match := botoSpecialCaseRegexp.FindString(operation); match != "" {
+		operation = operation[:len(operation)-len(match)] + "_" + strings.ToLower(match)
+	}
+
+	name := botoFirstCapRegexp.ReplaceAllString(operation, "${1}_${2}")
+	return strings.ToLower(botoEndCapRegexp.ReplaceAllString(name, "${1}_${2}"))
+}
+
+// SemgrepService is a captured service with the operations that the policy doesn't allow
+type SemgrepService struct {
+	Definition *ServiceDefinition
+	Operations []string
+	Actions    []string
+}
+
+// getSemgrepServices returns the operations of each captured service that call actions missing from the policy
+func getSemgrepServices() []SemgrepService {
+	readServiceFiles()
+
+	allowedActions := make(map[string]bool)
+	for _, action := range getCapturedActions() {
+		allowedActions[strings.ToLower(action)] = true
+	}
+
+	capturedServices := make(map[string]string)
+	for _, entry := range callLog.Snapshot() {
+		capturedServices[normalizeServiceName(entry.Service)] = entry.Service
+	}
+
+	definitions := getServiceDefinitions()
+	var services []SemgrepService
+	for normalizedService, service := range capturedServices {
+		// service definitions are sorted latest API version first
+		var serviceDefinition *ServiceDefinition
+		for i := range definitions {
+			if normalizeServiceName(definitions[i].Metadata.ServiceID) == normalizedService {
+				serviceDefinition = &definitions[i]
+				break
+			}
+		}
+		if serviceDefinition == nil {
+			continue
+		}
+
+		semgrepService := SemgrepService{Definition: serviceDefinition}
+		for operation := range serviceDefinition.Operations {
+			var missingActions []string
+			for _, action := range getActions(service, operation) {
+				if !allowedActions[strings.ToLower(action)] {
+					missingActions = append(missingActions, action)
+				}
+			}
+			if len(missingActions) > 0 {
+				semgrepService.Operations = append(semgrepService.Operations, operation)
+				semgrepService.Actions = append(semgrepService.Actions, missingActions...)
+			}
+		}
+		if len(semgrepService.Operations) == 0 {
+			continue
+		}
+
+		sort.Strings(semgrepService.Operations)
+		semgrepService.Actions = uniqueSlice(semgrepService.Actions)
+		sort.Strings(semgrepService.Actions)
+		services = append(services, semgrepService)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Definition.Metadata.ServiceID < services[j].Definition.Metadata.ServiceID
+	})
+
+	return services
+}
+
+func writeSemgrepRule(sb *strings.Builder, service SemgrepService, language string, sdk string, patterns []string) {
+	serviceName := normalizeServiceName(service.Definition.Metadata.ServiceID)
+
+	sb.WriteString(fmt.Sprintf("  - id: iamlive-%s-%s\n", serviceName, language))
+	sb.WriteString(fmt.Sprintf("    message: %q\n", fmt.Sprintf("This %s call is not allowed by the IAM policy inferred by iamlive", service.Definition.Metadata.ServiceID)))
+	sb.WriteString("    severity: WARNING\n")
+	sb.WriteString(fmt.Sprintf("    languages: [%s]\n", language))
+	sb.WriteString("    pattern-either:\n")
+	for _, pattern := range patterns {
+		sb.WriteString(fmt.Sprintf("      - pattern: %q\n", pattern))
+	}
+	sb.WriteString("    metadata:\n")
+	sb.WriteString("      iamlive:\n")
+	sb.WriteString(fmt.Sprintf("        service: %q\n", service.Definition.Metadata.ServiceID))
+	sb.WriteString(fmt.Sprintf("        api-version: %q\n", service.Definition.Metadata.APIVersion))
+	sb.WriteString(fmt.Sprintf("        sdk: %s\n", sdk))
+	sb.WriteString("        missing-actions:\n")
+	for _, action := range service.Actions {
+		sb.WriteString(fmt.Sprintf("          - %s\n", action))
+	}
+}
+
+// getSemgrepOutput renders Semgrep rules that flag boto3 and aws-sdk-go-v2 calls to operations of the captured
+// services that the policy doesn't allow
+func getSemgrepOutput() []byte {
+	services := getSemgrepServices()
+
+	var sb strings.Builder
+	if len(services) == 0 {
+		sb.WriteString("rules: []\n")
+		return []byte(sb.String())
+	}
+
+	sb.WriteString("rules:\n")
+	for _, service := range services {
+		var pythonPatterns []string
+		var goPatterns []string
+		// aws-sdk-go-v2 packages are named after the service ID, e.g. Route 53 is route53
+		goPackage := strings.Replace(normalizeServiceName(service.Definition.Metadata.ServiceID), "-", "", -1)
+		for _, operation := range service.Operations {
+			pythonPatterns = append(pythonPatterns, fmt.Sprintf("$CLIENT.%s(...)", getBotoMethodName(operation)))
+			goPatterns = append(goPatterns, fmt.Sprintf("%s.%sInput{...}", goPackage, operation))
+		}
+
+		writeSemgrepRule(&sb, service, "python", "boto3", pythonPatterns)
+		writeSemgrepRule(&sb, service, "go", "aws-sdk-go-v2", goPatterns)
+	}
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// SimulatorContextEntry is a condition key value passed to the IAM policy simulator
+type SimulatorContextEntry struct {
+	ContextKeyName   string   `json:"ContextKeyName"`
+	ContextKeyValues []string `json:"ContextKeyValues"`
+	ContextKeyType   string   `json:"ContextKeyType"`
+}
+
+// SimulatorInput is the input for aws iam simulate-principal-policy --cli-input-json
+type SimulatorInput struct {
+	ActionNames    []string                `json:"ActionNames"`
+	ResourceArns   []string                `json:"ResourceArns"`
+	ContextEntries []SimulatorContextEntry `json:"ContextEntries,omitempty"`
+}
+
+func getSimulatorInputOutput() []byte {
+	policy := getPolicy()
+
+	input := SimulatorInput{
+		ActionNames:  []string{},
+		ResourceArns: []string{},
+	}
+	for _, statement := range policy.Statement {
+		input.ActionNames = append(input.ActionNames, statement.Action...)
+		input.ResourceArns = append(input.ResourceArns, getStatementResources(statement)...)
+	}
+	input.ActionNames = uniqueSlice(input.ActionNames)
+	input.ResourceArns = uniqueSlice(input.ResourceArns)
+	sort.Strings(input.ActionNames)
+	sort.Strings(input.ResourceArns)
+
+	contextValues := make(map[string][]string)
+	for _, entry := range callLog.Snapshot() {
+		for k, v := range entry.ConditionKeys {
+			contextValues[k] = append(contextValues[k], v)
+		}
+	}
+
+	var contextKeys []string
+	for k := range contextValues {
+		contextKeys = append(contextKeys, k)
+	}
+	sort.Strings(contextKeys)
+
+	for _, k := range contextKeys {
+		values := uniqueSlice(contextValues[k])
+		contextKeyType := "string"
+		if len(values) > 1 {
+			contextKeyType = "stringList"
+		}
+
+		input.ContextEntries = append(input.ContextEntries, SimulatorContextEntry{
+			ContextKeyName:   k,
+			ContextKeyValues: values,
+			ContextKeyType:   contextKeyType,
+		})
+	}
+
+	doc, err := json.MarshalIndent(input, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
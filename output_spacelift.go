@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const spaceliftPolicyRules = `
+iam_policy_resource_types := {"aws_iam_policy", "aws_iam_role_policy", "aws_iam_user_policy", "aws_iam_group_policy"}
+
+statements(policy) = s {
+    is_array(policy.Statement)
+    s := policy.Statement
+}
+
+statements(policy) = [policy.Statement] {
+    is_object(policy.Statement)
+}
+
+actions(statement) = a {
+    is_array(statement.Action)
+    a := statement.Action
+}
+
+actions(statement) = [statement.Action] {
+    is_string(statement.Action)
+}
+
+deny[sprintf("%s grants %s, which was not observed by iamlive", [resource.address, action])] {
+    resource := input.terraform.resource_changes[_]
+    iam_policy_resource_types[resource.type]
+    resource.change.actions[_] != "delete"
+    statement := statements(json.unmarshal(resource.change.after.policy))[_]
+    statement.Effect == "Allow"
+    action := actions(statement)[_]
+    not observed_actions[lower(action)]
+}
+`
+
+// getSpaceliftOutput renders a Spacelift plan policy denying IAM policy changes that grant unobserved actions
+func getSpaceliftOutput() []byte {
+	var sb strings.Builder
+	sb.WriteString("package spacelift\n\n")
+	sb.WriteString("import data.spacelift.proposed_plan\n\n")
+	sb.WriteString("# Generated by iamlive from the observed AWS calls\n")
+
+	// an empty {} is an object in Rego, so an empty set must be written as set()
+	actions := getCapturedActions()
+	if len(actions) == 0 {
+		sb.WriteString("observed_actions := set()\n")
+	} else {
+		sb.WriteString("observed_actions := {\n")
+		for i, action := range actions {
+			sb.WriteString(fmt.Sprintf("    %q", strings.ToLower(action)))
+			if i < len(actions)-1 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	}
+	sb.WriteString(spaceliftPolicyRules)
+
+	return []byte(sb.String())
+}
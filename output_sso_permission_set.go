@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// getSSOPermissionSetOutput renders a shell script of aws sso-admin commands creating a permission set with the
+// policy as its inline policy
+func getSSOPermissionSetOutput() []byte {
+	compactDoc := new(bytes.Buffer)
+	if err := json.Compact(compactDoc, getPolicyDocument()); err != nil {
+		panic(err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString("# Generated by iamlive from the observed AWS calls\n")
+	sb.WriteString("set -e\n\n")
+	sb.WriteString(fmt.Sprintf("PERMISSION_SET_ARN=$(aws sso-admin create-permission-set --instance-arn %s --name %s --query PermissionSet.PermissionSetArn --output text)\n", shellQuote(*ssoInstanceARNFlag), shellQuote(*permissionSetNameFlag)))
+	sb.WriteString(fmt.Sprintf("aws sso-admin put-inline-policy-to-permission-set --instance-arn %s --permission-set-arn \"$PERMISSION_SET_ARN\" --inline-policy %s\n", shellQuote(*ssoInstanceARNFlag), shellQuote(compactDoc.String())))
+
+	return []byte(sb.String())
+}
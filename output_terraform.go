@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"text/template"
+)
+
+// terraformStatement is a statement block of an aws_iam_policy_document data source
+type terraformStatement struct {
+	Sid       string
+	Effect    string
+	Actions   []string
+	Resources []string
+}
+
+var terraformHCLEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{")
+
+// getHCLString quotes a string for HCL, escaping template sequences so that ARNs are never interpolated
+func getHCLString(s string) string {
+	return `"` + terraformHCLEscaper.Replace(s) + `"`
+}
+
+func getHCLList(list []string) string {
+	var items []string
+	for _, item := range list {
+		items = append(items, getHCLString(item))
+	}
+
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+var terraformHCLTemplate = template.Must(template.New("terraform-hcl").Funcs(template.FuncMap{
+	"hcl":     getHCLString,
+	"hclList": getHCLList,
+}).Parse(`data "aws_iam_policy_document" "iamlive" {
+{{- range $i, $statement := .}}
+{{- if $i}}
+{{end}}
+  statement {
+{{- if $statement.Sid}}
+    sid       = {{hcl $statement.Sid}}
+{{- end}}
+    effect    = {{hcl $statement.Effect}}
+    actions   = {{hclList $statement.Actions}}
+    resources = {{hclList $statement.Resources}}
+  }
+{{- end}}
+}
+`))
+
+// FormatTerraform renders statements as an aws_iam_policy_document data source, with one statement block for each
+// distinct combination of effect, actions and resources
+func FormatTerraform(statements []PolicyStatement) string {
+	var terraformStatements []terraformStatement
+	seen := make(map[string]bool)
+	for _, statement := range statements {
+		resources := getStatementResources(statement)
+		if len(resources) == 0 {
+			resources = []string{"*"}
+		}
+
+		key := statement.Effect + "\n" + strings.Join(statement.Action, ",") + "\n" + strings.Join(resources, ",")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		terraformStatements = append(terraformStatements, terraformStatement{
+			Sid:       statement.Sid,
+			Effect:    statement.Effect,
+			Actions:   statement.Action,
+			Resources: resources,
+		})
+	}
+
+	var sb strings.Builder
+	if err := terraformHCLTemplate.Execute(&sb, terraformStatements); err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+// getTerraformHCLOutput renders the policy as a Terraform aws_iam_policy_document data source
+func getTerraformHCLOutput() []byte {
+	return []byte(FormatTerraform(getPolicy().Statement))
+}
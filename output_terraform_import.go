@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// terraformIAMResourceTypes maps IAM ARN resource types to their Terraform resource and whether they're imported by ARN
+var terraformIAMResourceTypes = map[string]struct {
+	resourceType string
+	importByARN  bool
+}{
+	"role":             {"aws_iam_role", false},
+	"policy":           {"aws_iam_policy", true},
+	"user":             {"aws_iam_user", false},
+	"group":            {"aws_iam_group", false},
+	"instance-profile": {"aws_iam_instance_profile", false},
+}
+
+var terraformInvalidNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+func getTerraformResourceName(name string) string {
+	name = terraformInvalidNameRegexp.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// getTerraformImportOutput renders a shell script of terraform import commands for the IAM resources in the policy
+func getTerraformImportOutput() []byte {
+	var commands []string
+	for _, statement := range getPolicy().Statement {
+		for _, resource := range getStatementResources(statement) {
+			arnSplit := strings.SplitN(resource, ":", 6)
+			if len(arnSplit) != 6 || arnSplit[2] != "iam" || strings.ContainsAny(resource, "*?$") {
+				continue
+			}
+
+			resourceSplit := strings.Split(arnSplit[5], "/")
+			iamResourceType, ok := terraformIAMResourceTypes[resourceSplit[0]]
+			if !ok || len(resourceSplit) < 2 {
+				continue
+			}
+
+			name := resourceSplit[len(resourceSplit)-1]
+			importID := name
+			if iamResourceType.importByARN {
+				importID = resource
+			}
+
+			commands = append(commands, fmt.Sprintf("terraform import %s.%s '%s'", iamResourceType.resourceType, getTerraformResourceName(name), importID))
+		}
+	}
+
+	commands = uniqueSlice(commands)
+	sort.Strings(commands)
+
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString("# Generated by iamlive from the IAM resources referenced by the observed AWS calls\n")
+	sb.WriteString("set -e\n\n")
+	for _, command := range commands {
+		sb.WriteString(command + "\n")
+	}
+
+	return []byte(sb.String())
+}
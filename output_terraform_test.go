@@ -0,0 +1,273 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terraformTestStatement is a statement block read back from the generated HCL
+type terraformTestStatement struct {
+	Sid       string
+	Effect    string
+	Actions   []string
+	Resources []string
+}
+
+func getHCLTestString(t *testing.T, attr *hcl.Attribute) string {
+	t.Helper()
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		t.Fatalf("could not evaluate %s: %s", attr.Name, diags.Error())
+	}
+	return value.AsString()
+}
+
+func getHCLTestList(t *testing.T, attr *hcl.Attribute) []string {
+	t.Helper()
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		t.Fatalf("could not evaluate %s: %s", attr.Name, diags.Error())
+	}
+	if !value.Type().IsTupleType() && !value.Type().IsListType() {
+		t.Fatalf("%s is a %s, want a list", attr.Name, value.Type().FriendlyName())
+	}
+
+	var list []string
+	for _, item := range value.AsValueSlice() {
+		if item.Type() != cty.String {
+			t.Fatalf("%s has a %s item, want strings", attr.Name, item.Type().FriendlyName())
+		}
+		list = append(list, item.AsString())
+	}
+	return list
+}
+
+// parseTerraformTestOutput parses the generated HCL with hclsyntax, returning its statement blocks
+func parseTerraformTestOutput(t *testing.T, output string) []terraformTestStatement {
+	t.Helper()
+
+	file, diags := hclsyntax.ParseConfig([]byte(output), "policy.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatalf("the output is not valid HCL: %s\n%s", diags.Error(), output)
+	}
+
+	body := file.Body.(*hclsyntax.Body)
+	if len(body.Blocks) != 1 {
+		t.Fatalf("got %d top-level blocks, want 1", len(body.Blocks))
+	}
+	data := body.Blocks[0]
+	if data.Type != "data" || !reflect.DeepEqual(data.Labels, []string{"aws_iam_policy_document", "iamlive"}) {
+		t.Fatalf("got block %s %v, want data aws_iam_policy_document iamlive", data.Type, data.Labels)
+	}
+
+	var statements []terraformTestStatement
+	for _, block := range data.Body.Blocks {
+		if block.Type != "statement" {
+			t.Fatalf("got a %s block, want statement blocks", block.Type)
+		}
+
+		var statement terraformTestStatement
+		for name, attr := range block.Body.Attributes {
+			switch name {
+			case "sid":
+				statement.Sid = getHCLTestString(t, attr.AsHCLAttribute())
+			case "effect":
+				statement.Effect = getHCLTestString(t, attr.AsHCLAttribute())
+			case "actions":
+				statement.Actions = getHCLTestList(t, attr.AsHCLAttribute())
+			case "resources":
+				statement.Resources = getHCLTestList(t, attr.AsHCLAttribute())
+			default:
+				t.Fatalf("unexpected attribute %s", name)
+			}
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func TestFormatTerraform(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []PolicyStatement
+		want       []terraformTestStatement
+	}{
+		{
+			name:       "no statements",
+			statements: []PolicyStatement{},
+			want:       nil,
+		},
+		{
+			name: "single action with a wildcard resource",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:ListAllMyBuckets"}, Resource: "*"},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:ListAllMyBuckets"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "unknown resources become a wildcard",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"sts:GetCallerIdentity"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"sts:GetCallerIdentity"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "empty resource list becomes a wildcard",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"ec2:DescribeInstances"}, Resource: []string{}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"ec2:DescribeInstances"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "multiple actions of one service",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:GetObject", "s3:PutObject"}, Resource: []string{"arn:aws:s3:::bucket/*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:GetObject", "s3:PutObject"}, Resources: []string{"arn:aws:s3:::bucket/*"}},
+			},
+		},
+		{
+			name: "multiple resources",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*", "arn:aws:s3:::b/*", "arn:aws:s3:::c/*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::a/*", "arn:aws:s3:::b/*", "arn:aws:s3:::c/*"}},
+			},
+		},
+		{
+			name: "multiple services",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:ListAllMyBuckets"}, Resource: "*"},
+				{Effect: "Allow", Action: []string{"ec2:DescribeInstances"}, Resource: "*"},
+				{Effect: "Allow", Action: []string{"dynamodb:GetItem"}, Resource: []string{"arn:aws:dynamodb:us-east-1:123456789012:table/orders"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:ListAllMyBuckets"}, Resources: []string{"*"}},
+				{Effect: "Allow", Actions: []string{"ec2:DescribeInstances"}, Resources: []string{"*"}},
+				{Effect: "Allow", Actions: []string{"dynamodb:GetItem"}, Resources: []string{"arn:aws:dynamodb:us-east-1:123456789012:table/orders"}},
+			},
+		},
+		{
+			name: "multiple services in one statement",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"kms:Decrypt", "s3:GetObject", "sqs:ReceiveMessage"}, Resource: "*"},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"kms:Decrypt", "s3:GetObject", "sqs:ReceiveMessage"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "multiple services and resources",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"sqs:SendMessage"}, Resource: []string{"arn:aws:sqs:us-east-1:123456789012:a", "arn:aws:sqs:us-east-1:123456789012:b"}},
+				{Effect: "Allow", Action: []string{"sns:Publish"}, Resource: []string{"arn:aws:sns:us-east-1:123456789012:topic"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"sqs:SendMessage"}, Resources: []string{"arn:aws:sqs:us-east-1:123456789012:a", "arn:aws:sqs:us-east-1:123456789012:b"}},
+				{Effect: "Allow", Actions: []string{"sns:Publish"}, Resources: []string{"arn:aws:sns:us-east-1:123456789012:topic"}},
+			},
+		},
+		{
+			name: "duplicate statements are rendered once",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::bucket/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::bucket/*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::bucket/*"}},
+			},
+		},
+		{
+			name: "statements differing only in effect are kept apart",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:DeleteObject"}, Resource: "*"},
+				{Effect: "Deny", Action: []string{"s3:DeleteObject"}, Resource: "*"},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:DeleteObject"}, Resources: []string{"*"}},
+				{Effect: "Deny", Actions: []string{"s3:DeleteObject"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "sid is rendered when set",
+			statements: []PolicyStatement{
+				{Sid: "ReadOrders", Effect: "Allow", Action: []string{"dynamodb:Query"}, Resource: "*"},
+			},
+			want: []terraformTestStatement{
+				{Sid: "ReadOrders", Effect: "Allow", Actions: []string{"dynamodb:Query"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "wildcard actions",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:Get*", "s3:List*"}, Resource: "*"},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:Get*", "s3:List*"}, Resources: []string{"*"}},
+			},
+		},
+		{
+			name: "wildcard resources within ARNs",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"logs:PutLogEvents"}, Resource: []string{"arn:aws:logs:*:123456789012:log-group:/aws/lambda/*:*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"logs:PutLogEvents"}, Resources: []string{"arn:aws:logs:*:123456789012:log-group:/aws/lambda/*:*"}},
+			},
+		},
+		{
+			name: "template sequences in ARNs are not interpolated",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::bucket/${aws:username}/*", "arn:aws:s3:::bucket/%{if}/*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::bucket/${aws:username}/*", "arn:aws:s3:::bucket/%{if}/*"}},
+			},
+		},
+		{
+			name: "quotes and backslashes are escaped",
+			statements: []PolicyStatement{
+				{Sid: `Say "hi"`, Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{`arn:aws:s3:::bucket/a\b`}},
+			},
+			want: []terraformTestStatement{
+				{Sid: `Say "hi"`, Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{`arn:aws:s3:::bucket/a\b`}},
+			},
+		},
+		{
+			name: "same actions on different resources are separate statements",
+			statements: []PolicyStatement{
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::a/*"}},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"arn:aws:s3:::b/*"}},
+			},
+			want: []terraformTestStatement{
+				{Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::a/*"}},
+				{Effect: "Allow", Actions: []string{"s3:GetObject"}, Resources: []string{"arn:aws:s3:::b/*"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := FormatTerraform(tt.statements)
+
+			got := parseTerraformTestOutput(t, output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got statements %+v, want %+v\n%s", got, tt.want, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getAssumedRoleNames returns the names of the roles assumed by sts:AssumeRole calls in the log
+func getAssumedRoleNames() []string {
+	var roleNames []string
+	for _, entry := range callLog.Snapshot() {
+		if strings.ToLower(entry.Service) != "sts" || entry.Method != "AssumeRole" {
+			continue
+		}
+		for _, roleARN := range entry.Parameters["RoleArn"] {
+			roleARNSplit := strings.Split(roleARN, "/")
+			roleNames = append(roleNames, roleARNSplit[len(roleARNSplit)-1])
+		}
+	}
+
+	roleNames = uniqueSlice(roleNames)
+	sort.Strings(roleNames)
+	return roleNames
+}
+
+// getVaultPolicyOutput renders a Vault policy allowing credentials to be read from the AWS secrets engine for
+// each role assumed, assuming the Vault roles are named after the IAM roles
+func getVaultPolicyOutput() []byte {
+	mountPath := strings.Trim(*vaultAWSMountPathFlag, "/")
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by iamlive from the observed sts:AssumeRole calls\n")
+
+	roleNames := getAssumedRoleNames()
+	if len(roleNames) == 0 {
+		sb.WriteString("# No sts:AssumeRole calls with a RoleArn were observed\n")
+	}
+	for _, roleName := range roleNames {
+		sb.WriteString(fmt.Sprintf("\npath %q {\n", fmt.Sprintf("%s/creds/%s", mountPath, roleName)))
+		sb.WriteString("  capabilities = [\"read\"]\n")
+		sb.WriteString("}\n")
+	}
+
+	return []byte(sb.String())
+}
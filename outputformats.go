@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// outputFormatExtensions maps each output format to the extension of its file in --output-dir, formats not listed
+// are written as .json
+var outputFormatExtensions = map[string]string{
+	"kubeseal":                   ".yaml",
+	"env":                        ".sh",
+	"spacelift":                  ".rego",
+	"kustomize-patch":            ".yaml",
+	"aws-config-rule":            ".yaml",
+	"terraform-import":           ".sh",
+	"github-copilot":             ".md",
+	"backstage":                  ".yaml",
+	"packer":                     ".pkr.hcl",
+	"aws-policy-generator":       ".txt",
+	"vault-policy":               ".hcl",
+	"semgrep":                    ".yaml",
+	"github-secret-scanning":     ".md",
+	"terraform-hcl":              ".tf",
+	"cloudformation-yaml":        ".yaml",
+	"cdk-python":                 ".py",
+	"aws-sso-permission-set-cli": ".sh",
+	"raw-actions":                ".txt",
+	"open-api":                   ".yaml",
+	"html":                       ".html",
+}
+
+// selectedOutputFormats are the formats given to --output-formats
+var selectedOutputFormats []string
+
+// loadOutputFormats parses and validates --output-formats, which needs --output-dir to write more than one format
+func loadOutputFormats() error {
+	if *outputFormatsFlag == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, format := range strings.Split(*outputFormatsFlag, ",") {
+		format = strings.TrimSpace(format)
+		if format == "" || seen[format] {
+			continue
+		}
+		if err := validateOutputFormatName(format); err != nil {
+			return err
+		}
+		seen[format] = true
+		selectedOutputFormats = append(selectedOutputFormats, format)
+	}
+
+	if len(selectedOutputFormats) == 0 {
+		return fmt.Errorf("--output-formats must list at least one output format")
+	}
+	if len(selectedOutputFormats) > 1 && *outputDirFlag == "" {
+		return fmt.Errorf("--output-dir is required when --output-formats lists more than one format")
+	}
+
+	return nil
+}
+
+// isOutputFormatSelected returns whether a format is written, either as the --output-format or one of the
+// --output-formats
+func isOutputFormatSelected(format string) bool {
+	if *outputFormatFlag == format {
+		return true
+	}
+	for _, selectedFormat := range selectedOutputFormats {
+		if selectedFormat == format {
+			return true
+		}
+	}
+	return false
+}
+
+// isPolicyFileOutput returns whether the policy is written to files rather than to stdout
+func isPolicyFileOutput() bool {
+	return *outputFileFlag != "" || len(selectedOutputFormats) > 0
+}
+
+// getPolicyFileOutputDescription returns where the policy is written, for status messages
+func getPolicyFileOutputDescription() string {
+	var outputs []string
+	if *outputFileFlag != "" {
+		outputs = append(outputs, *outputFileFlag)
+	}
+	if len(selectedOutputFormats) > 0 {
+		dir := *outputDirFlag
+		if dir == "" {
+			dir = "."
+		}
+		outputs = append(outputs, dir)
+	}
+	return strings.Join(outputs, " and ")
+}
+
+func getOutputFormatExtension(format string) string {
+	if ext, ok := outputFormatExtensions[format]; ok {
+		return ext
+	}
+	return ".json"
+}
+
+// getOutputFormatFiles returns the file each of the --output-formats is written to, named policy with the
+// extension of the format, or with the format added when several formats share an extension
+func getOutputFormatFiles(dir string) map[string]string {
+	extensionCounts := make(map[string]int)
+	for _, format := range selectedOutputFormats {
+		extensionCounts[getOutputFormatExtension(format)]++
+	}
+
+	files := make(map[string]string)
+	for _, format := range selectedOutputFormats {
+		ext := getOutputFormatExtension(format)
+		name := "policy" + ext
+		if extensionCounts[ext] > 1 {
+			name = "policy-" + format + ext
+		}
+		files[format] = filepath.Join(dir, name)
+	}
+	return files
+}
+
+// writeOutputFormatFile writes one of the --output-formats and its companion documents, splitting a json policy
+// exceeding --max-policy-size into numbered files as is done for --output-file
+func writeOutputFormatFile(format string, file string) error {
+	split := false
+	if format == "json" {
+		files, err := writeSplitPolicyFiles(file)
+		if err != nil {
+			return err
+		}
+		split = len(files) > 0
+	}
+
+	if !split {
+		if err := ioutil.WriteFile(file, getPolicyOutputForFormat(format), 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, additionalOutput := range getFormatAdditionalOutputs(format) {
+		if err := ioutil.WriteFile(getCompanionFile(file, additionalOutput.Suffix), additionalOutput.Output, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOutputFormats writes each of the --output-formats to its own file in --output-dir concurrently, returning
+// the errors of any that could not be written
+func writeOutputFormats() error {
+	dir := "."
+	if *outputDirFlag != "" {
+		var err error
+		dir, err = homedir.Expand(*outputDirFlag)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	var errsMutex sync.Mutex
+	var errs []string
+	for format, file := range getOutputFormatFiles(dir) {
+		wg.Add(1)
+		go func(format string, file string) {
+			defer wg.Done()
+
+			err := writeOutputFormatFile(format, file)
+			if err != nil {
+				errsMutex.Lock()
+				errs = append(errs, fmt.Sprintf("%s: %v", format, err))
+				errsMutex.Unlock()
+			}
+		}(format, file)
+	}
+	wg.Wait()
+
+	if *networkPolicyModeFlag {
+		networkPolicy := getNetworkPolicyAdditionalOutput()
+		file := getCompanionFile(filepath.Join(dir, "policy"), networkPolicy.Suffix)
+		if err := ioutil.WriteFile(file, networkPolicy.Output, 0644); err != nil {
+			errs = append(errs, fmt.Sprintf("network policy: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("could not write the output formats: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
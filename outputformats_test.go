@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestOutputFormats selects --output-formats writing to a new directory for the duration of a test, returning
+// the directory
+func loadTestOutputFormats(t *testing.T, formats string) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "out")
+	setTestFlag(t, "output-formats", formats)
+	setTestFlag(t, "output-dir", dir)
+	selectedOutputFormats = nil
+	t.Cleanup(func() {
+		selectedOutputFormats = nil
+	})
+
+	if err := loadOutputFormats(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadOutputFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats string
+		dir     string
+		wantErr string
+	}{
+		{name: "single format without a directory", formats: "json"},
+		{name: "several formats", formats: "json, terraform-hcl,html", dir: "out"},
+		{name: "several formats without a directory", formats: "json,terraform-hcl", wantErr: "--output-dir is required"},
+		{name: "unknown format", formats: "json,yaml", dir: "out", wantErr: `unknown output format "yaml"`},
+		{name: "format missing its flags", formats: "json,github-oidc", dir: "out", wantErr: "requires --github-repo"},
+		{name: "no formats", formats: ",", wantErr: "at least one output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestFlag(t, "output-formats", tt.formats)
+			setTestFlag(t, "output-dir", tt.dir)
+			selectedOutputFormats = nil
+			defer func() {
+				selectedOutputFormats = nil
+			}()
+
+			err := loadOutputFormats()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteOutputFormats(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	dir := loadTestOutputFormats(t, "json,terraform-hcl,html")
+
+	if err := writeOutputFormats(); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := readTestOutputFile(t, filepath.Join(dir, "policy.json"))
+	if !strings.Contains(policy, "dynamodb:GetItem") {
+		t.Errorf("policy.json is missing dynamodb:GetItem:\n%s", policy)
+	}
+	if terraform := readTestOutputFile(t, filepath.Join(dir, "policy.tf")); !strings.Contains(terraform, `data "aws_iam_policy_document"`) {
+		t.Errorf("policy.tf is not a Terraform data source:\n%s", terraform)
+	}
+	if report := readTestOutputFile(t, filepath.Join(dir, "policy.html")); !strings.Contains(report, "<table") {
+		t.Errorf("policy.html has no calls table")
+	}
+}
+
+func TestWriteOutputFormatsSharedExtension(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	dir := loadTestOutputFormats(t, "json,scp,raw-actions")
+
+	if err := writeOutputFormats(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"policy-json.json", "policy-scp.json", "policy.txt"} {
+		readTestOutputFile(t, filepath.Join(dir, name))
+	}
+}
+
+func TestWriteOutputFormatsCompanions(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	setTestFlag(t, "github-repo", "octo-org/octo-repo")
+	setTestFlag(t, "role-arn", "arn:aws:iam::123456789012:role/app")
+	setTestFlag(t, "network-policy-mode", "true")
+	dir := loadTestOutputFormats(t, "github-oidc,kustomize-patch")
+
+	if err := writeOutputFormats(); err != nil {
+		t.Fatal(err)
+	}
+
+	trust := readTestOutputFile(t, filepath.Join(dir, "policy-trust.json"))
+	if !strings.Contains(trust, "sts:AssumeRoleWithWebIdentity") {
+		t.Errorf("the trust policy does not allow sts:AssumeRoleWithWebIdentity:\n%s", trust)
+	}
+	if patch := readTestOutputFile(t, filepath.Join(dir, "policy-policy.json")); !strings.Contains(patch, "s3:GetObject") {
+		t.Errorf("the kustomize-patch policy is missing s3:GetObject:\n%s", patch)
+	}
+	readTestOutputFile(t, filepath.Join(dir, "policy.yaml"))
+	readTestOutputFile(t, filepath.Join(dir, "policy-networkpolicy.yaml"))
+}
+
+func TestWriteOutputFormatsSplitPolicy(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	setTestFlag(t, "max-policy-size", "250")
+	dir := loadTestOutputFormats(t, "json,terraform-hcl")
+
+	if err := writeOutputFormats(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "policy.json")); !os.IsNotExist(err) {
+		t.Errorf("policy.json was written although the policy was split")
+	}
+	for i, action := range []string{"s3:GetObject", "dynamodb:GetItem"} {
+		file := filepath.Join(dir, "policy-"+string(rune('1'+i))+".json")
+		var policy IAMPolicy
+		if err := json.Unmarshal([]byte(readTestOutputFile(t, file)), &policy); err != nil {
+			t.Fatalf("%s is not a policy: %v", filepath.Base(file), err)
+		}
+		if len(policy.Statement) != 1 || policy.Statement[0].Action[0] != action {
+			t.Errorf("%s has statements %+v, want one for %s", filepath.Base(file), policy.Statement, action)
+		}
+	}
+	readTestOutputFile(t, filepath.Join(dir, "policy.tf"))
+}
+
+func TestWriteOutputFormatsError(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	dir := loadTestOutputFormats(t, "json,terraform-hcl")
+	if err := os.MkdirAll(filepath.Join(dir, "policy.tf"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeOutputFormats()
+	if err == nil || !strings.Contains(err.Error(), "terraform-hcl") {
+		t.Fatalf("got error %v, want one for terraform-hcl", err)
+	}
+	readTestOutputFile(t, filepath.Join(dir, "policy.json"))
+}
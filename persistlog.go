@@ -0,0 +1,272 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	_ "modernc.org/sqlite"
+)
+
+// persistedCall is the data of a call in the persistent log. The JSON encoding of Entry follows CSM datagrams and
+// leaves out the details added by iamlive, so those are stored alongside it.
+type persistedCall struct {
+	Entry
+	Timestamp          time.Time         `json:"Timestamp"`
+	CorrelationID      string            `json:"CorrelationId,omitempty"`
+	EventSource        string            `json:"EventSource,omitempty"`
+	ResponseBodyBytes  int64             `json:"ResponseBodyBytes,omitempty"`
+	TimeBucket         string            `json:"TimeBucket,omitempty"`
+	SourceAnnotation   string            `json:"SourceAnnotation,omitempty"`
+	SessionID          string            `json:"SessionId,omitempty"`
+	Headers            map[string]string `json:"Headers,omitempty"`
+	ResourceARNs       []string          `json:"ResourceArns,omitempty"`
+	AssumedRoleARN     string            `json:"AssumedRoleArn,omitempty"`
+	CallerAccessKeyID  string            `json:"CallerAccessKeyId,omitempty"`
+	CallerPrincipalARN string            `json:"CallerPrincipalArn,omitempty"`
+	SigningRegion      string            `json:"SigningRegion,omitempty"`
+}
+
+func newPersistedCall(entry Entry) persistedCall {
+	return persistedCall{
+		Entry:              entry,
+		Timestamp:          entry.Timestamp,
+		CorrelationID:      entry.CorrelationID,
+		EventSource:        entry.EventSource,
+		ResponseBodyBytes:  entry.ResponseBodyBytes,
+		TimeBucket:         entry.TimeBucket,
+		SourceAnnotation:   entry.SourceAnnotation,
+		SessionID:          entry.SessionID,
+		Headers:            entry.Headers,
+		ResourceARNs:       entry.ResourceARNs,
+		AssumedRoleARN:     entry.AssumedRoleARN,
+		CallerAccessKeyID:  entry.CallerAccessKeyID,
+		CallerPrincipalARN: entry.CallerPrincipalARN,
+		SigningRegion:      entry.SigningRegion,
+	}
+}
+
+func (call persistedCall) toEntry() Entry {
+	entry := call.Entry
+	entry.Timestamp = call.Timestamp
+	entry.CorrelationID = call.CorrelationID
+	entry.EventSource = call.EventSource
+	entry.ResponseBodyBytes = call.ResponseBodyBytes
+	entry.TimeBucket = call.TimeBucket
+	entry.SourceAnnotation = call.SourceAnnotation
+	entry.SessionID = call.SessionID
+	entry.Headers = call.Headers
+	entry.ResourceARNs = call.ResourceARNs
+	entry.AssumedRoleARN = call.AssumedRoleARN
+	entry.CallerAccessKeyID = call.CallerAccessKeyID
+	entry.CallerPrincipalARN = call.CallerPrincipalARN
+	entry.SigningRegion = call.SigningRegion
+
+	return entry
+}
+
+// persistentLogSchema creates the table of calls in a new persistent log. The details of each call are stored as
+// JSON in data, with the columns alongside for querying the log.
+const persistentLogSchema = `CREATE TABLE IF NOT EXISTS calls (
+	id INTEGER PRIMARY KEY,
+	captured_at TEXT,
+	service TEXT,
+	action TEXT,
+	region TEXT,
+	status_code INTEGER,
+	data BLOB
+)`
+
+// PersistentLog stores captured calls in a SQLite database, so that a capture session can continue across restarts
+type PersistentLog struct {
+	mutex sync.Mutex
+	path  string
+	db    *sql.DB
+}
+
+var persistentLog *PersistentLog
+
+// Append adds a call to the log
+func (l *PersistentLog) Append(entry Entry) {
+	data, err := json.Marshal(newPersistedCall(entry))
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, err = l.db.Exec(
+		"INSERT INTO calls (captured_at, service, action, region, status_code, data) VALUES (?, ?, ?, ?, ?, ?)",
+		entry.Timestamp.UTC().Format(time.RFC3339Nano), entry.Service, entry.Method, entry.Region, entry.FinalHTTPStatusCode, data,
+	)
+	if err != nil {
+		log.Printf("WARNING: could not write a call to %s: %v", l.path, err)
+	}
+}
+
+// Snapshot returns the calls in the log, including those stored by previous sessions
+func (l *PersistentLog) Snapshot() []Entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries, err := readPersistedCalls(l.db, l.path)
+	if err != nil {
+		log.Printf("WARNING: could not read %s: %v", l.path, err)
+	}
+	return entries
+}
+
+// Close closes the database of the log
+func (l *PersistentLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.db.Close()
+}
+
+// openPersistentLogDB opens the SQLite database of a persistent log, creating the table of calls if needed
+func openPersistentLogDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1) // writes are serialized by the log, and a single connection avoids locking errors
+
+	if _, err := db.Exec(persistentLogSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not open %s: %v", path, err)
+	}
+
+	return db, nil
+}
+
+// readPersistedCalls reads the calls in a persistent log in the order they were captured, skipping those whose
+// data cannot be parsed
+func readPersistedCalls(db *sql.DB, path string) ([]Entry, error) {
+	rows, err := db.Query("SELECT id, data FROM calls ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var id int64
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return entries, err
+		}
+
+		var call persistedCall
+		if err := json.Unmarshal(data, &call); err != nil {
+			log.Printf("WARNING: skipping call %d of %s: %v", id, path, err)
+			continue
+		}
+		entries = append(entries, call.toEntry())
+	}
+
+	return entries, rows.Err()
+}
+
+// openPersistentLog opens --persist-log, restoring the calls stored by previous sessions into the call log
+func openPersistentLog() error {
+	if *persistLogFlag == "" {
+		return nil
+	}
+
+	path, err := homedir.Expand(*persistLogFlag)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	db, err := openPersistentLogDB(path)
+	if err != nil {
+		return err
+	}
+	entries, err := readPersistedCalls(db, path)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	for _, entry := range entries {
+		callLog.Append(entry)
+	}
+
+	persistentLog = &PersistentLog{
+		path: path,
+		db:   db,
+	}
+	return nil
+}
+
+// isReplayedSession returns whether the calls of a capture session are replayed, following --replay-session-id and
+// --replay-exclude-session-id
+func isReplayedSession(sessionID string) bool {
+	if *replaySessionIDFlag != "" && sessionID != *replaySessionIDFlag {
+		return false
+	}
+
+	return *replayExcludeSessionIDFlag == "" || sessionID != *replayExcludeSessionIDFlag
+}
+
+// replayPersistentLog generates the policy from the calls in a --persist-log database, in the mode they were
+// captured in, for the replay-log command
+func replayPersistentLog(path string) error {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err // rather than creating an empty database
+	}
+
+	db, err := openPersistentLogDB(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	entries, err := readPersistedCalls(db, path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no calls were found in %s", path)
+	}
+
+	*modeFlag = "csm"
+	replayed := 0
+	for _, entry := range entries {
+		if !isReplayedSession(entry.SessionID) {
+			continue
+		}
+		if entry.Type == "ProxyCall" {
+			*modeFlag = "proxy"
+		}
+		callLog.Append(entry)
+		replayed++
+	}
+	if replayed == 0 {
+		return fmt.Errorf("no calls of the selected sessions were found in %s", path)
+	}
+	defer printWildcardSuggestions(os.Stderr)
+
+	if isPolicyFileOutput() {
+		writePolicyToFile()
+		return nil
+	}
+
+	fmt.Println(string(getPolicyOutput()))
+	return nil
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestPersistentLog opens --persist-log at a path as on start, closing it at the end of the test
+func openTestPersistentLog(t *testing.T, path string) {
+	t.Helper()
+
+	setTestFlag(t, "persist-log", path)
+	if err := openPersistentLog(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(closeTestPersistentLog)
+}
+
+// closeTestPersistentLog closes the persistent log as when the process exits
+func closeTestPersistentLog() {
+	if persistentLog != nil {
+		persistentLog.Close()
+		persistentLog = nil
+	}
+}
+
+// writeTestPersistentLog stores calls in a persistent log, as a capture session with --persist-log would
+func writeTestPersistentLog(t *testing.T, path string, entries []Entry) {
+	t.Helper()
+
+	db, err := openPersistentLogDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &PersistentLog{path: path, db: db}
+	defer l.Close()
+	for _, entry := range entries {
+		l.Append(entry)
+	}
+}
+
+// readTestPersistentLog reads the calls stored in a persistent log
+func readTestPersistentLog(t *testing.T, path string) []Entry {
+	t.Helper()
+
+	db, err := openPersistentLogDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	entries, err := readPersistedCalls(db, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func getTestPersistedEntry(i int) Entry {
+	services := []string{"S3", "EC2", "DynamoDB", "STS", "SQS"}
+	methods := []string{"ListBuckets", "DescribeInstances", "ListTables", "GetCallerIdentity", "ListQueues"}
+
+	return Entry{
+		Region:              []string{"us-east-1", "eu-west-1"}[i%2],
+		Type:                "ApiCall",
+		Service:             services[i%len(services)],
+		Method:              methods[i%len(methods)],
+		FinalHTTPStatusCode: []int{200, 200, 200, 403}[i%4],
+		Timestamp:           time.Date(2024, 3, 1, 10, 0, i, 0, time.UTC),
+		CorrelationID:       fmt.Sprintf("call-%d", i),
+		SessionID:           "session-1",
+		Headers:             map[string]string{"User-Agent": fmt.Sprintf("aws-cli/2.15.%d", i)},
+	}
+}
+
+func TestPersistentLogRestart(t *testing.T) {
+	resetTestCallLog(t)
+	path := filepath.Join(t.TempDir(), "logs", "calls.db")
+	setTestFlag(t, "session-id", "session-1")
+
+	openTestPersistentLog(t, path)
+	var want []Entry
+	for i := 0; i < 50; i++ {
+		entry := getTestPersistedEntry(i)
+		if !recordCall(entry) {
+			t.Fatalf("call %d was not recorded", i)
+		}
+		want = append(want, entry)
+	}
+
+	// restart with an empty call log, as a new process would
+	closeTestPersistentLog()
+	callLog.Reset()
+	openTestPersistentLog(t, path)
+
+	got := callLog.Snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("got %d calls after the restart, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Service != want[i].Service || got[i].Method != want[i].Method || got[i].Region != want[i].Region || got[i].FinalHTTPStatusCode != want[i].FinalHTTPStatusCode {
+			t.Errorf("call %d is %s.%s in %s (%d), want %s.%s in %s (%d)", i, got[i].Service, got[i].Method, got[i].Region, got[i].FinalHTTPStatusCode, want[i].Service, want[i].Method, want[i].Region, want[i].FinalHTTPStatusCode)
+		}
+		if !got[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("call %d has timestamp %s, want %s", i, got[i].Timestamp, want[i].Timestamp)
+		}
+		if got[i].CorrelationID != want[i].CorrelationID || got[i].SessionID != want[i].SessionID || !reflect.DeepEqual(got[i].Headers, want[i].Headers) {
+			t.Errorf("call %d has details %s %s %v, want %s %s %v", i, got[i].CorrelationID, got[i].SessionID, got[i].Headers, want[i].CorrelationID, want[i].SessionID, want[i].Headers)
+		}
+	}
+
+	// calls after the restart are appended to those of the previous session
+	recordCall(getTestPersistedEntry(50))
+	if got := len(persistentLog.Snapshot()); got != 51 {
+		t.Errorf("got %d persisted calls, want 51", got)
+	}
+}
+
+func TestPersistentLogColumns(t *testing.T) {
+	resetTestCallLog(t)
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	openTestPersistentLog(t, path)
+	for i := 0; i < 4; i++ {
+		recordCall(getTestPersistedEntry(i))
+	}
+
+	rows, err := persistentLog.db.Query("SELECT captured_at, service, action, region, status_code FROM calls ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var capturedAt, service, action, region string
+		var statusCode int
+		if err := rows.Scan(&capturedAt, &service, &action, &region, &statusCode); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s %s.%s %s %d", capturedAt, service, action, region, statusCode))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"2024-03-01T10:00:00Z S3.ListBuckets us-east-1 200",
+		"2024-03-01T10:00:01Z EC2.DescribeInstances eu-west-1 200",
+		"2024-03-01T10:00:02Z DynamoDB.ListTables us-east-1 200",
+		"2024-03-01T10:00:03Z STS.GetCallerIdentity eu-west-1 403",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got rows %v, want %v", got, want)
+	}
+}
+
+func TestPersistentLogCorruptCall(t *testing.T) {
+	resetTestCallLog(t)
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	writeTestPersistentLog(t, path, []Entry{getTestPersistedEntry(0)})
+	// a call whose data was written by hand or by another tool
+	db, err := openPersistentLogDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO calls (service, action, data) VALUES (?, ?, ?)", "EC2", "DescribeInstances", []byte(`{"Service":"EC2","Api`))
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openTestPersistentLog(t, path)
+	if got := callLog.Len(); got != 1 {
+		t.Fatalf("got %d restored calls, want 1", got)
+	}
+
+	recordCall(getTestPersistedEntry(2))
+	entries := persistentLog.Snapshot()
+	if len(entries) != 2 || entries[1].Service != "DynamoDB" {
+		t.Errorf("got persisted calls %+v, want the restored call and DynamoDB", entries)
+	}
+}
+
+func TestReplayPersistentLog(t *testing.T) {
+	resetTestCallLog(t)
+	setTestFlag(t, "mode", "proxy")
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	var entries []Entry
+	for i := 0; i < 50; i++ {
+		entries = append(entries, getTestPersistedEntry(i))
+	}
+	writeTestPersistentLog(t, path, entries)
+
+	file := filepath.Join(t.TempDir(), "policy.json")
+	setTestFlag(t, "output-file", file)
+	if err := replayPersistentLog(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := callLog.Len(); got != 50 {
+		t.Errorf("got %d replayed calls, want 50", got)
+	}
+	actions := getTestPolicyFileActions(t, file)
+	sort.Strings(actions)
+	want := []string{"dynamodb:ListTables", "ec2:DescribeInstances", "s3:ListAllMyBuckets", "sqs:ListQueues"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("got actions %v, want %v", actions, want)
+	}
+}
+
+func TestReplayPersistentLogSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	// two capture sessions stored in the same log
+	var entries []Entry
+	for i, call := range []struct {
+		sessionID string
+		service   string
+		method    string
+	}{
+		{sessionID: "deployment-1", service: "S3", method: "ListBuckets"},
+		{sessionID: "deployment-2", service: "EC2", method: "DescribeInstances"},
+		{sessionID: "deployment-1", service: "DynamoDB", method: "ListTables"},
+		{sessionID: "deployment-2", service: "SQS", method: "ListQueues"},
+	} {
+		entry := getTestPersistedEntry(i)
+		entry.SessionID = call.sessionID
+		entry.Service = call.service
+		entry.Method = call.method
+		entry.FinalHTTPStatusCode = 200
+		entries = append(entries, entry)
+	}
+	writeTestPersistentLog(t, path, entries)
+
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		wantActions []string
+		wantErr     string
+	}{
+		{name: "all sessions", wantActions: []string{"dynamodb:ListTables", "ec2:DescribeInstances", "s3:ListAllMyBuckets", "sqs:ListQueues"}},
+		{name: "one session", flags: map[string]string{"replay-session-id": "deployment-1"}, wantActions: []string{"dynamodb:ListTables", "s3:ListAllMyBuckets"}},
+		{name: "excluded session", flags: map[string]string{"replay-exclude-session-id": "deployment-1"}, wantActions: []string{"ec2:DescribeInstances", "sqs:ListQueues"}},
+		{name: "unknown session", flags: map[string]string{"replay-session-id": "deployment-3"}, wantErr: "no calls of the selected sessions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			setTestFlag(t, "mode", "proxy") // restored after the replay sets it
+			file := filepath.Join(t.TempDir(), "policy.json")
+			setTestFlag(t, "output-file", file)
+			for name, value := range tt.flags {
+				setTestFlag(t, name, value)
+			}
+
+			err := replayPersistentLog(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actions := getTestPolicyFileActions(t, file)
+			sort.Strings(actions)
+			if !reflect.DeepEqual(actions, tt.wantActions) {
+				t.Errorf("got actions %v, want %v", actions, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestPersistentLogEventSource(t *testing.T) {
+	resetTestCallLog(t)
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	// the deploy and test phases of a pipeline append to the same log
+	for i, eventSource := range []string{"deploy", "test"} {
+		setTestFlag(t, "event-source", eventSource)
+		openTestPersistentLog(t, path)
+		recordCall(getTestPersistedEntry(i))
+		closeTestPersistentLog()
+	}
+
+	entries := readTestPersistentLog(t, path)
+	var eventSources []string
+	for _, entry := range entries {
+		eventSources = append(eventSources, entry.EventSource)
+	}
+	if want := []string{"deploy", "test"}; !reflect.DeepEqual(eventSources, want) {
+		t.Errorf("got event sources %v, want %v", eventSources, want)
+	}
+}
+
+func TestPersistentLogSourceAnnotation(t *testing.T) {
+	resetTestCallLog(t)
+	path := filepath.Join(t.TempDir(), "calls.db")
+
+	for i, annotation := range []string{"integration-test-run-41", "integration-test-run-42"} {
+		setTestFlag(t, "entry-source-annotation", annotation)
+		openTestPersistentLog(t, path)
+		recordCall(getTestPersistedEntry(i))
+		closeTestPersistentLog()
+	}
+
+	entries := readTestPersistentLog(t, path)
+	var annotations []string
+	for _, entry := range entries {
+		annotations = append(annotations, entry.SourceAnnotation)
+	}
+	if want := []string{"integration-test-run-41", "integration-test-run-42"}; !reflect.DeepEqual(annotations, want) {
+		t.Errorf("got annotations %v, want %v", annotations, want)
+	}
+}
+
+func readTestOutputFile(t *testing.T, file string) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read %s: %v", filepath.Base(file), err)
+	}
+	if len(data) == 0 {
+		t.Fatalf("%s is empty", filepath.Base(file))
+	}
+	return string(data)
+}
+
+func getTestPolicyFileActions(t *testing.T, file string) []string {
+	t.Helper()
+
+	var policy IAMPolicy
+	if err := json.Unmarshal([]byte(readTestOutputFile(t, file)), &policy); err != nil {
+		t.Fatalf("the policy is not valid JSON: %v", err)
+	}
+
+	var actions []string
+	for _, statement := range policy.Statement {
+		actions = append(actions, statement.Action...)
+	}
+	sort.Strings(actions)
+	return actions
+}
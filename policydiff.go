@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// diffStatement is a policy statement as written by hand or by other tools, where Action and Resource may be
+// either a string or a list
+type diffStatement struct {
+	Sid      string      `json:"Sid"`
+	Effect   string      `json:"Effect"`
+	Action   interface{} `json:"Action"`
+	Resource interface{} `json:"Resource"`
+}
+
+type diffPolicy struct {
+	Statement []diffStatement `json:"Statement"`
+}
+
+// ResourceChange lists the resources added to and removed from a statement found in both policies
+type ResourceChange struct {
+	Statement        string   `json:"statement"`
+	AddedResources   []string `json:"addedResources"`
+	RemovedResources []string `json:"removedResources"`
+}
+
+// PolicyDiff is the difference between two policies
+type PolicyDiff struct {
+	AddedActions    []string         `json:"addedActions"`
+	RemovedActions  []string         `json:"removedActions"`
+	ResourceChanges []ResourceChange `json:"resourceChanges"`
+}
+
+// parseCommand returns the command given after the flags (e.g. diff) and its arguments, parsing any flags given
+// after the arguments as well
+func parseCommand() (string, []string) {
+	if flag.NArg() == 0 {
+		return "", nil
+	}
+
+	command := flag.Arg(0)
+	args := flag.Args()[1:]
+	var commandArgs []string
+	for len(args) > 0 {
+		flag.CommandLine.Parse(args)
+		args = flag.Args()
+		if len(args) > 0 {
+			commandArgs = append(commandArgs, args[0])
+			args = args[1:]
+		}
+	}
+
+	return command, commandArgs
+}
+
+func getStringList(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var list []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+
+	return nil
+}
+
+func readDiffPolicy(path string) (diffPolicy, error) {
+	var policy diffPolicy
+
+	policyPath, err := homedir.Expand(path)
+	if err != nil {
+		return policy, err
+	}
+	data, err := ioutil.ReadFile(policyPath)
+	if err != nil {
+		return policy, err
+	}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("could not parse the policy in %s: %v", path, err)
+	}
+
+	return policy, nil
+}
+
+// getDiffAction returns how an action is compared, actions are case-insensitive and denied actions are kept apart
+func getDiffAction(effect string, action string) string {
+	if effect == "Deny" {
+		return action + " (Deny)"
+	}
+	return action
+}
+
+// getPolicyDiffActions returns the actions of a policy keyed by their lowercase form
+func getPolicyDiffActions(policy diffPolicy) map[string]string {
+	actions := make(map[string]string)
+	for _, statement := range policy.Statement {
+		for _, action := range getStringList(statement.Action) {
+			diffAction := getDiffAction(statement.Effect, action)
+			actions[strings.ToLower(diffAction)] = diffAction
+		}
+	}
+	return actions
+}
+
+// getDiffStatementKey identifies a statement across the two policies, by its Sid or otherwise its actions
+func getDiffStatementKey(statement diffStatement) string {
+	if statement.Sid != "" {
+		return statement.Sid
+	}
+
+	var actions []string
+	for _, action := range getStringList(statement.Action) {
+		actions = append(actions, getDiffAction(statement.Effect, action))
+	}
+	sort.Strings(actions)
+	return strings.Join(actions, ",")
+}
+
+// getSetDifference returns the values of a that are not in b, as an empty list rather than nil so that the JSON
+// diff has no nulls
+func getSetDifference(a map[string]string, b map[string]string) []string {
+	difference := []string{}
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			difference = append(difference, value)
+		}
+	}
+	sort.Strings(difference)
+	return difference
+}
+
+// getPolicyDiff compares the actions of two policies, and the resources of the statements found in both
+func getPolicyDiff(oldPolicy diffPolicy, newPolicy diffPolicy) PolicyDiff {
+	oldActions := getPolicyDiffActions(oldPolicy)
+	newActions := getPolicyDiffActions(newPolicy)
+
+	diff := PolicyDiff{
+		AddedActions:    getSetDifference(newActions, oldActions),
+		RemovedActions:  getSetDifference(oldActions, newActions),
+		ResourceChanges: []ResourceChange{},
+	}
+
+	oldResources := make(map[string]map[string]string)
+	for _, statement := range oldPolicy.Statement {
+		key := getDiffStatementKey(statement)
+		if oldResources[key] == nil {
+			oldResources[key] = make(map[string]string)
+		}
+		for _, resource := range getStringList(statement.Resource) {
+			oldResources[key][resource] = resource
+		}
+	}
+
+	newResources := make(map[string]map[string]string)
+	var keys []string
+	for _, statement := range newPolicy.Statement {
+		key := getDiffStatementKey(statement)
+		if newResources[key] == nil {
+			newResources[key] = make(map[string]string)
+			keys = append(keys, key)
+		}
+		for _, resource := range getStringList(statement.Resource) {
+			newResources[key][resource] = resource
+		}
+	}
+
+	for _, key := range keys {
+		if oldResources[key] == nil {
+			continue // a new statement, whose actions are reported as added
+		}
+
+		change := ResourceChange{
+			Statement:        key,
+			AddedResources:   getSetDifference(newResources[key], oldResources[key]),
+			RemovedResources: getSetDifference(oldResources[key], newResources[key]),
+		}
+		if len(change.AddedResources) > 0 || len(change.RemovedResources) > 0 {
+			diff.ResourceChanges = append(diff.ResourceChanges, change)
+		}
+	}
+
+	return diff
+}
+
+func (diff PolicyDiff) isEmpty() bool {
+	return len(diff.AddedActions) == 0 && len(diff.RemovedActions) == 0 && len(diff.ResourceChanges) == 0
+}
+
+// formatPolicyDiff writes the added and removed actions grouped by service, followed by the resource changes of
+// each statement
+func formatPolicyDiff(diff PolicyDiff) string {
+	var sb strings.Builder
+
+	lines := make(map[string][]string)
+	for _, action := range diff.AddedActions {
+		service := strings.ToLower(strings.SplitN(action, ":", 2)[0])
+		lines[service] = append(lines[service], "+ "+action)
+	}
+	for _, action := range diff.RemovedActions {
+		service := strings.ToLower(strings.SplitN(action, ":", 2)[0])
+		lines[service] = append(lines[service], "- "+action)
+	}
+
+	var services []string
+	for service := range lines {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(service + "\n")
+		for _, line := range lines[service] {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+
+	for _, change := range diff.ResourceChanges {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("Resources of " + change.Statement + "\n")
+		for _, resource := range change.AddedResources {
+			sb.WriteString("  + " + resource + "\n")
+		}
+		for _, resource := range change.RemovedResources {
+			sb.WriteString("  - " + resource + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// runPolicyDiff compares the policy files given to the diff command, returning 0 when they grant the same
+// permissions, 1 when they differ and 2 when they could not be compared
+func runPolicyDiff(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: iamlive diff [--diff-format text|json] <old-policy.json> <new-policy.json>")
+		return 2
+	}
+	if *diffFormatFlag != "text" && *diffFormatFlag != "json" {
+		fmt.Fprintln(os.Stderr, "ERROR: --diff-format must be text or json")
+		return 2
+	}
+
+	oldPolicy, err := readDiffPolicy(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return 2
+	}
+	newPolicy, err := readDiffPolicy(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return 2
+	}
+
+	diff := getPolicyDiff(oldPolicy, newPolicy)
+
+	if *diffFormatFlag == "json" {
+		doc, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(doc))
+	} else {
+		fmt.Print(formatPolicyDiff(diff))
+	}
+
+	if diff.isEmpty() {
+		return 0
+	}
+	return 1
+}
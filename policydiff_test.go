@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runTestPolicyDiff runs iamlive diff on two policy files in testdata/policydiff, returning its output and exit code
+func runTestPolicyDiff(t *testing.T, format string, oldFile string, newFile string) (string, int) {
+	t.Helper()
+
+	setTestFlag(t, "diff-format", format)
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	exitCode := runPolicyDiff([]string{filepath.Join("testdata", "policydiff", oldFile), filepath.Join("testdata", "policydiff", newFile)})
+	w.Close()
+	os.Stdout = stdout
+
+	output, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output), exitCode
+}
+
+func TestPolicyDiffText(t *testing.T) {
+	tests := []struct {
+		name     string
+		newFile  string
+		want     string
+		wantExit int
+	}{
+		{
+			name:     "no differences",
+			newFile:  "before.json",
+			want:     "",
+			wantExit: 0,
+		},
+		{
+			name:     "single action",
+			newFile:  "single_action.json",
+			want:     "s3\n  + s3:PutObject\n",
+			wantExit: 1,
+		},
+		{
+			name:    "multiple actions in one statement",
+			newFile: "multiple_actions.json",
+			want: "dynamodb\n  + dynamodb:Query\n  + dynamodb:Scan\n  - dynamodb:GetItem\n" +
+				"\nec2\n  + ec2:DescribeInstances\n  + ec2:DescribeVpcs\n",
+			wantExit: 1,
+		},
+		{
+			name:    "resources",
+			newFile: "resources.json",
+			want: "Resources of Storage\n  + arn:aws:s3:::archive\n  + arn:aws:s3:::archive/*\n  - arn:aws:s3:::reports/*\n" +
+				"\nResources of dynamodb:GetItem\n  + arn:aws:dynamodb:us-east-1:123456789012:table/customers\n",
+			wantExit: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exitCode := runTestPolicyDiff(t, "text", "before.json", tt.newFile)
+			if got != tt.want {
+				t.Errorf("got diff\n%s\nwant\n%s", got, tt.want)
+			}
+			if exitCode != tt.wantExit {
+				t.Errorf("got exit code %d, want %d", exitCode, tt.wantExit)
+			}
+		})
+	}
+}
+
+func TestPolicyDiffJSON(t *testing.T) {
+	output, exitCode := runTestPolicyDiff(t, "json", "multiple_actions.json", "resources.json")
+	if exitCode != 1 {
+		t.Errorf("got exit code %d, want 1", exitCode)
+	}
+
+	var got PolicyDiff
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("the diff is not JSON: %v\n%s", err, output)
+	}
+	want := PolicyDiff{
+		AddedActions:   []string{"dynamodb:GetItem"},
+		RemovedActions: []string{"dynamodb:Query", "dynamodb:Scan", "ec2:DescribeInstances", "ec2:DescribeVpcs"},
+		ResourceChanges: []ResourceChange{{
+			Statement:        "Storage",
+			AddedResources:   []string{"arn:aws:s3:::archive", "arn:aws:s3:::archive/*"},
+			RemovedResources: []string{"arn:aws:s3:::reports/*"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// no differences are empty lists rather than null, for CI tools
+	output, exitCode = runTestPolicyDiff(t, "json", "before.json", "before.json")
+	if exitCode != 0 {
+		t.Errorf("got exit code %d, want 0", exitCode)
+	}
+	var empty map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &empty); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"addedActions", "removedActions", "resourceChanges"} {
+		if list, ok := empty[key].([]interface{}); !ok || len(list) != 0 {
+			t.Errorf("got %s %v, want an empty list", key, empty[key])
+		}
+	}
+}
+
+func TestPolicyDiffErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		oldFile string
+	}{
+		{name: "missing file", format: "text", oldFile: "missing.json"},
+		{name: "invalid format", format: "yaml", oldFile: "before.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, exitCode := runTestPolicyDiff(t, tt.format, tt.oldFile, "before.json"); exitCode != 2 {
+				t.Errorf("got exit code %d, want 2", exitCode)
+			}
+		})
+	}
+
+	if exitCode := runPolicyDiff([]string{"before.json"}); exitCode != 2 {
+		t.Errorf("got exit code %d for a single file, want 2", exitCode)
+	}
+}
+
+func TestGetPolicyDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldPolicy   string
+		newPolicy   string
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{
+			name:      "actions differing in case",
+			oldPolicy: `{"Statement": [{"Effect": "Allow", "Action": "s3:getobject", "Resource": "*"}]}`,
+			newPolicy: `{"Statement": [{"Effect": "Allow", "Action": ["S3:GetObject"], "Resource": "*"}]}`,
+		},
+		{
+			name:        "allowed action becomes denied",
+			oldPolicy:   `{"Statement": [{"Effect": "Allow", "Action": "iam:PassRole", "Resource": "*"}]}`,
+			newPolicy:   `{"Statement": [{"Effect": "Deny", "Action": "iam:PassRole", "Resource": "*"}]}`,
+			wantAdded:   []string{"iam:PassRole (Deny)"},
+			wantRemoved: []string{"iam:PassRole"},
+		},
+		{
+			name:      "actions moved between statements",
+			oldPolicy: `{"Statement": [{"Sid": "A", "Effect": "Allow", "Action": ["sqs:SendMessage", "sqs:ReceiveMessage"], "Resource": "*"}]}`,
+			newPolicy: `{"Statement": [{"Sid": "A", "Effect": "Allow", "Action": "sqs:SendMessage", "Resource": "*"}, {"Sid": "B", "Effect": "Allow", "Action": "sqs:ReceiveMessage", "Resource": "*"}]}`,
+		},
+		{
+			name:      "empty policy",
+			oldPolicy: `{"Statement": []}`,
+			newPolicy: `{"Statement": [{"Effect": "Allow", "Action": ["sns:Publish", "kms:Decrypt"], "Resource": "*"}]}`,
+			wantAdded: []string{"kms:Decrypt", "sns:Publish"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oldPolicy, newPolicy diffPolicy
+			if err := json.Unmarshal([]byte(tt.oldPolicy), &oldPolicy); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.newPolicy), &newPolicy); err != nil {
+				t.Fatal(err)
+			}
+
+			diff := getPolicyDiff(oldPolicy, newPolicy)
+			if strings.Join(diff.AddedActions, ",") != strings.Join(tt.wantAdded, ",") || strings.Join(diff.RemovedActions, ",") != strings.Join(tt.wantRemoved, ",") {
+				t.Errorf("got added %v and removed %v, want %v and %v", diff.AddedActions, diff.RemovedActions, tt.wantAdded, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// managedPolicySizeLimit is the maximum number of characters, excluding whitespace, in a managed policy document
+const managedPolicySizeLimit = 6144
+
+// getPolicySize returns the number of characters in a policy document with the statements, as counted by IAM
+func getPolicySize(statements []Statement) int {
+	doc, err := json.Marshal(IAMPolicy{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return len(doc)
+}
+
+// splitPolicyStatements splits statements at statement boundaries into groups that each fit in a policy document
+// of the given size. A statement too large to fit by itself is kept in a group of its own.
+func splitPolicyStatements(statements []Statement, maxSize int) [][]Statement {
+	emptySize := getPolicySize([]Statement{})
+
+	var groups [][]Statement
+	var group []Statement
+	size := emptySize
+	for _, statement := range statements {
+		statementSize := getPolicySize([]Statement{statement}) - emptySize
+		if len(group) > 0 && size+1+statementSize > maxSize { // statements are separated by a comma
+			groups = append(groups, group)
+			group = nil
+			size = emptySize
+		}
+		if len(group) > 0 {
+			size++
+		}
+		group = append(group, statement)
+		size += statementSize
+	}
+	if len(group) > 0 || len(groups) == 0 {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// validateMaxPolicySize checks --max-policy-size leaves room for a statement
+func validateMaxPolicySize() error {
+	if *maxPolicySizeFlag <= getPolicySize([]Statement{}) {
+		return fmt.Errorf("--max-policy-size must be greater than %d", getPolicySize([]Statement{}))
+	}
+	return nil
+}
+
+// getStatementService returns the service of the first action in a statement
+func getStatementService(statement Statement) string {
+	if len(statement.Action) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.SplitN(statement.Action[0], ":", 2)[0])
+}
+
+// checkAndSplitPolicy splits a policy larger than maxBytes into several documents that each fit, keeping the
+// statements of a service in the same document where they fit in one
+func checkAndSplitPolicy(policy IAMPolicy, maxBytes int) []IAMPolicy {
+	if getPolicySize(policy.Statement) <= maxBytes {
+		return []IAMPolicy{policy}
+	}
+
+	var services []string
+	serviceStatements := make(map[string][]Statement)
+	for _, statement := range policy.Statement {
+		service := getStatementService(statement)
+		if _, ok := serviceStatements[service]; !ok {
+			services = append(services, service)
+		}
+		serviceStatements[service] = append(serviceStatements[service], statement)
+	}
+
+	var groups [][]Statement
+	var group []Statement
+	for _, service := range services {
+		statements := serviceStatements[service]
+		combined := append(append([]Statement{}, group...), statements...)
+		if getPolicySize(combined) <= maxBytes {
+			group = combined
+			continue
+		}
+
+		if getPolicySize(statements) <= maxBytes { // the service fits in a document of its own
+			groups = append(groups, group)
+			group = statements
+			continue
+		}
+
+		parts := splitPolicyStatements(combined, maxBytes)
+		groups = append(groups, parts[:len(parts)-1]...)
+		group = parts[len(parts)-1]
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	var policies []IAMPolicy
+	for _, statements := range groups {
+		policies = append(policies, IAMPolicy{
+			Version:   policy.Version,
+			Statement: statements,
+		})
+	}
+
+	return policies
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// getTestServiceStatements returns statements of a service with enough actions to make them large
+func getTestServiceStatements(service string, count int) []Statement {
+	var statements []Statement
+	for i := 0; i < count; i++ {
+		var actions []string
+		for j := 0; j < 30; j++ {
+			actions = append(actions, fmt.Sprintf("%s:DescribeSomethingRatherLong%02d%02d", service, i, j))
+		}
+		statements = append(statements, Statement{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: []string{fmt.Sprintf("arn:aws:%s:us-east-1:123456789012:thing/%d", service, i)},
+		})
+	}
+	return statements
+}
+
+// getTestPolicyServices returns the services of the statements in each policy document
+func getTestPolicyServices(policies []IAMPolicy) [][]string {
+	var services [][]string
+	for _, policy := range policies {
+		var policyServices []string
+		for _, statement := range policy.Statement {
+			service := getStatementService(statement)
+			if len(policyServices) == 0 || policyServices[len(policyServices)-1] != service {
+				policyServices = append(policyServices, service)
+			}
+		}
+		services = append(services, policyServices)
+	}
+	return services
+}
+
+func TestCheckAndSplitPolicy(t *testing.T) {
+	var statements []Statement
+	for _, service := range []string{"ec2", "s3", "dynamodb"} {
+		statements = append(statements, getTestServiceStatements(service, 3)...)
+	}
+	policy := IAMPolicy{Version: "2012-10-17", Statement: statements}
+	serviceSize := getPolicySize(getTestServiceStatements("dynamodb", 3))
+	statementSize := getPolicySize(getTestServiceStatements("dynamodb", 1))
+
+	tests := []struct {
+		name         string
+		maxBytes     int
+		wantServices [][]string
+		wantParts    []int
+	}{
+		{
+			name:         "under the limit",
+			maxBytes:     getPolicySize(statements),
+			wantServices: [][]string{{"ec2", "s3", "dynamodb"}},
+			wantParts:    []int{9},
+		},
+		{
+			name:         "three-way split by service",
+			maxBytes:     serviceSize + statementSize/2,
+			wantServices: [][]string{{"ec2"}, {"s3"}, {"dynamodb"}},
+			wantParts:    []int{3, 3, 3},
+		},
+		{
+			name:         "services too large for a document",
+			maxBytes:     serviceSize - statementSize/2,
+			wantServices: [][]string{{"ec2"}, {"ec2", "s3"}, {"s3"}, {"dynamodb"}, {"dynamodb"}},
+			wantParts:    []int{2, 2, 2, 2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := checkAndSplitPolicy(policy, tt.maxBytes)
+
+			var parts []int
+			var got []Statement
+			for _, part := range policies {
+				if size := getPolicySize(part.Statement); size > tt.maxBytes {
+					t.Errorf("got a document of %d characters, want at most %d", size, tt.maxBytes)
+				}
+				if part.Version != "2012-10-17" {
+					t.Errorf("got version %q, want 2012-10-17", part.Version)
+				}
+				parts = append(parts, len(part.Statement))
+				got = append(got, part.Statement...)
+			}
+
+			// statements are kept whole and in order
+			if !reflect.DeepEqual(got, statements) {
+				t.Errorf("the statements were changed by the split")
+			}
+			if services := getTestPolicyServices(policies); !reflect.DeepEqual(services, tt.wantServices) {
+				t.Errorf("got services %v, want %v", services, tt.wantServices)
+			}
+			if !reflect.DeepEqual(parts, tt.wantParts) {
+				t.Errorf("got statement counts %v, want %v", parts, tt.wantParts)
+			}
+		})
+	}
+}
+
+func TestSplitPolicyStatements(t *testing.T) {
+	statements := getTestServiceStatements("s3", 3)
+	statementSize := getPolicySize(statements[:1])
+
+	tests := []struct {
+		name       string
+		statements []Statement
+		maxSize    int
+		wantGroups []int
+	}{
+		{name: "no statements", maxSize: statementSize, wantGroups: []int{0}},
+		{name: "one statement a group", statements: statements, maxSize: statementSize, wantGroups: []int{1, 1, 1}},
+		{name: "two statements a group", statements: statements, maxSize: getPolicySize(statements[:2]), wantGroups: []int{2, 1}},
+		{name: "statement larger than the limit", statements: statements, maxSize: statementSize / 2, wantGroups: []int{1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, group := range splitPolicyStatements(tt.statements, tt.maxSize) {
+				got = append(got, len(group))
+			}
+			if !reflect.DeepEqual(got, tt.wantGroups) {
+				t.Errorf("got groups of %v statements, want %v", got, tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestWriteSplitPolicyFiles(t *testing.T) {
+	resetTestCallLog(t)
+	appendTestResourceCalls()
+	policySplitCount = 1
+	t.Cleanup(func() {
+		policySplitCount = 1
+	})
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	// a limit each statement fits in by itself, but not the whole policy
+	policy := getPolicy()
+	largest := 0
+	for _, statement := range policy.Statement {
+		if size := getPolicySize([]Statement{statement}); size > largest {
+			largest = size
+		}
+	}
+	maxSize := largest + 1
+	if getPolicySize(policy.Statement) <= maxSize {
+		t.Fatalf("the policy fits in %d characters", maxSize)
+	}
+	setTestFlag(t, "max-policy-size", fmt.Sprint(maxSize))
+
+	dir := t.TempDir()
+	files, err := writeSplitPolicyFiles(filepath.Join(dir, "policy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 2 {
+		t.Fatalf("got files %v, want the policy split", files)
+	}
+
+	var actions []string
+	for i, file := range files {
+		if want := filepath.Join(dir, fmt.Sprintf("policy-%d.json", i+1)); file != want {
+			t.Errorf("got file %s, want %s", file, want)
+		}
+		var part IAMPolicy
+		if err := json.Unmarshal([]byte(readTestOutputFile(t, file)), &part); err != nil {
+			t.Fatal(err)
+		}
+		if size := getPolicySize(part.Statement); size > maxSize {
+			t.Errorf("%s has %d characters, want at most %d", file, size, maxSize)
+		}
+		actions = append(actions, getTestPolicyFileActions(t, file)...)
+	}
+	var wantActions []string
+	for _, statement := range policy.Statement {
+		wantActions = append(wantActions, statement.Action...)
+	}
+	sort.Strings(actions)
+	sort.Strings(wantActions)
+	if !reflect.DeepEqual(actions, wantActions) {
+		t.Errorf("got actions %v across the files, want %v", actions, wantActions)
+	}
+
+	want := fmt.Sprintf("Policy document exceeded limit; split into %d files: %s.", len(files), strings.Join(files, ", "))
+	if !strings.Contains(logs.String(), want) {
+		t.Errorf("got logs %q, want %q", logs.String(), want)
+	}
+
+	// the split is reported again only once it changes
+	logs.Reset()
+	if _, err := writeSplitPolicyFiles(filepath.Join(dir, "policy.json")); err != nil {
+		t.Fatal(err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("the unchanged split was reported again: %q", logs.String())
+	}
+
+	setTestFlag(t, "max-policy-size", fmt.Sprint(getPolicySize(policy.Statement)))
+	if files, err := writeSplitPolicyFiles(filepath.Join(dir, "policy.json")); err != nil || len(files) != 0 {
+		t.Errorf("got files %v and error %v, want none for a policy within the limit", files, err)
+	}
+}
+
+func TestValidateMaxPolicySize(t *testing.T) {
+	emptySize := getPolicySize([]Statement{})
+
+	setTestFlag(t, "max-policy-size", fmt.Sprint(emptySize))
+	if err := validateMaxPolicySize(); err == nil {
+		t.Errorf("got no error for a limit with no room for a statement")
+	}
+
+	setTestFlag(t, "max-policy-size", "10240")
+	if err := validateMaxPolicySize(); err != nil {
+		t.Errorf("got error %v for the inline policy limit", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// principalMap maps access key IDs to the ARNs of their principals, from --principal-map
+var principalMap map[string]string
+
+// loadPrincipalMap reads the --principal-map JSON file, an object of access key IDs to principal ARNs
+func loadPrincipalMap() error {
+	if *splitByPrincipalFlag && (*principalMapFlag == "" || *outputFileFlag == "") {
+		return fmt.Errorf("--split-by-principal requires --principal-map and --output-file")
+	}
+	if *principalMapFlag == "" {
+		return nil
+	}
+
+	principalMapPath, err := homedir.Expand(*principalMapFlag)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(principalMapPath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &principalMap); err != nil {
+		return fmt.Errorf("could not parse --principal-map, expected an object of access key IDs to principal ARNs: %v", err)
+	}
+
+	return nil
+}
+
+// getRequestCredentialScope returns the credential scope a request was signed with, from its Authorization
+// header or the X-Amz-Credential parameter of a presigned URL
+func getRequestCredentialScope(req *http.Request) *SigV4Authorization {
+	if auth, err := parseSigV4Authorization(req.Header.Get("Authorization")); err == nil {
+		return auth
+	}
+
+	scope := strings.Split(req.URL.Query().Get("X-Amz-Credential"), "/")
+	if len(scope) != 5 || scope[4] != "aws4_request" {
+		return nil
+	}
+	return &SigV4Authorization{
+		AccessKeyID: scope[0],
+		Date:        scope[1],
+		Region:      scope[2],
+		Service:     scope[3],
+	}
+}
+
+// setCallerDetails sets the access key, principal and signing region of the credentials that signed a request
+func setCallerDetails(entry *Entry, req *http.Request) {
+	auth := getRequestCredentialScope(req)
+	if auth == nil {
+		return
+	}
+
+	entry.CallerAccessKeyID = auth.AccessKeyID
+	entry.SigningRegion = auth.Region
+	entry.CallerPrincipalARN = principalMap[auth.AccessKeyID]
+}
+
+var principalFileNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// getPrincipalFileSuffix returns the suffix of the --split-by-principal policy file of a principal, such as
+// -123456789012-role-deploy for arn:aws:iam::123456789012:role/deploy
+func getPrincipalFileSuffix(principalARN string) string {
+	name := principalARN
+	if parts := strings.SplitN(principalARN, ":", 6); len(parts) == 6 {
+		name = parts[4] + "-" + parts[5]
+	}
+
+	return "-" + strings.Trim(principalFileNameRegexp.ReplaceAllString(name, "-"), "-")
+}
+
+// writePrincipalPoliciesToFiles writes a policy for each principal of --principal-map that made the captured
+// calls, beside --output-file
+func writePrincipalPoliciesToFiles() {
+	principalEntries := make(map[string][]Entry)
+	for _, entry := range getPolicyEntries() {
+		if entry.CallerPrincipalARN != "" {
+			principalEntries[entry.CallerPrincipalARN] = append(principalEntries[entry.CallerPrincipalARN], entry)
+		}
+	}
+
+	var principalARNs []string
+	for principalARN := range principalEntries {
+		principalARNs = append(principalARNs, principalARN)
+	}
+	sort.Strings(principalARNs)
+
+	for _, principalARN := range principalARNs {
+		doc, err := json.MarshalIndent(getPolicyForEntries(principalEntries[principalARN]), "", "    ")
+		if err != nil {
+			panic(err)
+		}
+
+		file := getAdditionalOutputFile(getPrincipalFileSuffix(principalARN))
+		err = ioutil.WriteFile(file, doc, 0644)
+		if err != nil {
+			log.Fatalf("Error writing policy to %s", file)
+		}
+	}
+}
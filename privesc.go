@@ -0,0 +1,68 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed privesc/paths.json
+var bPrivilegeEscalationPaths []byte
+
+// PrivilegeEscalationPath is a combination of actions known to allow a principal to escalate its privileges
+type PrivilegeEscalationPath struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Actions     []string `json:"actions"`
+}
+
+var privilegeEscalationPaths []PrivilegeEscalationPath
+
+func loadPrivilegeEscalationPaths() error {
+	if !*detectPrivilegeEscalationFlag && !isOutputFormatSelected("scout-suite") {
+		return nil
+	}
+
+	err := json.Unmarshal(bPrivilegeEscalationPaths, &privilegeEscalationPaths)
+	if err != nil {
+		return fmt.Errorf("invalid privilege escalation paths: %v", err)
+	}
+
+	return nil
+}
+
+// getPrivilegeEscalationPaths returns the escalation paths fully covered by the actions in the policy
+func getPrivilegeEscalationPaths() []PrivilegeEscalationPath {
+	capturedActions := make(map[string]bool)
+	for _, action := range getCapturedActions() {
+		capturedActions[strings.ToLower(action)] = true
+	}
+
+	var paths []PrivilegeEscalationPath
+	for _, path := range privilegeEscalationPaths {
+		covered := true
+		for _, action := range path.Actions {
+			if !capturedActions[strings.ToLower(action)] {
+				covered = false
+				break
+			}
+		}
+
+		if covered {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// getPrivilegeEscalationNotes warns of each escalation path fully covered by the actions in the policy
+func getPrivilegeEscalationNotes() []string {
+	var notes []string
+	for _, path := range getPrivilegeEscalationPaths() {
+		notes = append(notes, fmt.Sprintf("WARNING: %s allows privilege escalation (%s): %s", strings.Join(path.Actions, " + "), path.Name, path.Description))
+	}
+
+	return notes
+}
@@ -2,25 +2,21 @@ package main
 
 import (
 	"bytes"
-	"crypto/rand"
-	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
-	"crypto/x509/pkix"
 	"embed"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
-	"math/big"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/elazarl/goproxy"
@@ -31,6 +27,7 @@ import (
 var serviceFiles embed.FS
 
 var serviceDefinitions []ServiceDefinition
+var latestServiceDefinitions map[string]ServiceDefinition
 
 func loadCAKeys() error {
 	var caCert []byte
@@ -45,6 +42,16 @@ func loadCAKeys() error {
 		return err
 	}
 
+	if *caKeyTypeFlag != caKeyTypeRSA && *caKeyTypeFlag != caKeyTypeECDSA {
+		return fmt.Errorf("--ca-key-type must be %s or %s", caKeyTypeRSA, caKeyTypeECDSA)
+	}
+	if *rotateCAFlag {
+		err = backUpCAFiles(caBundlePath, caKeyPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	if _, err := os.Stat(caBundlePath); os.IsNotExist(err) {
 		if _, err := os.Stat(caKeyPath); os.IsNotExist(err) {
 			// make directories
@@ -57,50 +64,11 @@ func loadCAKeys() error {
 				return err
 			}
 
-			// generate keys
-			ca := &x509.Certificate{
-				SerialNumber: big.NewInt(2019),
-				Subject: pkix.Name{
-					Organization:  []string{"iamlive CA"},
-					Country:       []string{"US"},
-					Province:      []string{""},
-					Locality:      []string{"San Francisco"},
-					StreetAddress: []string{"Golden Gate Bridge"},
-					PostalCode:    []string{"94016"},
-				},
-				NotBefore:             time.Now(),
-				NotAfter:              time.Now().AddDate(10, 0, 0),
-				IsCA:                  true,
-				ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-				KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-				BasicConstraintsValid: true,
-			}
-
-			caPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+			caCert, caKey, err = generateCA(*caKeyTypeFlag)
 			if err != nil {
 				return err
 			}
 
-			caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, &caPrivKey.PublicKey, caPrivKey)
-			if err != nil {
-				return err
-			}
-
-			caPEM := new(bytes.Buffer)
-			pem.Encode(caPEM, &pem.Block{
-				Type:  "CERTIFICATE",
-				Bytes: caBytes,
-			})
-
-			caPrivKeyPEM := new(bytes.Buffer)
-			pem.Encode(caPrivKeyPEM, &pem.Block{
-				Type:  "RSA PRIVATE KEY",
-				Bytes: x509.MarshalPKCS1PrivateKey(caPrivKey),
-			})
-
-			caCert = caPEM.Bytes()
-			caKey = caPrivKeyPEM.Bytes()
-
 			// write data
 			err = ioutil.WriteFile(caBundlePath, caCert, 0600)
 			if err != nil {
@@ -126,6 +94,25 @@ func loadCAKeys() error {
 		if err != nil {
 			return err
 		}
+
+		keyType, err := getCAKeyType(caKey)
+		if err != nil {
+			return err
+		}
+		if _, ok := configSources["ca-key-type"]; ok && keyType != *caKeyTypeFlag {
+			log.Printf("WARNING: the existing CA has an %s key rather than %s, use --rotate-ca to generate a new CA", keyType, *caKeyTypeFlag)
+		}
+
+		if *caValidityFromExistingFlag > 0 {
+			caCert, err = extendCAValidity(caCert, caKey, *caValidityFromExistingFlag)
+			if err != nil {
+				return err
+			}
+			err = ioutil.WriteFile(caBundlePath, caCert, 0600)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	goproxyCa, err := tls.X509KeyPair(caCert, caKey)
@@ -135,6 +122,10 @@ func loadCAKeys() error {
 	if goproxyCa.Leaf, err = x509.ParseCertificate(goproxyCa.Certificate[0]); err != nil {
 		return err
 	}
+	err = checkCAExpiry(goproxyCa.Leaf, time.Now())
+	if err != nil {
+		return err
+	}
 	goproxy.GoproxyCa = goproxyCa
 	goproxy.OkConnect = &goproxy.ConnectAction{Action: goproxy.ConnectAccept, TLSConfig: goproxy.TLSConfigFromCA(&goproxyCa)}
 	goproxy.MitmConnect = &goproxy.ConnectAction{Action: goproxy.ConnectMitm, TLSConfig: goproxy.TLSConfigFromCA(&goproxyCa)}
@@ -149,22 +140,173 @@ func createProxy(addr string) {
 		log.Fatal(err)
 	}
 
+	proxy := newProxy()
+
+	listener, err := getProxyListener(addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(http.Serve(listener, proxy))
+}
+
+// newProxy returns the proxy with its request and response handlers, intercepting HTTPS with the CA loaded by
+// loadCAKeys
+func newProxy() *goproxy.ProxyHttpServer {
 	proxy := goproxy.NewProxyHttpServer()
 	proxy.Logger = log.New(io.Discard, "", log.LstdFlags)
+	if proxyCredentials != nil {
+		addProxyAuth(proxy)
+	}
+	if upstreamProxyURL != nil {
+		addUpstreamProxy(proxy)
+	}
 	proxy.OnRequest().HandleConnect(goproxy.AlwaysMitm)
-	proxy.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) { // TODO: Move to onResponse for HTTP response codes
-		body, _ := ioutil.ReadAll(req.Body)
+	proxy.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		body, passthrough := readRequestBody(req)
+		if passthrough != nil {
+			log.Printf("WARNING: Request timeout: body reading exceeded %s for %s%s", *perRequestTimeoutFlag, req.Host, req.URL.RequestURI())
+			req.Body = passthrough
+			ctx.UserData = &proxyRequestContext{
+				timestamp: time.Now(),
+			}
+			return req, nil
+		}
+		defer func() {
+			req.Body = ioutil.NopCloser(bytes.NewBuffer(body)) // re-inject the body however parsing ends
+		}()
+
+		reqCtx := &proxyRequestContext{
+			body:      body,
+			timestamp: time.Now(),
+		}
+
+		isAWSHostname := isAWSHost(req.Host)
+		if isAWSHostname || isLocalStackHost(req.Host) || getJSONPathMappingRule(req.Host) != nil {
+			// the call is recorded once its response status code is known
+			reqCtx.entry = parseAWSRequest(req, body, 0)
+			if reqCtx.entry != nil {
+				startInFlightCall()
+			}
+
+			if isOutputFormatSelected("github-secret-scanning") && isAWSHostname {
+				recordRequestAccessKey(req, reqCtx.entry)
+			}
 
-		isAWSHostname, _ := regexp.MatchString(`^.*\.amazonaws\.com(?:\.cn)?$`, req.Host)
-		if isAWSHostname {
-			handleAWSRequest(req, body, 200)
+			if *awsSingleUseCredentialsFlag && isAWSHostname {
+				if err := resignWithSingleUseCredentials(req, body, reqCtx.entry); err != nil {
+					// the call is failed rather than forwarded with the original credentials, which would not verify
+					// the policy
+					log.Printf("WARNING: could not re-sign the request to %s with single-use credentials: %v", req.Host, err)
+					ctx.UserData = reqCtx
+					return req, goproxy.NewResponse(req, goproxy.ContentTypeText, http.StatusBadGateway, fmt.Sprintf("iamlive could not re-sign the request with single-use credentials: %v", err))
+				}
+			}
 		}
 
-		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		ctx.UserData = reqCtx
 
 		return req, nil
 	})
-	log.Fatal(http.ListenAndServe(addr, proxy))
+	proxy.OnResponse().DoFunc(handleProxyResponse)
+
+	return proxy
+}
+
+// proxyRequestContext carries the details of a proxied request through to its response
+type proxyRequestContext struct {
+	body      []byte
+	timestamp time.Time
+	entry     *Entry
+}
+
+func handleProxyResponse(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+	reqCtx, ok := ctx.UserData.(*proxyRequestContext)
+	if !ok {
+		return resp
+	}
+
+	if *inferARNsFromTagsFlag && resp != nil && reqCtx.entry != nil && isTaggingRequest(ctx.Req, reqCtx.body) {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		cacheTaggedResourceARNs(ctx.Req, reqCtx.body, respBody)
+		resp.Body = ioutil.NopCloser(bytes.NewBuffer(respBody))
+	}
+
+	if *chainRolesFlag && resp != nil && resp.StatusCode == http.StatusOK && reqCtx.entry != nil && assumeRoleMethods[reqCtx.entry.Method] && reqCtx.entry.AssumedRoleARN != "" {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		recordAssumedRoleSession(ctx.Req, *reqCtx.entry, respBody)
+		resp.Body = ioutil.NopCloser(bytes.NewBuffer(respBody))
+	}
+
+	if reqCtx.entry != nil {
+		reqCtx.entry.FinalHTTPStatusCode = http.StatusBadGateway // no response was received
+		if resp != nil {
+			reqCtx.entry.FinalHTTPStatusCode = resp.StatusCode
+		}
+		reqCtx.entry.Duration = time.Since(reqCtx.timestamp)
+
+		if *trackResponseSizeFlag {
+			resp = trackResponseSize(resp, reqCtx.entry)
+		} else {
+			recordProxyCall(*reqCtx.entry)
+		}
+	}
+
+	if auditLogFile != nil {
+		resp = auditProxyResponse(resp, ctx.Req, reqCtx)
+	}
+
+	if accessLogFile != nil {
+		resp = accessLogProxyResponse(resp, ctx.Req, reqCtx)
+	}
+
+	if reqCtx.entry != nil {
+		resp = endInFlightCallWithResponse(resp)
+	}
+
+	return resp
+}
+
+// countingReadCloser counts the bytes read through it, calling done once when the body is finished with
+type countingReadCloser struct {
+	io.ReadCloser
+	count int64
+	once  sync.Once
+	done  func(count int64)
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count += int64(n)
+	if err == io.EOF {
+		r.once.Do(func() { r.done(r.count) })
+	}
+	return n, err
+}
+
+func (r *countingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() { r.done(r.count) })
+	return err
+}
+
+// trackResponseSize records the call once its response body has been sent, along with the size of the body
+func trackResponseSize(resp *http.Response, entry *Entry) *http.Response {
+	if resp == nil || resp.Body == nil {
+		recordProxyCall(*entry)
+		return resp
+	}
+
+	resp.Body = &countingReadCloser{
+		ReadCloser: resp.Body,
+		done: func(count int64) {
+			entry.ResponseBodyBytes = count
+			recordProxyCall(*entry)
+		},
+	}
+
+	return resp
 }
 
 type ServiceDefinition struct {
@@ -175,9 +317,10 @@ type ServiceDefinition struct {
 }
 
 type ServiceOperation struct {
-	Http   ServiceHttp      `json:"http"`
-	Input  ServiceStructure `json:"input"`
-	Output ServiceStructure `json:"output"`
+	Http       ServiceHttp      `json:"http"`
+	Input      ServiceStructure `json:"input"`
+	Output     ServiceStructure `json:"output"`
+	Deprecated bool             `json:"deprecated"`
 }
 
 type ServiceHttp struct {
@@ -191,8 +334,11 @@ type ServiceStructure struct {
 	Type         string                      `json:"type"`
 	Member       *ServiceStructure           `json:"member"`
 	Members      map[string]ServiceStructure `json:"members"`
+	Required     []string                    `json:"required"`
+	Location     string                      `json:"location"`
 	LocationName string                      `json:"locationName"`
 	QueryName    string                      `json:"queryName"`
+	Payload      string                      `json:"payload"`
 }
 
 type ServiceDefinitionMetadata struct {
@@ -207,7 +353,23 @@ type ServiceDefinitionMetadata struct {
 	UID              string `json:"uid"`
 }
 
+// readServiceFiles loads the service definitions from --service-dir, or otherwise those embedded
 func readServiceFiles() {
+	serviceDefinitionsMutex.Lock()
+	defer serviceDefinitionsMutex.Unlock()
+
+	if len(serviceDefinitionFiles) > 0 {
+		return
+	}
+
+	if *serviceDirFlag != "" {
+		if err := readServiceDirFiles(); err != nil {
+			log.Fatal(err)
+		}
+		setServiceDefinitions()
+		return
+	}
+
 	files, err := serviceFiles.ReadDir("service")
 	if err != nil {
 		panic(err)
@@ -229,15 +391,43 @@ func readServiceFiles() {
 			panic(err)
 		}
 
-		serviceDefinitions = append(serviceDefinitions, def)
+		serviceDefinitionFiles[dirEntry.Name()] = def
+	}
+
+	setServiceDefinitions()
+}
+
+// isQueryCompatibleJSONRequest returns whether a call to a query protocol service, such as SQS or STS, uses the
+// JSON protocol that newer SDKs send these services instead
+func isQueryCompatibleJSONRequest(serviceDef ServiceDefinition, req *http.Request) bool {
+	return serviceDef.Metadata.Protocol == "query" && strings.Contains(req.Header.Get("X-Amz-Target"), ".") && strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-amz-json")
+}
+
+// flattenBody adds the values within a JSON body to params, where a scalar body is recorded as _body
+func flattenBody(params map[string][]string, bodyJSON interface{}) {
+	switch bodyJSON.(type) {
+	case map[string]interface{}, []interface{}:
+		flatten(true, params, bodyJSON, "", *maxParamDepthFlag)
+	default:
+		params["_body"] = []string{fmt.Sprintf("%v", bodyJSON)}
 	}
 }
 
-func flatten(top bool, flatMap map[string][]string, nested interface{}, prefix string) error {
+// flatten adds the values within a JSON body to flatMap, keyed by their path, storing values nested deeper than
+// maxDepth as JSON with a [truncated-depth] marker
+func flatten(top bool, flatMap map[string][]string, nested interface{}, prefix string, maxDepth int) error {
 	assign := func(newKey string, v interface{}) error {
 		switch v.(type) {
 		case map[string]interface{}, []interface{}:
-			if err := flatten(false, flatMap, v, newKey); err != nil {
+			if maxDepth <= 1 {
+				truncated, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				flatMap[newKey] = append(flatMap[newKey], "[truncated-depth]"+string(truncated))
+				return nil
+			}
+			if err := flatten(false, flatMap, v, newKey, maxDepth-1); err != nil {
 				return err
 			}
 		default:
@@ -267,35 +457,70 @@ func flatten(top bool, flatMap map[string][]string, nested interface{}, prefix s
 	return nil
 }
 
-func handleAWSRequest(req *http.Request, body []byte, respCode int) {
+// parseAWSRequest returns the call made by an AWS request, or nil if it isn't recognised
+func parseAWSRequest(req *http.Request, body []byte, respCode int) *Entry {
 	host := req.Host
 	uri := req.RequestURI
 
+	jsonPathMappingRule := getJSONPathMappingRule(host)
+
 	var serviceDef ServiceDefinition
+	uriparams := make(map[string]string)
+	s3Bucket := getS3VirtualHostedBucket(host)
 	hostSplit := strings.Split(host, ".")
-	if hostSplit[len(hostSplit)-1] == "com" && hostSplit[len(hostSplit)-2] == "amazonaws" {
-		endpointPrefix := hostSplit[len(hostSplit)-3]
-		if len(hostSplit) > 3 {
-			endpointPrefix = hostSplit[len(hostSplit)-4]
+	if s3Bucket != "" { // the bucket is a subdomain, e.g. my-bucket.s3.us-east-1.amazonaws.com
+		serviceDef, _ = getLatestServiceDefinition("s3")
+		uriparams["Bucket"] = s3Bucket
+	} else if len(hostSplit) > 2 && hostSplit[len(hostSplit)-1] == "com" && hostSplit[len(hostSplit)-2] == "amazonaws" {
+		serviceDef, _ = getLatestServiceDefinition(getEndpointPrefix(hostSplit[:len(hostSplit)-2]))
+	} else if isLocalStackHost(host) {
+		localStackServiceDef, ok := getLocalStackServiceDefinition(req)
+		if !ok {
+			return nil
 		}
-		for _, serviceDefinition := range serviceDefinitions {
-			if serviceDefinition.Metadata.EndpointPrefix == endpointPrefix { // TODO: Ensure latest version
-				serviceDef = serviceDefinition
-			}
+		serviceDef = localStackServiceDef
+		if s3Bucket = getLocalStackS3Bucket(host); s3Bucket != "" {
+			uriparams["Bucket"] = s3Bucket
 		}
+	} else if jsonPathMappingRule != nil { // custom service without a service definition
+		serviceDef.Metadata.Protocol = "json"
 	} else {
-		return
+		return nil
+	}
+
+	if jsonPathMappingRule != nil && jsonPathMappingRule.Service != "" {
+		serviceDef.Metadata.ServiceID = jsonPathMappingRule.Service
+	}
+	if serviceDef.Metadata.ServiceID != "" && !isServiceIncluded(serviceDef.Metadata.ServiceID) {
+		return nil // skip parsing the calls of filtered services
 	}
 
-	uriparams := make(map[string]string)
 	params := make(map[string][]string)
 	action := "*"
 
-	if serviceDef.Metadata.Protocol == "rest-json" {
+	// S3 paths can look transcoded when bucket names contain dots
+	grpcPackage, grpcMethod := parseGRPCTranscodedPath(req.URL.Path)
+	if grpcMethod != "" && serviceDef.Metadata.EndpointPrefix != "s3" {
+		if serviceDef.Metadata.ServiceID == "" {
+			grpcServiceDef, ok := getGRPCPackageServiceDefinition(grpcPackage)
+			if !ok {
+				return nil
+			}
+			serviceDef = grpcServiceDef
+		}
+		action = grpcMethod
+
+		if len(body) > 0 {
+			var bodyJSON interface{}
+			if err := json.Unmarshal(body, &bodyJSON); err == nil {
+				flattenBody(params, bodyJSON)
+			}
+		}
+	} else if serviceDef.Metadata.Protocol == "rest-json" {
 		// URL param schema
 		urlobj, err := url.ParseRequestURI(uri)
 		if err != nil {
-			return
+			return nil
 		}
 		vals := urlobj.Query()
 
@@ -309,7 +534,7 @@ func handleAWSRequest(req *http.Request, body []byte, respCode int) {
 				action = operationName
 				pathMatches := regexp.MustCompile(regexStr).FindAllStringSubmatch(urlobj.Path, -1)
 
-				if len(pathMatches) > 0 && len(pathMatches) > 0 && len(templateMatches) == len(pathMatches[0])-1 {
+				if len(pathMatches) > 0 && len(templateMatches) == len(pathMatches[0])-1 {
 					for i := 0; i < len(templateMatches); i++ {
 						uriparams[templateMatches[i][1]] = pathMatches[0][1:][i]
 					}
@@ -322,7 +547,7 @@ func handleAWSRequest(req *http.Request, body []byte, respCode int) {
 			normalizedK := regexp.MustCompile(`\.member\.[0-9]+`).ReplaceAllString(k, "[]")
 			normalizedK = regexp.MustCompile(`\.[0-9]+`).ReplaceAllString(normalizedK, "[]")
 
-			resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, normalizedK, "", "", serviceDef.Shapes)
+			resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, normalizedK, "", "", serviceDef.Shapes, nil)
 			if resolvedPropertyName != "" {
 				normalizedK = resolvedPropertyName
 			}
@@ -339,36 +564,99 @@ func handleAWSRequest(req *http.Request, body []byte, respCode int) {
 			var bodyJSON interface{}
 			err := json.Unmarshal(body, &bodyJSON)
 			if err != nil {
-				return
+				return nil
 			}
 
-			flatten(true, params, bodyJSON, "")
+			flattenBody(params, bodyJSON)
+		}
+	} else if serviceDef.Metadata.Protocol == "rest-xml" {
+		urlobj, err := url.ParseRequestURI(uri)
+		if err != nil {
+			return nil
 		}
-	} else if serviceDef.Metadata.Protocol == "json" {
+		vals := urlobj.Query()
+
+		// operations are matched against the path-style path
+		path := urlobj.Path
+		if s3Bucket != "" {
+			path = "/" + s3Bucket
+			if urlobj.Path != "/" {
+				path += urlobj.Path
+			}
+		}
+
+		// path and subresource part
+		operationName, operationURIParams := matchRESTOperation(serviceDef, req, path, vals)
+		if operationName != "" {
+			action = operationName
+			for k, v := range operationURIParams {
+				uriparams[k] = v
+			}
+
+			// header part, and URI parameters by member name where it differs (e.g. HostedZoneId for {Id})
+			input := resolveShape(serviceDef.Operations[action].Input, serviceDef.Shapes)
+			for memberName, member := range input.Members {
+				if member.Location == "header" && req.Header.Get(member.LocationName) != "" {
+					params[memberName] = []string{req.Header.Get(member.LocationName)}
+				}
+				if uriValue, ok := operationURIParams[member.LocationName]; ok && member.Location == "uri" && memberName != member.LocationName {
+					uriparams[memberName] = uriValue
+				}
+			}
+		}
+
+		// query part
+		for k, v := range vals {
+			resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, k, "", "", serviceDef.Shapes, nil)
+			if resolvedPropertyName != "" {
+				params[resolvedPropertyName] = append(params[resolvedPropertyName], v...)
+			}
+		}
+
+		// body part, skipping blobs such as S3 objects
+		if len(body) > 0 && operationName != "" && hasXMLPayload(serviceDef.Operations[action], serviceDef.Shapes) {
+			bodyParams, err := flattenXML(body)
+			if err == nil {
+				for k, v := range bodyParams {
+					params[k] = append(params[k], v...)
+				}
+			}
+		}
+	} else if serviceDef.Metadata.Protocol == "json" || isQueryCompatibleJSONRequest(serviceDef, req) {
 		// JSON schema
 		var bodyJSON interface{}
 		err := json.Unmarshal(body, &bodyJSON)
 
 		if err == nil {
 			amzTargetHeader := req.Header.Get("X-Amz-Target")
-			if amzTargetHeader != "" {
+			if jsonPathMappingRule != nil && serviceDef.Metadata.Protocol == "json" {
+				action, err = jsonPathMappingRule.resolveAction(bodyJSON)
+				if err != nil {
+					return nil
+				}
+				flattenBody(params, bodyJSON)
+			} else if amzTargetHeader != "" {
 				action = strings.Split(amzTargetHeader, ".")[1]
-				flatten(true, params, bodyJSON, "")
+				flattenBody(params, bodyJSON)
 			} else {
-				return
+				return nil
 			}
 		} else {
-			return
+			return nil
 		}
 	} else if serviceDef.Metadata.Protocol == "ec2" || serviceDef.Metadata.Protocol == "query" {
 		// URL param schema in body
 		vals, err := url.ParseQuery(string(body))
 		if err != nil {
-			return
+			return nil
+		}
+
+		if *ignoreDuplicateParamsFlag {
+			dedupeQueryParams(vals, "Action", "Version")
 		}
 
 		if len(vals["Action"]) != 1 || len(vals["Version"]) != 1 {
-			return
+			return nil
 		}
 		action = vals["Action"][0]
 
@@ -378,7 +666,7 @@ func handleAWSRequest(req *http.Request, body []byte, respCode int) {
 					normalizedK := regexp.MustCompile(`\.member\.[0-9]+`).ReplaceAllString(k, "[]")
 					normalizedK = regexp.MustCompile(`\.[0-9]+`).ReplaceAllString(normalizedK, "[]")
 
-					resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, normalizedK, "", "", serviceDef.Shapes)
+					resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, normalizedK, "", "", serviceDef.Shapes, nil)
 					if resolvedPropertyName != "" {
 						normalizedK = resolvedPropertyName
 					}
@@ -393,32 +681,237 @@ func handleAWSRequest(req *http.Request, body []byte, respCode int) {
 		}
 	}
 
-	region := "us-east-1"
-	re, _ := regexp.Compile(`\.(.+)\.amazonaws\.com(?:\.cn)?$`)
-	matches := re.FindStringSubmatch(host)
-	if len(matches) == 2 {
-		region = matches[1]
+	// S3 GET requests for listings and objects are ambiguous by path alone
+	if serviceDef.Metadata.EndpointPrefix == "s3" && req.Method == http.MethodGet {
+		s3Action := disambiguateS3GetAction(host, uri, uriparams)
+		if s3Action != "" {
+			action = s3Action
+
+			urlobj, err := url.ParseRequestURI(uri)
+			if err == nil {
+				for k, v := range urlobj.Query() {
+					resolvedPropertyName := resolvePropertyName(serviceDef.Operations[action].Input, k, "", "", serviceDef.Shapes, nil)
+					if resolvedPropertyName != "" {
+						params[resolvedPropertyName] = v
+					}
+				}
+			}
+		}
 	}
 
-	callLog = append(callLog, Entry{
-		Region:              region,
+	if action == "*" {
+		writeUnresolvedRequest(req, body)
+		if *noEntryOnActionStarFlag {
+			log.Printf("WARNING: could not resolve the action of %s %s%s, the call was not recorded", req.Method, host, uri)
+			return nil
+		}
+	}
+
+	entry := Entry{
+		Region:              getRegionFromHost(host),
 		Type:                "ProxyCall",
 		Service:             serviceDef.Metadata.ServiceID,
 		Method:              action,
 		Parameters:          params,
 		URIParameters:       uriparams,
 		FinalHTTPStatusCode: respCode,
-	})
+		Timestamp:           time.Now(),
+		Headers:             getLoggedRequestHeaders(req),
+	}
+
+	setCallerDetails(&entry, req)
+	if entry.SigningRegion != "" { // the hostname does not always name the region, e.g. regional STS endpoints
+		entry.Region = entry.SigningRegion
+	}
+	if isLocalStackHost(host) { // LocalStack hostnames do not name the region
+		entry.Region = *defaultRegionFlag
+	}
+
+	if *requestIDHeaderFlag != "" {
+		entry.CorrelationID = req.Header.Get(*requestIDHeaderFlag)
+	}
+	entry.AssumedRoleARN = getAssumedRoleARN(serviceDef.Metadata.EndpointPrefix, action, params)
+	if entry.AssumedRoleARN == "" && *chainRolesFlag {
+		entry.AssumedRoleARN = getSessionRoleARN(req)
+	}
 
-	handleLoggedCall()
+	return &entry
 }
 
-func resolvePropertyName(obj ServiceStructure, searchProp string, path string, locationPath string, shapes map[string]ServiceStructure) (ret string) {
-	if searchProp[len(searchProp)-2:] == "[]" { // trim trailing []
+func recordProxyCall(entry Entry) {
+	if recordCall(entry) {
+		handleLoggedCall()
+	}
+}
+
+// dedupeQueryParams collapses keys whose repeated values are all identical down to the first value
+func dedupeQueryParams(vals url.Values, keys ...string) {
+	for _, key := range keys {
+		if len(vals[key]) < 2 {
+			continue
+		}
+
+		identical := true
+		for _, v := range vals[key][1:] {
+			if v != vals[key][0] {
+				identical = false
+				break
+			}
+		}
+		if identical {
+			log.Printf("WARNING: request contains %d duplicate %s parameters, using the first", len(vals[key]), key)
+			vals[key] = vals[key][:1]
+		}
+	}
+}
+
+// getEndpointPrefix returns the endpoint prefix from the labels of a hostname before amazonaws.com, ignoring any
+// dual-stack label and FIPS suffix, e.g. ec2-fips.us-east-1 and s3.dualstack.us-east-1 give ec2 and s3
+func getEndpointPrefix(labels []string) string {
+	var serviceLabels []string
+	for _, label := range labels {
+		if label != "dualstack" {
+			serviceLabels = append(serviceLabels, label)
+		}
+	}
+	if len(serviceLabels) == 0 {
+		return ""
+	}
+
+	endpointPrefix := serviceLabels[len(serviceLabels)-1] // global endpoints have no region, e.g. iam.amazonaws.com
+	if len(serviceLabels) > 1 {
+		endpointPrefix = serviceLabels[len(serviceLabels)-2]
+	}
+
+	return strings.TrimSuffix(endpointPrefix, "-fips")
+}
+
+// hostRegionRegexp matches the region label of a hostname, including legacy S3 hostnames such as s3-us-west-2
+var awsHostRegexp = regexp.MustCompile(`^.*\.amazonaws\.com(?:\.cn)?$`)
+
+// isAWSHost returns whether a host is an AWS endpoint
+func isAWSHost(host string) bool {
+	return awsHostRegexp.MatchString(host)
+}
+
+var hostRegionRegexp = regexp.MustCompile(`[.-]([a-z]{2}(?:-[a-z]+)+-[0-9]+)\.amazonaws\.com(?:\.cn)?$`)
+
+func getRegionFromHost(host string) string {
+	region := "us-east-1"
+	matches := hostRegionRegexp.FindStringSubmatch(host)
+	if len(matches) == 2 {
+		region = matches[1]
+	}
+
+	return region
+}
+
+var s3ListQueryParams = []string{"list-type", "delimiter", "prefix", "marker", "max-keys", "encoding-type", "continuation-token", "start-after", "fetch-owner"}
+var s3GetObjectQueryParams = []string{"versionid", "partnumber"}
+
+// disambiguateS3GetAction selects between the bucket listing and object retrieval operations for an S3 GET request,
+// returning an empty string when the request is for another operation (such as a subresource like ?acl)
+func disambiguateS3GetAction(host string, uri string, uriparams map[string]string) string {
+	urlobj, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return ""
+	}
+
+	isList := false
+	for k := range urlobj.Query() {
+		lowerK := strings.ToLower(k)
+		if strings.HasPrefix(lowerK, "x-amz-") || strings.HasPrefix(lowerK, "response-") { // presigned URL and response overrides
+			continue
+		}
+
+		isKnownParam := false
+		for _, listParam := range s3ListQueryParams {
+			if lowerK == listParam {
+				isKnownParam = true
+				isList = true
+			}
+		}
+		for _, getParam := range s3GetObjectQueryParams {
+			if lowerK == getParam {
+				isKnownParam = true
+			}
+		}
+		if !isKnownParam {
+			return ""
+		}
+	}
+
+	path := strings.TrimPrefix(urlobj.Path, "/")
+	bucket := ""
+	key := ""
+
+	hostname := strings.Split(host, ":")[0]
+	virtualHostMatches := regexp.MustCompile(`^(.+)\.s3[.-].*amazonaws\.com(?:\.cn)?$`).FindStringSubmatch(hostname)
+	if len(virtualHostMatches) == 2 { // virtual-hosted style
+		bucket = virtualHostMatches[1]
+		key = path
+	} else if localStackBucket := getLocalStackS3Bucket(host); localStackBucket != "" {
+		bucket = localStackBucket
+		key = path
+	} else { // path style
+		pathSplit := strings.SplitN(path, "/", 2)
+		bucket = pathSplit[0]
+		if len(pathSplit) == 2 {
+			key = pathSplit[1]
+		}
+	}
+
+	if bucket == "" {
+		return "ListBuckets"
+	}
+	uriparams["Bucket"] = bucket
+
+	if key == "" || isList {
+		if urlobj.Query().Get("list-type") == "2" {
+			return "ListObjectsV2"
+		}
+		return "ListObjects"
+	}
+
+	uriparams["Key"] = key
+	return "GetObject"
+}
+
+// shapeCycleWarnings records the shapes found to reference themselves, whose warning has been logged
+var shapeCycleWarnings = struct {
+	sync.Mutex
+	shapes map[string]bool
+}{shapes: make(map[string]bool)}
+
+// warnShapeCycle logs, once per shape, that a shape was not searched again as it references itself
+func warnShapeCycle(shape string) {
+	shapeCycleWarnings.Lock()
+	defer shapeCycleWarnings.Unlock()
+
+	if !shapeCycleWarnings.shapes[shape] {
+		shapeCycleWarnings.shapes[shape] = true
+		log.Printf("WARNING: shape %s references itself, its members are only searched once for property names", shape)
+	}
+}
+
+// resolvePropertyName searches the members of a shape for a property, returning its path within the input.
+// visited holds the shapes on the path searched so far, so that shapes referencing themselves are searched once.
+func resolvePropertyName(obj ServiceStructure, searchProp string, path string, locationPath string, shapes map[string]ServiceStructure, visited map[string]bool) (ret string) {
+	if strings.HasSuffix(searchProp, "[]") { // trim trailing []
 		searchProp = searchProp[:len(searchProp)-2]
 	}
 
 	if obj.Shape != "" {
+		if visited[obj.Shape] {
+			warnShapeCycle(obj.Shape)
+			return ""
+		}
+		if visited == nil {
+			visited = make(map[string]bool)
+		}
+		visited[obj.Shape] = true
+		defer delete(visited, obj.Shape)
+
 		locationName := obj.LocationName
 		queryName := obj.QueryName
 		obj = shapes[obj.Shape]
@@ -443,7 +936,7 @@ func resolvePropertyName(obj ServiceStructure, searchProp string, path string, l
 				newLocationPath = locationPath + "." + v.LocationName
 			}
 
-			ret = resolvePropertyName(v, searchProp, newPath, newLocationPath, shapes)
+			ret = resolvePropertyName(v, searchProp, newPath, newLocationPath, shapes, visited)
 			if ret != "" {
 				return ret
 			}
@@ -463,7 +956,7 @@ func resolvePropertyName(obj ServiceStructure, searchProp string, path string, l
 		newPath := fmt.Sprintf("%s[]", path)
 		newLocationPath := fmt.Sprintf("%s[]", locationPath)
 
-		ret = resolvePropertyName(*obj.Member, searchProp, newPath, newLocationPath, shapes)
+		ret = resolvePropertyName(*obj.Member, searchProp, newPath, newLocationPath, shapes, visited)
 		if ret != "" {
 			return ret
 		}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// startTestProxy runs the proxy in front of an upstream handler that stands in for every AWS endpoint, returning
+// a client sending plain HTTP requests through the proxy
+func startTestProxy(t *testing.T, upstream http.Handler) *http.Client {
+	t.Helper()
+
+	upstreamServer := httptest.NewServer(upstream)
+	t.Cleanup(upstreamServer.Close)
+
+	proxy := newProxy()
+	proxy.Tr.Proxy = nil
+	proxy.Tr.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, upstreamServer.Listener.Addr().String())
+	}
+	proxyServer := httptest.NewServer(proxy)
+	t.Cleanup(proxyServer.Close)
+
+	proxyURL, err := url.Parse(proxyServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+}
+
+// sendTestRequest sends a request through the proxy, returning the response status code
+func sendTestRequest(t *testing.T, client *http.Client, method string, rawURL string, header http.Header, body string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, rawURL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// getSingleTestEntry returns the only call in the log
+func getSingleTestEntry(t *testing.T) Entry {
+	t.Helper()
+
+	entries := callLog.Snapshot()
+	if len(entries) != 1 {
+		t.Fatalf("got %d calls in the log, want 1: %+v", len(entries), entries)
+	}
+	return entries[0]
+}
+
+var queryFormHeader = http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}}
+
+func TestProxyRecordsResponseStatusCode(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusForbidden, http.StatusInternalServerError} {
+		t.Run(http.StatusText(statusCode), func(t *testing.T) {
+			resetTestCallLog(t)
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(statusCode)
+			}))
+
+			got := sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", queryFormHeader, "Action=GetCallerIdentity&Version=2011-06-15")
+			if got != statusCode {
+				t.Fatalf("the client got status %d, want %d", got, statusCode)
+			}
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != "STS" || entry.Method != "GetCallerIdentity" {
+				t.Errorf("got call %s.%s, want STS.GetCallerIdentity", entry.Service, entry.Method)
+			}
+			if entry.FinalHTTPStatusCode != statusCode {
+				t.Errorf("got FinalHTTPStatusCode %d, want %d", entry.FinalHTTPStatusCode, statusCode)
+			}
+		})
+	}
+}
+
+// getTestNestedJSON returns a JSON body with a value nested levels deep, alternating objects and lists
+func getTestNestedJSON(levels int) string {
+	var sb strings.Builder
+	for i := 0; i < levels; i++ {
+		if i%2 == 0 {
+			sb.WriteString(`{"a":`)
+		} else {
+			sb.WriteString(`[`)
+		}
+	}
+	sb.WriteString(`"leaf"`)
+	for i := levels - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			sb.WriteString(`}`)
+		} else {
+			sb.WriteString(`]`)
+		}
+	}
+	return sb.String()
+}
+
+func TestFlattenMaxDepth(t *testing.T) {
+	tests := []struct {
+		name     string
+		levels   int
+		maxDepth string
+		wantKey  string
+		wantLeaf bool
+	}{
+		{name: "within the depth", levels: 4, maxDepth: "20", wantKey: "a[].a[]", wantLeaf: true},
+		{name: "at the depth", levels: 20, maxDepth: "20", wantKey: "a" + strings.Repeat("[].a", 9) + "[]", wantLeaf: true},
+		{name: "100 levels", levels: 100, maxDepth: "20", wantKey: "a" + strings.Repeat("[].a", 9) + "[]"},
+		{name: "100 levels without a limit", levels: 100, maxDepth: "100", wantKey: "a" + strings.Repeat("[].a", 49) + "[]", wantLeaf: true},
+		{name: "100 levels to the top", levels: 100, maxDepth: "1", wantKey: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestFlag(t, "max-param-depth", tt.maxDepth)
+
+			var bodyJSON interface{}
+			if err := json.Unmarshal([]byte(getTestNestedJSON(tt.levels)), &bodyJSON); err != nil {
+				t.Fatal(err)
+			}
+			params := make(map[string][]string)
+			flatten(true, params, bodyJSON, "", *maxParamDepthFlag)
+
+			if len(params) != 1 || len(params[tt.wantKey]) != 1 {
+				t.Fatalf("got params %v, want one value at %s", params, tt.wantKey)
+			}
+			value := params[tt.wantKey][0]
+			if tt.wantLeaf {
+				if value != "leaf" {
+					t.Errorf("got %.40s, want leaf", value)
+				}
+				return
+			}
+			if !strings.HasPrefix(value, "[truncated-depth]") || !strings.Contains(value, `"leaf"`) {
+				t.Errorf("got %.40s, want the rest of the body marked [truncated-depth]", value)
+			}
+		})
+	}
+}
+
+func TestFlattenXMLMaxDepth(t *testing.T) {
+	setTestFlag(t, "max-param-depth", "20")
+
+	doc := "<Request><Name>shallow</Name>" + strings.Repeat("<A>", 100) + "deep" + strings.Repeat("</A>", 100) + "</Request>"
+	params, err := flattenXML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(params, map[string][]string{"Name": {"shallow"}}) {
+		t.Errorf("got params %v, want only Name", params)
+	}
+}
+
+func TestResolvePropertyNameCycle(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	shapeCycleWarnings.shapes = make(map[string]bool)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	shapes := map[string]ServiceStructure{
+		"Node": {Type: "structure", Members: map[string]ServiceStructure{
+			"Next": {Shape: "Node"},
+		}},
+		"Tree": {Type: "structure", Members: map[string]ServiceStructure{
+			"Name":     {Shape: "String"},
+			"Children": {Shape: "TreeList"},
+		}},
+		"TreeList": {Type: "list", Member: &ServiceStructure{Shape: "Tree"}},
+		"String":   {Type: "string"},
+	}
+
+	if got := resolvePropertyName(ServiceStructure{Shape: "Node"}, "Missing", "", "", shapes, nil); got != "" {
+		t.Errorf("got %q for a shape referencing itself, want none", got)
+	}
+	if got := resolvePropertyName(ServiceStructure{Shape: "Node"}, "Missing", "", "", shapes, nil); got != "" {
+		t.Errorf("got %q for a shape referencing itself, want none", got)
+	}
+	if got := strings.Count(logs.String(), "shape Node references itself"); got != 1 {
+		t.Errorf("got %d warnings for the Node shape, want 1:\n%s", got, logs.String())
+	}
+
+	// the members of a shape are still found when it references itself through a list
+	if got := resolvePropertyName(ServiceStructure{Shape: "Tree"}, "Name", "", "", shapes, nil); got != "Name" {
+		t.Errorf("got %q for Name, want Name", got)
+	}
+}
+
+func TestGetEndpointPrefix(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "ec2.us-east-1", want: "ec2"},
+		{host: "ec2-fips.us-east-1", want: "ec2"},
+		{host: "ec2.dualstack.us-east-1", want: "ec2"},
+		{host: "s3-fips.us-east-1", want: "s3"},
+		{host: "s3.dualstack.us-east-1", want: "s3"},
+		{host: "s3-fips.dualstack.us-west-2", want: "s3"},
+		{host: "sts-fips.us-east-2", want: "sts"},
+		{host: "sqs-fips.us-west-1", want: "sqs"},
+		{host: "kms-fips.us-gov-west-1", want: "kms"},
+		{host: "dynamodb-fips.us-east-1", want: "dynamodb"},
+		{host: "lambda.us-gov-east-1", want: "lambda"},
+		{host: "iam", want: "iam"},
+		{host: "iam-fips", want: "iam"},
+		{host: "dualstack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got := getEndpointPrefix(strings.Split(tt.host, "."))
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			if got != "" && latestServiceDefinitions[got].Metadata.EndpointPrefix != got {
+				t.Errorf("%q has no service definition", got)
+			}
+		})
+	}
+}
+
+func TestGetRegionFromHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "ec2.eu-west-1.amazonaws.com", want: "eu-west-1"},
+		{host: "ec2-fips.us-east-2.amazonaws.com", want: "us-east-2"},
+		{host: "s3.dualstack.ap-southeast-2.amazonaws.com", want: "ap-southeast-2"},
+		{host: "s3-fips.dualstack.us-west-2.amazonaws.com", want: "us-west-2"},
+		{host: "orders-bucket.s3.eu-west-1.amazonaws.com", want: "eu-west-1"},
+		{host: "s3-us-west-2.amazonaws.com", want: "us-west-2"},
+		{host: "ec2.us-gov-east-1.amazonaws.com", want: "us-gov-east-1"},
+		{host: "dynamodb.cn-north-1.amazonaws.com.cn", want: "cn-north-1"},
+		{host: "iam.amazonaws.com", want: "us-east-1"},
+		{host: "s3.amazonaws.com", want: "us-east-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := getRegionFromHost(tt.host); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyFIPSAndDualStackEndpoints(t *testing.T) {
+	tests := []struct {
+		url        string
+		header     http.Header
+		body       string
+		wantCall   string
+		wantRegion string
+	}{
+		{url: "http://ec2-fips.us-east-1.amazonaws.com/", header: queryFormHeader, body: "Action=DescribeInstances&Version=2016-11-15", wantCall: "EC2.DescribeInstances", wantRegion: "us-east-1"},
+		{url: "http://ec2.us-gov-east-1.amazonaws.com/", header: queryFormHeader, body: "Action=DescribeInstances&Version=2016-11-15", wantCall: "EC2.DescribeInstances", wantRegion: "us-gov-east-1"},
+		{url: "http://sts-fips.us-east-2.amazonaws.com/", header: queryFormHeader, body: "Action=GetCallerIdentity&Version=2011-06-15", wantCall: "STS.GetCallerIdentity", wantRegion: "us-east-2"},
+		{url: "http://sqs-fips.us-west-1.amazonaws.com/", header: queryFormHeader, body: "Action=ListQueues&Version=2012-11-05", wantCall: "SQS.ListQueues", wantRegion: "us-west-1"},
+		{url: "http://kms-fips.us-gov-west-1.amazonaws.com/", header: http.Header{"Content-Type": {"application/x-amz-json-1.1"}, "X-Amz-Target": {"TrentService.ListKeys"}}, body: "{}", wantCall: "KMS.ListKeys", wantRegion: "us-gov-west-1"},
+		{url: "http://dynamodb-fips.us-east-1.amazonaws.com/", header: http.Header{"Content-Type": {"application/x-amz-json-1.0"}, "X-Amz-Target": {"DynamoDB_20120810.ListTables"}}, body: "{}", wantCall: "DynamoDB.ListTables", wantRegion: "us-east-1"},
+		{url: "http://s3-fips.us-east-1.amazonaws.com/", wantCall: "S3.ListBuckets", wantRegion: "us-east-1"},
+		{url: "http://s3.dualstack.eu-west-1.amazonaws.com/", wantCall: "S3.ListBuckets", wantRegion: "eu-west-1"},
+		{url: "http://s3-fips.dualstack.us-west-2.amazonaws.com/", wantCall: "S3.ListBuckets", wantRegion: "us-west-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			resetTestCallLog(t)
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			method := "POST"
+			if tt.body == "" {
+				method = "GET"
+			}
+			sendTestRequest(t, client, method, tt.url, tt.header, tt.body)
+
+			entry := getSingleTestEntry(t)
+			if got := entry.Service + "." + entry.Method; got != tt.wantCall {
+				t.Errorf("got call %s, want %s", got, tt.wantCall)
+			}
+			if entry.Region != tt.wantRegion {
+				t.Errorf("got region %s, want %s", entry.Region, tt.wantRegion)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/elazarl/goproxy"
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// proxyCredential holds a hashed password, so that plain text passwords aren't kept in memory. Passwords are checked
+// with bcrypt, or with SHA-1 for the {SHA} entries of htpasswd files.
+type proxyCredential struct {
+	hash []byte
+	sha1 bool
+}
+
+var proxyCredentials map[string]proxyCredential
+
+// proxyAuthDummyHash is checked against the password of unknown users, so that they take as long to reject as
+// known users with a wrong password
+var proxyAuthDummyHash []byte
+
+// proxyAuthBcryptCost is the cost of the bcrypt hash of the --proxy-auth password
+const proxyAuthBcryptCost = bcrypt.DefaultCost
+
+func loadProxyAuth() error {
+	if *proxyAuthFlag == "" && *proxyAuthFileFlag == "" {
+		return nil
+	}
+
+	proxyCredentials = make(map[string]proxyCredential)
+
+	var err error
+	proxyAuthDummyHash, err = bcrypt.GenerateFromPassword([]byte("iamlive"), proxyAuthBcryptCost)
+	if err != nil {
+		return err
+	}
+
+	if *proxyAuthFlag != "" {
+		authSplit := strings.SplitN(*proxyAuthFlag, ":", 2)
+		if len(authSplit) != 2 || authSplit[0] == "" {
+			return fmt.Errorf("the proxy auth must be in the form user:password")
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(authSplit[1]), proxyAuthBcryptCost)
+		if err != nil {
+			return err
+		}
+		proxyCredentials[authSplit[0]] = proxyCredential{hash: hash}
+	}
+
+	if *proxyAuthFileFlag != "" {
+		authFilePath, err := homedir.Expand(*proxyAuthFileFlag)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(authFilePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// bcrypt (htpasswd -B) and SHA-1 (htpasswd -s) entries are supported
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			lineSplit := strings.SplitN(line, ":", 2)
+			if len(lineSplit) != 2 {
+				return fmt.Errorf("invalid entry in proxy auth file for user %q, expected user:hash", lineSplit[0])
+			}
+			credential, err := parseProxyAuthFileHash(lineSplit[1])
+			if err != nil {
+				return fmt.Errorf("invalid entry in proxy auth file for user %q: %v", lineSplit[0], err)
+			}
+			proxyCredentials[lineSplit[0]] = credential
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseProxyAuthFileHash parses the hash of an htpasswd entry, accepting the $2y$, $2a$ and $2b$ bcrypt prefixes and
+// {SHA}
+func parseProxyAuthFileHash(hash string) (proxyCredential, error) {
+	if strings.HasPrefix(hash, "{SHA}") {
+		sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(hash, "{SHA}"))
+		if err != nil {
+			return proxyCredential{}, err
+		}
+		if len(sum) != sha1.Size {
+			return proxyCredential{}, fmt.Errorf("the {SHA} hash is %d bytes, expected %d", len(sum), sha1.Size)
+		}
+		return proxyCredential{hash: sum, sha1: true}, nil
+	}
+
+	if strings.HasPrefix(hash, "$2y$") || strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") {
+		// htpasswd writes $2y$, which Go's bcrypt reads as the equivalent $2a$
+		bcryptHash := []byte("$2a$" + hash[4:])
+		if _, err := bcrypt.Cost(bcryptHash); err != nil {
+			return proxyCredential{}, err
+		}
+		return proxyCredential{hash: bcryptHash}, nil
+	}
+
+	return proxyCredential{}, fmt.Errorf("unsupported hash, only bcrypt ($2y$) and {SHA} entries are supported")
+}
+
+func isProxyAuthorized(req *http.Request) bool {
+	if proxyCredentials == nil {
+		return true
+	}
+
+	authSplit := strings.SplitN(req.Header.Get("Proxy-Authorization"), " ", 2)
+	if len(authSplit) != 2 || !strings.EqualFold(authSplit[0], "Basic") {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(authSplit[1])
+	if err != nil {
+		return false
+	}
+	userSplit := strings.SplitN(string(decoded), ":", 2)
+	if len(userSplit) != 2 {
+		return false
+	}
+
+	// the password is always checked, against a dummy hash for unknown users, so that the time taken does not reveal
+	// whether the user exists
+	credential, ok := proxyCredentials[userSplit[0]]
+	if !ok {
+		credential = proxyCredential{hash: proxyAuthDummyHash}
+	}
+
+	var matched bool
+	if credential.sha1 {
+		sum := sha1.Sum([]byte(userSplit[1]))
+		matched = subtle.ConstantTimeCompare(sum[:], credential.hash) == 1
+		bcrypt.CompareHashAndPassword(proxyAuthDummyHash, []byte(userSplit[1])) // as slow as the other users
+	} else {
+		matched = bcrypt.CompareHashAndPassword(credential.hash, []byte(userSplit[1])) == nil
+	}
+
+	return ok && matched
+}
+
+func proxyAuthRequired(req *http.Request) *http.Response {
+	resp := goproxy.NewResponse(req, goproxy.ContentTypeText, http.StatusProxyAuthRequired, "Proxy Authentication Required")
+	resp.Header.Set("Proxy-Authenticate", `Basic realm="iamlive"`)
+	return resp
+}
+
+// addProxyAuth requires proxy authentication for CONNECT and plain HTTP requests, requests within an
+// intercepted HTTPS connection were authorized when it was established
+func addProxyAuth(proxy *goproxy.ProxyHttpServer) {
+	proxy.OnRequest().HandleConnectFunc(func(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
+		if !isProxyAuthorized(ctx.Req) {
+			ctx.Resp = proxyAuthRequired(ctx.Req)
+			return goproxy.RejectConnect, host
+		}
+		return nil, host
+	})
+	proxy.OnRequest().DoFunc(func(req *http.Request, ctx *goproxy.ProxyCtx) (*http.Request, *http.Response) {
+		if req.URL.Scheme != "https" && !isProxyAuthorized(req) {
+			return req, proxyAuthRequired(req)
+		}
+		return req, nil
+	})
+}
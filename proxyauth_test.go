@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loadTestProxyAuth loads --proxy-auth and an htpasswd file of the given lines, until the end of the test
+func loadTestProxyAuth(t *testing.T, auth string, fileLines ...string) error {
+	t.Helper()
+
+	setTestFlag(t, "proxy-auth", auth)
+	setTestFlag(t, "proxy-auth-file", "")
+	if len(fileLines) > 0 {
+		file := filepath.Join(t.TempDir(), "htpasswd")
+		if err := ioutil.WriteFile(file, []byte(strings.Join(fileLines, "\n")+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		setTestFlag(t, "proxy-auth-file", file)
+	}
+	t.Cleanup(func() {
+		proxyCredentials = nil
+	})
+
+	return loadProxyAuth()
+}
+
+// getTestHtpasswdBcrypt returns a bcrypt hash with the $2y$ prefix written by htpasswd -B
+func getTestHtpasswdBcrypt(t *testing.T, password string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "$2y$" + string(hash[4:])
+}
+
+func getTestHtpasswdSHA(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func getTestProxyAuthRequest(user string, password string) *http.Request {
+	req, _ := http.NewRequest("GET", "http://s3.amazonaws.com/", nil)
+	req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+	return req
+}
+
+func TestParseProxyAuthFileHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     string
+		wantSHA1 bool
+		wantErr  bool
+	}{
+		{name: "bcrypt $2y$", hash: getTestHtpasswdBcrypt(t, "secret"), wantSHA1: false},
+		{name: "bcrypt $2a$", hash: "$2a$" + getTestHtpasswdBcrypt(t, "secret")[4:], wantSHA1: false},
+		{name: "bcrypt $2b$", hash: "$2b$" + getTestHtpasswdBcrypt(t, "secret")[4:], wantSHA1: false},
+		{name: "sha", hash: getTestHtpasswdSHA("secret"), wantSHA1: true},
+		{name: "truncated sha", hash: "{SHA}AAAA", wantErr: true},
+		{name: "invalid sha", hash: "{SHA}not base64!", wantErr: true},
+		{name: "truncated bcrypt", hash: "$2y$10$abc", wantErr: true},
+		{name: "md5", hash: "$apr1$salt$hash", wantErr: true},
+		{name: "crypt", hash: "rFkbJxW.IaZmw", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential, err := parseProxyAuthFileHash(tt.hash)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got no error for %q", tt.hash)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if credential.sha1 != tt.wantSHA1 {
+				t.Errorf("got sha1 %t, want %t", credential.sha1, tt.wantSHA1)
+			}
+		})
+	}
+}
+
+func TestIsProxyAuthorized(t *testing.T) {
+	err := loadTestProxyAuth(t, "flaguser:flag-secret",
+		"# users",
+		"bcryptuser:"+getTestHtpasswdBcrypt(t, "bcrypt-secret"),
+		"shauser:"+getTestHtpasswdSHA("sha-secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential := proxyCredentials["flaguser"]; credential.sha1 || strings.Contains(string(credential.hash), "flag-secret") {
+		t.Fatalf("the --proxy-auth password is not kept as a bcrypt hash")
+	}
+	if _, err := bcrypt.Cost(proxyCredentials["flaguser"].hash); err != nil {
+		t.Fatalf("the --proxy-auth password is not kept as a bcrypt hash: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want bool
+	}{
+		{name: "flag user", req: getTestProxyAuthRequest("flaguser", "flag-secret"), want: true},
+		{name: "flag user with the wrong password", req: getTestProxyAuthRequest("flaguser", "wrong"), want: false},
+		{name: "bcrypt user", req: getTestProxyAuthRequest("bcryptuser", "bcrypt-secret"), want: true},
+		{name: "bcrypt user with the wrong password", req: getTestProxyAuthRequest("bcryptuser", "wrong"), want: false},
+		{name: "sha user", req: getTestProxyAuthRequest("shauser", "sha-secret"), want: true},
+		{name: "sha user with the wrong password", req: getTestProxyAuthRequest("shauser", "wrong"), want: false},
+		{name: "unknown user", req: getTestProxyAuthRequest("unknown", "flag-secret"), want: false},
+		{name: "unknown user with the dummy password", req: getTestProxyAuthRequest("unknown", "iamlive"), want: false},
+		{name: "password of another user", req: getTestProxyAuthRequest("bcryptuser", "sha-secret"), want: false},
+		{name: "no header", req: func() *http.Request { req, _ := http.NewRequest("GET", "http://s3.amazonaws.com/", nil); return req }(), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isProxyAuthorized(tt.req); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProxyAuthInvalidFile(t *testing.T) {
+	err := loadTestProxyAuth(t, "", "md5user:$apr1$salt$hash")
+	if err == nil || !strings.Contains(err.Error(), `user "md5user"`) {
+		t.Fatalf("got error %v, want one naming md5user", err)
+	}
+}
+
+func TestProxyRequiresAuth(t *testing.T) {
+	resetTestCallLog(t)
+	if err := loadTestProxyAuth(t, "flaguser:flag-secret"); err != nil {
+		t.Fatal(err)
+	}
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if got := sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", queryFormHeader, "Action=GetCallerIdentity&Version=2011-06-15"); got != http.StatusProxyAuthRequired {
+		t.Errorf("got status code %d without credentials, want 407", got)
+	}
+
+	header := getTestProxyAuthRequest("flaguser", "flag-secret").Header
+	header.Set("Content-Type", queryFormHeader.Get("Content-Type"))
+	if got := sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", header, "Action=GetCallerIdentity&Version=2011-06-15"); got != http.StatusOK {
+		t.Errorf("got status code %d with credentials, want 200", got)
+	}
+}
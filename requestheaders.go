@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// getLoggedRequestHeaders returns the request headers selected by --log-request-headers, without their secrets
+func getLoggedRequestHeaders(req *http.Request) map[string]string {
+	if *logRequestHeadersFlag == "" {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	for _, header := range strings.Split(*logRequestHeadersFlag, ",") {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		value := req.Header.Get(header)
+		if header == "" || value == "" {
+			continue
+		}
+
+		switch header {
+		case "Authorization":
+			// only the credential scope is kept, the signature is never stored
+			if auth, err := parseSigV4Authorization(value); err == nil {
+				value = sigV4Algorithm + " Credential=" + strings.Join([]string{auth.AccessKeyID, auth.Date, auth.Region, auth.Service, "aws4_request"}, "/")
+			} else {
+				value = "[redacted]"
+			}
+		case "X-Amz-Security-Token":
+			value = "[redacted]"
+		}
+
+		headers[header] = value
+	}
+
+	return headers
+}
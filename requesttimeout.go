@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+const requestBodyChunkSize = 32 * 1024
+
+type requestBodyChunk struct {
+	data []byte
+	err  error
+}
+
+// readRequestBody reads the body of a proxied request, giving up once --per-request-timeout elapses. If it gives
+// up, the returned reader yields the whole body, including what was already read, so the request can be passed
+// through unmodified.
+func readRequestBody(req *http.Request) ([]byte, io.ReadCloser) {
+	if *perRequestTimeoutFlag <= 0 || req.Body == nil {
+		body, _ := ioutil.ReadAll(req.Body)
+		return body, nil
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), *perRequestTimeoutFlag)
+	defer cancel()
+
+	chunks := make(chan requestBodyChunk)
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, requestBodyChunkSize)
+			n, err := req.Body.Read(buf)
+			chunks <- requestBodyChunk{data: buf[:n], err: err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var body []byte
+	for {
+		select {
+		case chunk := <-chunks:
+			body = append(body, chunk.data...)
+			if chunk.err != nil {
+				return body, nil
+			}
+		case <-ctx.Done():
+			return nil, &passthroughBody{pending: body, chunks: chunks, body: req.Body}
+		}
+	}
+}
+
+// passthroughBody continues reading a request body that was abandoned part way through
+type passthroughBody struct {
+	pending []byte
+	chunks  chan requestBodyChunk
+	body    io.ReadCloser
+	err     error
+}
+
+func (b *passthroughBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		chunk, ok := <-b.chunks
+		if !ok {
+			return 0, io.EOF
+		}
+		b.pending = chunk.data
+		b.err = chunk.err
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *passthroughBody) Close() error {
+	err := b.body.Close()
+	go func() {
+		for range b.chunks { // let the reading goroutine finish
+		}
+	}()
+	return err
+}
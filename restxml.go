@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var restURITemplateRegexp = regexp.MustCompile(`{([^}]+?)(\+?)}`)
+
+// resolveShape returns the structure a shape reference points to
+func resolveShape(structure ServiceStructure, shapes map[string]ServiceStructure) ServiceStructure {
+	if structure.Shape != "" && structure.Type == "" {
+		if shape, ok := shapes[structure.Shape]; ok {
+			return shape
+		}
+	}
+
+	return structure
+}
+
+// restOperationMatch is an operation whose HTTP binding matches a request, scored by how specifically it matched
+type restOperationMatch struct {
+	name       string
+	uriparams  map[string]string
+	score      int
+	literal    int
+	deprecated bool
+}
+
+func (m restOperationMatch) betterThan(other restOperationMatch) bool {
+	if m.score != other.score {
+		return m.score > other.score
+	}
+	if m.deprecated != other.deprecated {
+		return other.deprecated
+	}
+	if m.literal != other.literal {
+		return m.literal > other.literal
+	}
+	return m.name < other.name // deterministic across map iteration
+}
+
+// matchRESTOperation finds the operation bound to the method and path of a request. Several operations can share a
+// path (e.g. PutObject, CopyObject and UploadPart), so each must have its query string subresource and required
+// query string and header members present, and the most specific match wins.
+func matchRESTOperation(serviceDef ServiceDefinition, req *http.Request, path string, query url.Values) (string, map[string]string) {
+	var best *restOperationMatch
+
+	for operationName, operation := range serviceDef.Operations {
+		method := operation.Http.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		if method != req.Method {
+			continue
+		}
+
+		requestURI := operation.Http.RequestURI
+		if requestURI == "" {
+			requestURI = "/"
+		}
+		uriSplit := strings.SplitN(requestURI, "?", 2)
+		pathTemplate := uriSplit[0]
+
+		var regexStr strings.Builder
+		var templateNames []string
+		literal := 0
+		last := 0
+		for _, loc := range restURITemplateRegexp.FindAllStringSubmatchIndex(pathTemplate, -1) {
+			regexStr.WriteString(regexp.QuoteMeta(pathTemplate[last:loc[0]]))
+			literal += loc[0] - last
+			if loc[5] > loc[4] { // greedy label such as {Key+}
+				regexStr.WriteString("(.+)")
+			} else {
+				regexStr.WriteString("([^/]+)")
+			}
+			templateNames = append(templateNames, pathTemplate[loc[2]:loc[3]])
+			last = loc[1]
+		}
+		regexStr.WriteString(regexp.QuoteMeta(pathTemplate[last:]))
+		literal += len(pathTemplate) - last
+
+		trailingSlash := ""
+		if !strings.HasSuffix(pathTemplate, "/") {
+			trailingSlash = "/?"
+		}
+		pathMatches := regexp.MustCompile("^" + regexStr.String() + trailingSlash + "$").FindStringSubmatch(path)
+		if pathMatches == nil {
+			continue
+		}
+
+		match := restOperationMatch{
+			name:       operationName,
+			uriparams:  make(map[string]string),
+			literal:    literal,
+			deprecated: operation.Deprecated,
+		}
+		for i, templateName := range templateNames {
+			match.uriparams[templateName] = pathMatches[i+1]
+		}
+
+		matched := true
+		if len(uriSplit) == 2 {
+			for _, subresource := range strings.Split(uriSplit[1], "&") {
+				keyValue := strings.SplitN(subresource, "=", 2)
+				if _, ok := query[keyValue[0]]; !ok || (len(keyValue) == 2 && query.Get(keyValue[0]) != keyValue[1]) {
+					matched = false
+					break
+				}
+				match.score++
+			}
+		}
+
+		input := resolveShape(operation.Input, serviceDef.Shapes)
+		for _, requiredMember := range input.Required {
+			member := input.Members[requiredMember]
+			switch member.Location {
+			case "querystring":
+				if _, ok := query[member.LocationName]; !ok {
+					matched = false
+				}
+				match.score++
+			case "header":
+				if req.Header.Get(member.LocationName) == "" {
+					matched = false
+				}
+				match.score++
+			}
+		}
+
+		if matched && (best == nil || match.betterThan(*best)) {
+			best = &match
+		}
+	}
+
+	if best == nil {
+		return "", nil
+	}
+
+	return best.name, best.uriparams
+}
+
+// hasXMLPayload returns true if the body of an operation is an XML document rather than a blob such as an S3 object
+func hasXMLPayload(operation ServiceOperation, shapes map[string]ServiceStructure) bool {
+	input := resolveShape(operation.Input, shapes)
+	if input.Payload == "" {
+		return true
+	}
+
+	return resolveShape(input.Members[input.Payload], shapes).Type == "structure"
+}
+
+// flattenXML returns the text values within an XML document keyed by their element path below the root element,
+// e.g. ChangeBatch.Changes.Change.Action, dropping values nested deeper than --max-param-depth
+func flattenXML(data []byte) (map[string][]string, error) {
+	flatMap := make(map[string][]string)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var path []string
+	var text []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			text = append(text, "")
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1] += string(t)
+			}
+		case xml.EndElement:
+			if len(path) == 0 {
+				return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+
+			// only leaf elements carry values, the root element is the request itself
+			value := strings.TrimSpace(text[len(text)-1])
+			if value != "" && len(path) > 1 && len(path)-1 <= *maxParamDepthFlag {
+				key := strings.Join(path[1:], ".")
+				flatMap[key] = append(flatMap[key], value)
+			}
+
+			path = path[:len(path)-1]
+			text = text[:len(text)-1]
+		}
+	}
+
+	if len(path) != 0 {
+		return nil, fmt.Errorf("unexpected end of XML document")
+	}
+
+	return flatMap, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const testLifecycleConfiguration = `<?xml version="1.0" encoding="UTF-8"?>
+<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Rule>
+    <ID>expire-logs</ID>
+    <Filter><Prefix>logs/</Prefix></Filter>
+    <Status>Enabled</Status>
+    <Expiration><Days>30</Days></Expiration>
+  </Rule>
+</LifecycleConfiguration>`
+
+const testChangeResourceRecordSets = `<?xml version="1.0" encoding="UTF-8"?>
+<ChangeResourceRecordSetsRequest xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <ChangeBatch>
+    <Changes>
+      <Change>
+        <Action>UPSERT</Action>
+        <ResourceRecordSet>
+          <Name>api.example.com</Name>
+          <Type>A</Type>
+          <TTL>300</TTL>
+          <ResourceRecords><ResourceRecord><Value>192.0.2.1</Value></ResourceRecord></ResourceRecords>
+        </ResourceRecordSet>
+      </Change>
+    </Changes>
+  </ChangeBatch>
+</ChangeResourceRecordSetsRequest>`
+
+var xmlHeader = http.Header{"Content-Type": {"application/xml"}}
+
+func TestProxyRestXMLRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		header         http.Header
+		body           string
+		wantService    string
+		wantMethod     string
+		wantParams     map[string][]string
+		wantURIParams  map[string]string
+		wantStatusCode int
+	}{
+		{
+			name:        "S3 PutBucketLifecycleConfiguration",
+			method:      "PUT",
+			url:         "http://s3.us-east-1.amazonaws.com/orders-bucket?lifecycle",
+			header:      xmlHeader,
+			body:        testLifecycleConfiguration,
+			wantService: "S3",
+			wantMethod:  "PutBucketLifecycleConfiguration",
+			wantParams: map[string][]string{
+				"Rule.ID":              {"expire-logs"},
+				"Rule.Filter.Prefix":   {"logs/"},
+				"Rule.Status":          {"Enabled"},
+				"Rule.Expiration.Days": {"30"},
+			},
+			wantURIParams: map[string]string{"Bucket": "orders-bucket"},
+		},
+		{
+			name:        "Route 53 ChangeResourceRecordSets",
+			method:      "POST",
+			url:         "http://route53.amazonaws.com/2013-04-01/hostedzone/Z0123456789ABC/rrset/",
+			header:      http.Header{"Content-Type": {"text/xml"}},
+			body:        testChangeResourceRecordSets,
+			wantService: "Route 53",
+			wantMethod:  "ChangeResourceRecordSets",
+			wantParams: map[string][]string{
+				"ChangeBatch.Changes.Change.Action":                                                 {"UPSERT"},
+				"ChangeBatch.Changes.Change.ResourceRecordSet.Name":                                 {"api.example.com"},
+				"ChangeBatch.Changes.Change.ResourceRecordSet.Type":                                 {"A"},
+				"ChangeBatch.Changes.Change.ResourceRecordSet.TTL":                                  {"300"},
+				"ChangeBatch.Changes.Change.ResourceRecordSet.ResourceRecords.ResourceRecord.Value": {"192.0.2.1"},
+			},
+			wantURIParams: map[string]string{"Id": "Z0123456789ABC", "HostedZoneId": "Z0123456789ABC"},
+		},
+		{
+			name:          "S3 DeleteObject without a body",
+			method:        "DELETE",
+			url:           "http://s3.us-east-1.amazonaws.com/orders-bucket/reports/2024.csv",
+			wantService:   "S3",
+			wantMethod:    "DeleteObject",
+			wantParams:    map[string][]string{},
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "reports/2024.csv"},
+		},
+		{
+			name:          "S3 HeadObject",
+			method:        "HEAD",
+			url:           "http://s3.us-east-1.amazonaws.com/orders-bucket/reports/2024.csv",
+			wantService:   "S3",
+			wantMethod:    "HeadObject",
+			wantParams:    map[string][]string{},
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "reports/2024.csv"},
+		},
+		{
+			name:          "S3 CopyObject from the header",
+			method:        "PUT",
+			url:           "http://s3.us-east-1.amazonaws.com/orders-bucket/copy.csv",
+			header:        http.Header{"X-Amz-Copy-Source": {"/source-bucket/original.csv"}},
+			wantService:   "S3",
+			wantMethod:    "CopyObject",
+			wantParams:    map[string][]string{"CopySource": {"/source-bucket/original.csv"}},
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "copy.csv"},
+		},
+		{
+			name:          "S3 PutObject body is not parsed",
+			method:        "PUT",
+			url:           "http://s3.us-east-1.amazonaws.com/orders-bucket/data.xml",
+			header:        xmlHeader,
+			body:          "<Orders><Order>1</Order></Orders>",
+			wantService:   "S3",
+			wantMethod:    "PutObject",
+			wantParams:    map[string][]string{"ContentLength": {"33"}, "ContentType": {"application/xml"}},
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "data.xml"},
+		},
+		{
+			name:           "CloudFront with an XML error envelope",
+			method:         "GET",
+			url:            "http://cloudfront.amazonaws.com/2020-05-31/distribution/E2EXAMPLE",
+			wantService:    "CloudFront",
+			wantMethod:     "GetDistribution",
+			wantParams:     map[string][]string{},
+			wantURIParams:  map[string]string{"Id": "E2EXAMPLE"},
+			wantStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			wantStatusCode := tt.wantStatusCode
+			if wantStatusCode == 0 {
+				wantStatusCode = http.StatusOK
+			}
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if wantStatusCode != http.StatusOK {
+					w.Header().Set("Content-Type", "text/xml")
+					w.WriteHeader(wantStatusCode)
+					w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>Access denied</Message></Error></ErrorResponse>`))
+				}
+			}))
+
+			sendTestRequest(t, client, tt.method, tt.url, tt.header, tt.body)
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != tt.wantService || entry.Method != tt.wantMethod {
+				t.Errorf("got call %s.%s, want %s.%s", entry.Service, entry.Method, tt.wantService, tt.wantMethod)
+			}
+			if !reflect.DeepEqual(entry.Parameters, tt.wantParams) {
+				t.Errorf("got params %v, want %v", entry.Parameters, tt.wantParams)
+			}
+			if !reflect.DeepEqual(entry.URIParameters, tt.wantURIParams) {
+				t.Errorf("got URI params %v, want %v", entry.URIParameters, tt.wantURIParams)
+			}
+			if entry.FinalHTTPStatusCode != wantStatusCode {
+				t.Errorf("got status code %d, want %d", entry.FinalHTTPStatusCode, wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestFlattenXML(t *testing.T) {
+	params, err := flattenXML([]byte(testChangeResourceRecordSets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params["ChangeBatch.Changes.Change.Action"]; !reflect.DeepEqual(got, []string{"UPSERT"}) {
+		t.Errorf("got action %v, want UPSERT", got)
+	}
+
+	for _, doc := range []string{"<Request><Name>a</Name>", "<Request></Name></Request>", "</Request>"} {
+		if _, err := flattenXML([]byte(doc)); err == nil {
+			t.Errorf("got no error for %s", doc)
+		}
+	}
+}
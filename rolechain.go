@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+// assumeRoleMethods are the STS calls that assume the role named by their RoleArn parameter
+var assumeRoleMethods = map[string]bool{
+	"AssumeRole":                true,
+	"AssumeRoleWithSAML":        true,
+	"AssumeRoleWithWebIdentity": true,
+}
+
+// sessionTokenRegexp matches the session token of the credentials in an STS query protocol (XML) response
+var sessionTokenRegexp = regexp.MustCompile(`<SessionToken>\s*([^<\s]+)\s*</SessionToken>`)
+
+// roleSessions maps the session token of each role session seen by --chain-roles to the ARN of its role
+var roleSessions = make(map[string]string)
+
+// roleChainParents maps each role assumed while --chain-roles is set to the role whose session assumed it, or an
+// empty string if it was assumed with other credentials
+var roleChainParents = make(map[string]string)
+var roleChainMutex sync.Mutex
+
+// getAssumedRoleARN returns the role an STS call assumes, or an empty string for other calls
+func getAssumedRoleARN(endpointPrefix string, method string, params map[string][]string) string {
+	if endpointPrefix != "sts" || !assumeRoleMethods[method] || len(params["RoleArn"]) == 0 {
+		return ""
+	}
+
+	return params["RoleArn"][0]
+}
+
+func getRequestSessionToken(req *http.Request) string {
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	return req.URL.Query().Get("X-Amz-Security-Token") // presigned URLs
+}
+
+// getSessionRoleARN returns the role of the session whose credentials signed a request, if --chain-roles saw the
+// session being created
+func getSessionRoleARN(req *http.Request) string {
+	token := getRequestSessionToken(req)
+	if token == "" {
+		return ""
+	}
+
+	roleChainMutex.Lock()
+	defer roleChainMutex.Unlock()
+
+	return roleSessions[token]
+}
+
+// getResponseSessionToken returns the session token of the credentials returned by an STS call, in either the
+// query (XML) or JSON protocol
+func getResponseSessionToken(respBody []byte) string {
+	var jsonResponse struct {
+		Credentials struct {
+			SessionToken string `json:"SessionToken"`
+		} `json:"Credentials"`
+	}
+	if err := json.Unmarshal(respBody, &jsonResponse); err == nil {
+		return jsonResponse.Credentials.SessionToken
+	}
+
+	if matches := sessionTokenRegexp.FindSubmatch(respBody); matches != nil {
+		return string(matches[1])
+	}
+
+	return ""
+}
+
+// recordAssumedRoleSession records the role session created by an STS call, so the calls made with its credentials
+// can be attributed to the role, along with the role whose session made the call
+func recordAssumedRoleSession(req *http.Request, entry Entry, respBody []byte) {
+	token := getResponseSessionToken(respBody)
+	if token == "" {
+		return
+	}
+	parentRoleARN := getSessionRoleARN(req)
+
+	roleChainMutex.Lock()
+	defer roleChainMutex.Unlock()
+
+	roleSessions[token] = entry.AssumedRoleARN
+	if _, ok := roleChainParents[entry.AssumedRoleARN]; !ok {
+		roleChainParents[entry.AssumedRoleARN] = parentRoleARN
+	}
+}
+
+// getRoleChainParents returns each role assumed while --chain-roles is set, mapped to the role that assumed it
+func getRoleChainParents() map[string]string {
+	roleChainMutex.Lock()
+	defer roleChainMutex.Unlock()
+
+	parents := make(map[string]string)
+	for role, parent := range roleChainParents {
+		parents[role] = parent
+	}
+	return parents
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// resetTestRoleChain forgets the role sessions seen by --chain-roles, until the end of the test
+func resetTestRoleChain(t *testing.T) {
+	reset := func() {
+		roleChainMutex.Lock()
+		roleSessions = make(map[string]string)
+		roleChainParents = make(map[string]string)
+		roleChainMutex.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// getTestSTSJSONHeader returns the header of a JSON protocol STS call, as sent by newer SDKs
+func getTestSTSJSONHeader(method string) http.Header {
+	return http.Header{
+		"Content-Type": {"application/x-amz-json-1.0"},
+		"X-Amz-Target": {"AWSSecurityTokenServiceV20110615." + method},
+	}
+}
+
+// stsTestHandler stands in for STS and S3, returning the credentials of session token-a to query protocol calls and
+// of session token-b to JSON protocol calls
+var stsTestHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-amz-json") {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{"Credentials": {"AccessKeyId": "ASIAEXAMPLE", "SessionToken": "token-b"}}`))
+		return
+	}
+	if r.Host == "sts.amazonaws.com" {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte("<AssumeRoleResponse><AssumeRoleResult><Credentials>\n  <SessionToken>token-a</SessionToken>\n</Credentials></AssumeRoleResult></AssumeRoleResponse>"))
+		return
+	}
+	w.Write([]byte("<ListAllMyBucketsResult></ListAllMyBucketsResult>"))
+})
+
+func TestAssumedRoleARN(t *testing.T) {
+	const roleARN = "arn:aws:iam::123456789012:role/deployer"
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   string
+		want   string
+	}{
+		{
+			name:   "query AssumeRole",
+			header: queryFormHeader,
+			body:   "Action=AssumeRole&Version=2011-06-15&RoleArn=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fdeployer&RoleSessionName=ci",
+			want:   roleARN,
+		},
+		{
+			name:   "query AssumeRoleWithWebIdentity",
+			header: queryFormHeader,
+			body:   "Action=AssumeRoleWithWebIdentity&Version=2011-06-15&RoleArn=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fdeployer&RoleSessionName=ci&WebIdentityToken=eyJ",
+			want:   roleARN,
+		},
+		{
+			name:   "json AssumeRole",
+			header: getTestSTSJSONHeader("AssumeRole"),
+			body:   `{"RoleArn": "arn:aws:iam::123456789012:role/deployer", "RoleSessionName": "ci"}`,
+			want:   roleARN,
+		},
+		{
+			name:   "json AssumeRoleWithWebIdentity",
+			header: getTestSTSJSONHeader("AssumeRoleWithWebIdentity"),
+			body:   `{"RoleArn": "arn:aws:iam::123456789012:role/deployer", "RoleSessionName": "ci", "WebIdentityToken": "eyJ"}`,
+			want:   roleARN,
+		},
+		{
+			name:   "query GetCallerIdentity",
+			header: queryFormHeader,
+			body:   "Action=GetCallerIdentity&Version=2011-06-15",
+		},
+		{
+			name:   "json GetCallerIdentity",
+			header: getTestSTSJSONHeader("GetCallerIdentity"),
+			body:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			client := startTestProxy(t, stsTestHandler)
+
+			sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", tt.header, tt.body)
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != "STS" {
+				t.Errorf("got service %s, want STS", entry.Service)
+			}
+			if entry.AssumedRoleARN != tt.want {
+				t.Errorf("got AssumedRoleARN %q, want %q", entry.AssumedRoleARN, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainRoles(t *testing.T) {
+	const roleA = "arn:aws:iam::123456789012:role/a"
+	const roleB = "arn:aws:iam::123456789012:role/b"
+
+	resetTestCallLog(t)
+	resetTestRoleChain(t)
+	setTestFlag(t, "chain-roles", "true")
+	client := startTestProxy(t, stsTestHandler)
+
+	// role a is assumed with the query protocol, then role b with its session and the JSON protocol
+	sendTestRequest(t, client, "POST", "http://sts.amazonaws.com/", queryFormHeader, "Action=AssumeRole&Version=2011-06-15&RoleArn=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fa&RoleSessionName=ci")
+	header := getTestSTSJSONHeader("AssumeRole")
+	header.Set("X-Amz-Security-Token", "token-a")
+	sendTestRequest(t, client, "POST", "http://sts.us-east-1.amazonaws.com/", header, `{"RoleArn": "arn:aws:iam::123456789012:role/b", "RoleSessionName": "ci"}`)
+	sendTestRequest(t, client, "GET", "http://s3.amazonaws.com/", http.Header{"X-Amz-Security-Token": {"token-b"}}, "")
+	sendTestRequest(t, client, "GET", "http://s3.amazonaws.com/", http.Header{"X-Amz-Security-Token": {"token-unknown"}}, "")
+
+	var got []string
+	for _, entry := range callLog.Snapshot() {
+		got = append(got, entry.Service+"."+entry.Method+" "+entry.AssumedRoleARN)
+	}
+	want := []string{
+		"STS.AssumeRole " + roleA,
+		"STS.AssumeRole " + roleB,
+		"S3.ListBuckets " + roleB,
+		"S3.ListBuckets ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls %q, want %q", got, want)
+	}
+
+	if got, want := getRoleChainParents(), map[string]string{roleA: "", roleB: roleA}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got role chain %v, want %v", got, want)
+	}
+
+	// the role is kept in the persistent log and the /events stream
+	data, err := json.Marshal(newPersistedCall(callLog.Snapshot()[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"AssumedRoleArn":"`+roleB+`"`) {
+		t.Errorf("got persisted call %s, want AssumedRoleArn %s", data, roleB)
+	}
+}
+
+func TestGetResponseSessionToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		respBody string
+		want     string
+	}{
+		{name: "xml", respBody: "<Credentials><AccessKeyId>ASIA</AccessKeyId><SessionToken>\n  FwoGZXIvYXdzEXAMPLE==\n</SessionToken></Credentials>", want: "FwoGZXIvYXdzEXAMPLE=="},
+		{name: "json", respBody: `{"Credentials": {"SessionToken": "FwoGZXIvYXdzEXAMPLE=="}}`, want: "FwoGZXIvYXdzEXAMPLE=="},
+		{name: "error", respBody: "<ErrorResponse><Error><Code>AccessDenied</Code></Error></ErrorResponse>"},
+		{name: "empty", respBody: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getResponseSessionToken([]byte(tt.respBody)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// s3VirtualHostedRegexp matches virtual-hosted-style S3 hostnames, including legacy dash-region, FIPS, accelerated and
+// dual-stack endpoints, but not S3 Control, access point or Object Lambda hostnames
+var s3VirtualHostedRegexp = regexp.MustCompile(`^(.+)\.s3(?:-fips)?(?:-accelerate)?(?:\.dualstack)?(?:[.-][a-z]{2}(?:-[a-z]+)+-[0-9]+)?\.amazonaws\.com(?:\.cn)?$`)
+
+// getS3VirtualHostedBucket returns the bucket named in the subdomain of a virtual-hosted-style S3 hostname, or an
+// empty string if the hostname is path-style or not S3
+func getS3VirtualHostedBucket(host string) string {
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+
+	matches := s3VirtualHostedRegexp.FindStringSubmatch(strings.ToLower(host))
+	if matches == nil {
+		return ""
+	}
+
+	return matches[1]
+}
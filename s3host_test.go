@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetS3VirtualHostedBucket(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "orders-bucket.s3.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3.us-east-1.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3-us-west-2.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3-accelerate.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3-accelerate.dualstack.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3.dualstack.us-east-1.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3-fips.us-gov-west-1.amazonaws.com", want: "orders-bucket"},
+		{host: "orders-bucket.s3.cn-north-1.amazonaws.com.cn", want: "orders-bucket"},
+		{host: "my.dotted.bucket.s3.eu-west-1.amazonaws.com", want: "my.dotted.bucket"},
+		{host: "Orders-Bucket.S3.US-EAST-1.amazonaws.com:443", want: "orders-bucket"},
+		{host: "s3.us-east-1.amazonaws.com"},
+		{host: "s3.amazonaws.com"},
+		{host: "s3.dualstack.us-east-1.amazonaws.com"},
+		{host: "123456789012.s3-control.us-east-1.amazonaws.com"},
+		{host: "reports-123456789012.s3-accesspoint.us-east-1.amazonaws.com"},
+		{host: "transform-123456789012.s3-object-lambda.us-east-1.amazonaws.com"},
+		{host: "dynamodb.us-east-1.amazonaws.com"},
+		{host: "orders-bucket.s3.us-east-1.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := getS3VirtualHostedBucket(tt.host); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyS3URLStyles(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		wantMethod    string
+		wantURIParams map[string]string
+		wantARNs      []string
+	}{
+		{
+			name:          "path-style object",
+			method:        "GET",
+			url:           "http://s3.us-east-1.amazonaws.com/orders-bucket/reports/2024.csv",
+			wantMethod:    "GetObject",
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "reports/2024.csv"},
+			wantARNs:      []string{"arn:aws:s3:::orders-bucket/reports/2024.csv"},
+		},
+		{
+			name:          "virtual-hosted object",
+			method:        "GET",
+			url:           "http://orders-bucket.s3.us-east-1.amazonaws.com/reports/2024.csv",
+			wantMethod:    "GetObject",
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "reports/2024.csv"},
+			wantARNs:      []string{"arn:aws:s3:::orders-bucket/reports/2024.csv"},
+		},
+		{
+			name:          "virtual-hosted bucket subresource",
+			method:        "GET",
+			url:           "http://orders-bucket.s3.us-east-1.amazonaws.com/?lifecycle",
+			wantMethod:    "GetBucketLifecycleConfiguration",
+			wantURIParams: map[string]string{"Bucket": "orders-bucket"},
+			wantARNs:      []string{"arn:aws:s3:::orders-bucket"},
+		},
+		{
+			name:          "accelerated object",
+			method:        "PUT",
+			url:           "http://orders-bucket.s3-accelerate.amazonaws.com/uploads/image.png",
+			wantMethod:    "PutObject",
+			wantURIParams: map[string]string{"Bucket": "orders-bucket", "Key": "uploads/image.png"},
+			wantARNs:      []string{"arn:aws:s3:::orders-bucket/uploads/image.png"},
+		},
+		{
+			name:          "dual-stack listing",
+			method:        "GET",
+			url:           "http://orders-bucket.s3.dualstack.us-east-1.amazonaws.com/?list-type=2&prefix=reports/",
+			wantMethod:    "ListObjectsV2",
+			wantURIParams: map[string]string{"Bucket": "orders-bucket"},
+			wantARNs:      []string{"arn:aws:s3:::orders-bucket"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTestCallLog(t)
+			client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			sendTestRequest(t, client, tt.method, tt.url, nil, "")
+
+			entry := getSingleTestEntry(t)
+			if entry.Service != "S3" || entry.Method != tt.wantMethod {
+				t.Errorf("got call %s.%s, want S3.%s", entry.Service, entry.Method, tt.wantMethod)
+			}
+			if !reflect.DeepEqual(entry.URIParameters, tt.wantURIParams) {
+				t.Errorf("got URI params %v, want %v", entry.URIParameters, tt.wantURIParams)
+			}
+			if !reflect.DeepEqual(entry.ResourceARNs, tt.wantARNs) {
+				t.Errorf("got resource ARNs %v, want %v", entry.ResourceARNs, tt.wantARNs)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/go-homedir"
+)
+
+// serviceDefinitionsMutex protects serviceDefinitions and latestServiceDefinitions, which are replaced rather than
+// modified when a file in --service-dir changes
+var serviceDefinitionsMutex sync.RWMutex
+
+// serviceDefinitionFiles holds the service definitions by file name, as several definitions share an endpoint
+// prefix (e.g. RDS, DocDB and Neptune, or the API versions of a service)
+var serviceDefinitionFiles = make(map[string]ServiceDefinition)
+
+// getServiceDefinitions returns the service definitions, latest API version first
+func getServiceDefinitions() []ServiceDefinition {
+	serviceDefinitionsMutex.RLock()
+	defer serviceDefinitionsMutex.RUnlock()
+
+	return serviceDefinitions
+}
+
+// getLatestServiceDefinition returns the latest service definition with an endpoint prefix
+func getLatestServiceDefinition(endpointPrefix string) (ServiceDefinition, bool) {
+	serviceDefinitionsMutex.RLock()
+	defer serviceDefinitionsMutex.RUnlock()
+
+	serviceDefinition, ok := latestServiceDefinitions[endpointPrefix]
+	return serviceDefinition, ok
+}
+
+// isCanonicalServiceDefinitionFile returns whether a service definition file is named after its endpoint prefix,
+// such as rds-2014-10-31.min.json rather than docdb-2014-10-31.min.json for the rds endpoint prefix
+func isCanonicalServiceDefinitionFile(fileName string) bool {
+	return strings.HasPrefix(fileName, serviceDefinitionFiles[fileName].Metadata.EndpointPrefix+"-")
+}
+
+// parseServiceVersion parses the YYYY-MM-DD API version of a service definition, returning the zero time if it is
+// malformed
+func parseServiceVersion(version string) time.Time {
+	parsed, err := time.Parse("2006-01-02", version)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+// setServiceDefinitions replaces the service definitions with those of serviceDefinitionFiles, and must be called
+// with serviceDefinitionsMutex locked
+func setServiceDefinitions() {
+	var fileNames []string
+	for fileName := range serviceDefinitionFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	// where an endpoint prefix has several definitions, the latest API version comes first, malformed versions last,
+	// and of those with the same version (e.g. RDS, DocDB and Neptune) the one named after the endpoint prefix
+	sort.SliceStable(fileNames, func(i, j int) bool {
+		versionI := parseServiceVersion(serviceDefinitionFiles[fileNames[i]].Metadata.APIVersion)
+		versionJ := parseServiceVersion(serviceDefinitionFiles[fileNames[j]].Metadata.APIVersion)
+		if !versionI.Equal(versionJ) {
+			return versionI.After(versionJ)
+		}
+		return isCanonicalServiceDefinitionFile(fileNames[i]) && !isCanonicalServiceDefinitionFile(fileNames[j])
+	})
+
+	definitions := []ServiceDefinition{}
+	latest := make(map[string]ServiceDefinition)
+	for _, fileName := range fileNames {
+		serviceDefinition := serviceDefinitionFiles[fileName]
+		definitions = append(definitions, serviceDefinition)
+
+		endpointPrefix := serviceDefinition.Metadata.EndpointPrefix
+		if latestDefinition, ok := latest[endpointPrefix]; !ok {
+			latest[endpointPrefix] = serviceDefinition
+		} else if *serviceDirFlag != "" && latestDefinition.Metadata.ServiceID == serviceDefinition.Metadata.ServiceID {
+			// the embedded definitions include older API versions on purpose, but in --service-dir they may be left over
+			log.Printf("WARNING: multiple definitions for endpoint prefix %q; using version %q", endpointPrefix, latestDefinition.Metadata.APIVersion)
+		}
+	}
+
+	serviceDefinitions = definitions
+	latestServiceDefinitions = latest
+}
+
+func isServiceDefinitionFile(fileName string) bool {
+	return strings.HasSuffix(fileName, ".json") && !strings.HasPrefix(fileName, ".")
+}
+
+// readServiceDirFiles reads the service definitions in --service-dir
+func readServiceDirFiles() error {
+	serviceDir, err := homedir.Expand(*serviceDirFlag)
+	if err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(serviceDir)
+	if err != nil {
+		return fmt.Errorf("could not read --service-dir: %v", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !isServiceDefinitionFile(file.Name()) {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(serviceDir, file.Name()))
+		if err != nil {
+			return err
+		}
+
+		var def ServiceDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("could not parse the service definition %s: %v", file.Name(), err)
+		}
+		serviceDefinitionFiles[file.Name()] = def
+	}
+
+	if len(serviceDefinitionFiles) == 0 {
+		return fmt.Errorf("no service definitions were found in --service-dir %s", *serviceDirFlag)
+	}
+
+	return nil
+}
+
+// reloadServiceDirFile updates the service definitions after a file in --service-dir was written or removed,
+// keeping any previous definition if the file cannot be parsed (such as while it is still being written)
+func reloadServiceDirFile(path string, removed bool) {
+	fileName := filepath.Base(path)
+	if !isServiceDefinitionFile(fileName) {
+		return
+	}
+
+	var def ServiceDefinition
+	if !removed {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Printf("WARNING: could not read the service definition %s: %v", fileName, err)
+			return
+		}
+		if err := json.Unmarshal(data, &def); err != nil {
+			log.Printf("WARNING: could not parse the service definition %s, so it was not reloaded: %v", fileName, err)
+			return
+		}
+	}
+
+	serviceDefinitionsMutex.Lock()
+	defer serviceDefinitionsMutex.Unlock()
+
+	if removed {
+		if _, ok := serviceDefinitionFiles[fileName]; !ok {
+			return
+		}
+		delete(serviceDefinitionFiles, fileName)
+		log.Printf("Removed the service definition %s", fileName)
+	} else {
+		serviceDefinitionFiles[fileName] = def
+		log.Printf("Reloaded the service definition %s", fileName)
+	}
+	setServiceDefinitions()
+}
+
+// watchServiceDir reloads the service definitions in --service-dir as they are created, written or removed
+func watchServiceDir() error {
+	if *serviceDirFlag == "" {
+		return nil
+	}
+
+	serviceDir, err := homedir.Expand(*serviceDirFlag)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	err = watcher.Add(serviceDir)
+	if err != nil {
+		watcher.Close()
+		return fmt.Errorf("could not watch --service-dir: %v", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+					reloadServiceDirFile(event.Name, false)
+				} else if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 { // a file renamed away is gone
+					reloadServiceDirFile(event.Name, true)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("WARNING: could not watch --service-dir: %v", err)
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+
+	return nil
+}
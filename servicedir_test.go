@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setTestServiceDefinitionFiles replaces the service definitions with those given by file name, until the end of
+// the test
+func setTestServiceDefinitionFiles(t *testing.T, files map[string]ServiceDefinition) {
+	t.Helper()
+
+	serviceDefinitionsMutex.Lock()
+	previous := serviceDefinitionFiles
+	serviceDefinitionFiles = files
+	setServiceDefinitions()
+	serviceDefinitionsMutex.Unlock()
+
+	t.Cleanup(func() {
+		serviceDefinitionsMutex.Lock()
+		serviceDefinitionFiles = previous
+		setServiceDefinitions()
+		serviceDefinitionsMutex.Unlock()
+	})
+}
+
+func getTestServiceDefinition(endpointPrefix string, serviceID string, apiVersion string) ServiceDefinition {
+	var def ServiceDefinition
+	def.Metadata.EndpointPrefix = endpointPrefix
+	def.Metadata.ServiceID = serviceID
+	def.Metadata.APIVersion = apiVersion
+	return def
+}
+
+func TestParseServiceVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    time.Time
+	}{
+		{version: "2015-03-31", want: time.Date(2015, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{version: "2006-03-01", want: time.Date(2006, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{version: ""},
+		{version: "latest"},
+		{version: "2015-3-31"},
+		{version: "2015-13-01"},
+		{version: "2015-02-30"},
+		{version: "20150331"},
+		{version: "2015-03-31T00:00:00Z"},
+		{version: " 2015-03-31"},
+		{version: "v2015-03-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := parseServiceVersion(tt.version); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestServiceDefinition(t *testing.T) {
+	setTestServiceDefinitionFiles(t, map[string]ServiceDefinition{
+		"lambda-2015-03-31.min.json":  getTestServiceDefinition("lambda", "Lambda", "2015-03-31"),
+		"lambda-2014-11-11.min.json":  getTestServiceDefinition("lambda", "Lambda", "2014-11-11"),
+		"lambda-preview.min.json":     getTestServiceDefinition("lambda", "Lambda", "preview"),
+		"docdb-2014-10-31.min.json":   getTestServiceDefinition("rds", "DocDB", "2014-10-31"),
+		"rds-2014-10-31.min.json":     getTestServiceDefinition("rds", "RDS", "2014-10-31"),
+		"neptune-2014-10-31.min.json": getTestServiceDefinition("rds", "Neptune", "2014-10-31"),
+		"widgets-beta.min.json":       getTestServiceDefinition("widgets", "Widgets", "beta"),
+	})
+
+	tests := []struct {
+		endpointPrefix string
+		wantService    string
+		wantVersion    string
+	}{
+		{endpointPrefix: "lambda", wantService: "Lambda", wantVersion: "2015-03-31"},
+		{endpointPrefix: "rds", wantService: "RDS", wantVersion: "2014-10-31"},
+		{endpointPrefix: "widgets", wantService: "Widgets", wantVersion: "beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpointPrefix, func(t *testing.T) {
+			def, ok := getLatestServiceDefinition(tt.endpointPrefix)
+			if !ok {
+				t.Fatal("got no service definition")
+			}
+			if def.Metadata.ServiceID != tt.wantService || def.Metadata.APIVersion != tt.wantVersion {
+				t.Errorf("got %s %s, want %s %s", def.Metadata.ServiceID, def.Metadata.APIVersion, tt.wantService, tt.wantVersion)
+			}
+		})
+	}
+
+	// malformed versions sort after every dated version
+	var lambdaVersions []string
+	for _, def := range getServiceDefinitions() {
+		if def.Metadata.EndpointPrefix == "lambda" {
+			lambdaVersions = append(lambdaVersions, def.Metadata.APIVersion)
+		}
+	}
+	if want := []string{"2015-03-31", "2014-11-11", "preview"}; !reflect.DeepEqual(lambdaVersions, want) {
+		t.Errorf("got lambda versions %v, want %v", lambdaVersions, want)
+	}
+}
+
+// writeTestLambdaDefinition writes the embedded Lambda service definition to a file, with the request URI of
+// GetFunction replaced
+func writeTestLambdaDefinition(t *testing.T, file string, getFunctionURI string) {
+	t.Helper()
+
+	data, err := serviceFiles.ReadFile("service/lambda-2015-03-31.min.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var def map[string]interface{}
+	if err := json.Unmarshal(data, &def); err != nil {
+		t.Fatal(err)
+	}
+	def["operations"].(map[string]interface{})["GetFunction"].(map[string]interface{})["http"].(map[string]interface{})["requestUri"] = getFunctionURI
+	data, err = json.Marshal(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForTestServiceDefinition waits for the watcher of --service-dir to have reloaded the definitions, failing the
+// test if the condition is not met within a few seconds
+func waitForTestServiceDefinition(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the service definitions were not reloaded: %s", description)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestServiceDirHotReload(t *testing.T) {
+	setTestServiceDefinitionFiles(t, map[string]ServiceDefinition{})
+	dir := t.TempDir()
+	setTestFlag(t, "service-dir", dir)
+
+	// a file name none of the embedded definitions use, as the watcher outlives the test
+	lambdaFile := filepath.Join(dir, "lambda-hot-reload.json")
+	writeTestLambdaDefinition(t, lambdaFile, "/2015-03-31/functions/{FunctionName}")
+	readServiceFiles()
+	if err := watchServiceDir(); err != nil {
+		t.Fatal(err)
+	}
+
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	// getMethod returns the Lambda operation a request for a path was recorded as, if any
+	getMethod := func(path string) string {
+		callLog.Reset()
+		sendTestRequest(t, client, "GET", "http://lambda.us-east-1.amazonaws.com"+path, nil, "")
+		entries := callLog.Snapshot()
+		if len(entries) != 1 {
+			return ""
+		}
+		return entries[0].Service + "." + entries[0].Method
+	}
+	t.Cleanup(callLog.Reset)
+
+	if got := getMethod("/2015-03-31/functions/orders"); got != "Lambda.GetFunction" {
+		t.Fatalf("got %q from --service-dir, want Lambda.GetFunction", got)
+	}
+
+	// a changed definition is used for later requests
+	writeTestLambdaDefinition(t, lambdaFile, "/2015-03-31/functions/{FunctionName}/definition")
+	waitForTestServiceDefinition(t, "GetFunction has the new request URI", func() bool {
+		return getMethod("/2015-03-31/functions/orders/definition") == "Lambda.GetFunction"
+	})
+	if got := getMethod("/2015-03-31/functions/orders"); got == "Lambda.GetFunction" {
+		t.Errorf("the previous request URI of GetFunction is still used")
+	}
+
+	// a broken file keeps the previous definition; the watcher handles events in order, so it has been seen once a
+	// later file is loaded
+	if err := ioutil.WriteFile(lambdaFile, []byte(`{"metadata": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "widgets-hot-reload.json"), []byte(`{"metadata": {"endpointPrefix": "widgets", "serviceId": "Widgets"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitForTestServiceDefinition(t, "Widgets is added", func() bool {
+		_, ok := getLatestServiceDefinition("widgets")
+		return ok
+	})
+	if got := getMethod("/2015-03-31/functions/orders/definition"); got != "Lambda.GetFunction" {
+		t.Errorf("got %q after a broken file was written, want the previous definition kept", got)
+	}
+
+	// a removed file removes its definition
+	if err := os.Remove(lambdaFile); err != nil {
+		t.Fatal(err)
+	}
+	waitForTestServiceDefinition(t, "Lambda is removed", func() bool {
+		_, ok := getLatestServiceDefinition("lambda")
+		return !ok
+	})
+	if got := getMethod("/2015-03-31/functions/orders/definition"); got == "Lambda.GetFunction" {
+		t.Errorf("got %q after the definition was removed", got)
+	}
+}
+
+func TestServiceDefinitionVersionConflict(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	setTestServiceDefinitionFiles(t, map[string]ServiceDefinition{})
+	dir := t.TempDir()
+	setTestFlag(t, "service-dir", dir)
+	for fileName, version := range map[string]string{
+		"ec2-2015-10-01.json": "2015-10-01",
+		"ec2-2016-11-15.json": "2016-11-15",
+		"ec2-2014-10-01.json": "2014-10-01",
+	} {
+		data := `{"metadata": {"apiVersion": "` + version + `", "endpointPrefix": "ec2", "serviceId": "EC2", "protocol": "ec2"}}`
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readServiceFiles()
+
+	def, ok := getLatestServiceDefinition("ec2")
+	if !ok || def.Metadata.APIVersion != "2016-11-15" {
+		t.Errorf("got ec2 version %q, want 2016-11-15", def.Metadata.APIVersion)
+	}
+	want := `WARNING: multiple definitions for endpoint prefix "ec2"; using version "2016-11-15"`
+	if got := strings.Count(logs.String(), want); got != 2 {
+		t.Errorf("got logs %q, want the conflict warned of for each older version", logs.String())
+	}
+}
+
+func TestEmbeddedServiceDefinitions(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+
+	// the embedded definitions include older API versions on purpose, so they are not warned of
+	serviceDefinitionsMutex.Lock()
+	setServiceDefinitions()
+	serviceDefinitionsMutex.Unlock()
+	if strings.Contains(logs.String(), "WARNING: multiple definitions") {
+		t.Errorf("got logs %q for the embedded definitions, want no warning", logs.String())
+	}
+
+	tests := []struct {
+		endpointPrefix string
+		wantService    string
+		wantVersion    string
+	}{
+		{endpointPrefix: "ec2", wantService: "EC2", wantVersion: "2016-11-15"},
+		{endpointPrefix: "rds", wantService: "RDS", wantVersion: "2014-10-31"},
+		{endpointPrefix: "events", wantService: "CloudWatch Events", wantVersion: "2015-10-07"},
+		{endpointPrefix: "lambda", wantService: "Lambda", wantVersion: "2015-03-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpointPrefix, func(t *testing.T) {
+			def, ok := getLatestServiceDefinition(tt.endpointPrefix)
+			if !ok {
+				t.Fatal("got no service definition")
+			}
+			if def.Metadata.ServiceID != tt.wantService || def.Metadata.APIVersion != tt.wantVersion {
+				t.Errorf("got %s %s, want %s %s", def.Metadata.ServiceID, def.Metadata.APIVersion, tt.wantService, tt.wantVersion)
+			}
+		})
+	}
+}
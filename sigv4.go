@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+const sigV4Algorithm = "AWS4-HMAC-SHA256"
+
+// SigV4Authorization is the parsed Authorization header of a request signed with Signature Version 4
+type SigV4Authorization struct {
+	AccessKeyID   string
+	Date          string
+	Region        string
+	Service       string
+	SignedHeaders []string
+	Signature     string
+}
+
+// parseSigV4Authorization parses a header of the form
+// AWS4-HMAC-SHA256 Credential=AKID/20060102/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=...
+func parseSigV4Authorization(header string) (*SigV4Authorization, error) {
+	if !strings.HasPrefix(header, sigV4Algorithm+" ") {
+		return nil, fmt.Errorf("the request is not signed with %s", sigV4Algorithm)
+	}
+
+	var auth SigV4Authorization
+	for _, component := range strings.Split(strings.TrimPrefix(header, sigV4Algorithm+" "), ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(component), "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+
+		switch keyValue[0] {
+		case "Credential":
+			scope := strings.Split(keyValue[1], "/")
+			if len(scope) != 5 || scope[4] != "aws4_request" {
+				return nil, fmt.Errorf("invalid credential scope %q", keyValue[1])
+			}
+			auth.AccessKeyID = scope[0]
+			auth.Date = scope[1]
+			auth.Region = scope[2]
+			auth.Service = scope[3]
+		case "SignedHeaders":
+			auth.SignedHeaders = strings.Split(keyValue[1], ";")
+		case "Signature":
+			auth.Signature = keyValue[1]
+		}
+	}
+
+	if auth.AccessKeyID == "" || len(auth.SignedHeaders) == 0 {
+		return nil, fmt.Errorf("invalid %s authorization header", sigV4Algorithm)
+	}
+
+	return &auth, nil
+}
+
+// signRequestV4 signs a request with Signature Version 4, replacing any existing signature and session token.
+// Only host, the x-amz- headers set here and the given headers are signed, as the other headers of a proxied request
+// are not always sent to AWS as they are.
+func signRequestV4(req *http.Request, body []byte, service string, region string, accessKeyID string, secretAccessKey string, sessionToken string, signedHeaders []string, signTime time.Time) error {
+	payloadHash := sha256.Sum256(body)
+	payloadHashHex := hex.EncodeToString(payloadHash[:])
+	if contentSHA256 := req.Header.Get("X-Amz-Content-Sha256"); contentSHA256 == "UNSIGNED-PAYLOAD" {
+		payloadHashHex = contentSHA256
+	} else if contentSHA256 != "" || service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHashHex)
+	}
+
+	// the signer signs every header of the request it is given, so it is given a copy with just the headers to sign
+	signReq := req.Clone(context.Background())
+	signReq.Header = make(http.Header)
+	signReq.ContentLength = 0
+	for _, header := range append([]string{"X-Amz-Content-Sha256"}, signedHeaders...) {
+		switch header = http.CanonicalHeaderKey(header); header {
+		case "Host", "X-Amz-Date", "X-Amz-Security-Token":
+		case "Content-Length":
+			signReq.ContentLength = int64(len(body))
+		default:
+			if values := req.Header.Values(header); len(values) > 0 {
+				signReq.Header[header] = values
+			}
+		}
+	}
+
+	signer := v4.NewSigner(func(o *v4.SignerOptions) {
+		o.DisableURIPathEscaping = service == "s3" // S3 signs the path as sent
+	})
+	credentials := aws.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}
+	if err := signer.SignHTTP(context.Background(), credentials, signReq, payloadHashHex, service, region, signTime); err != nil {
+		return err
+	}
+
+	req.Header.Del("X-Amz-Security-Token")
+	for _, header := range []string{"Authorization", "X-Amz-Date", "X-Amz-Security-Token"} {
+		if value := signReq.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignRequestV4(t *testing.T) {
+	body := "Action=GetCallerIdentity&Version=2011-06-15"
+	req, err := http.NewRequest("POST", "https://sts.amazonaws.com/", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("User-Agent", "aws-cli/2.7.0")
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-Amz-Security-Token", "original-token")
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDORIGINAL/20240301/us-east-1/sts/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature=0123456789abcdef")
+
+	err = signRequestV4(req, []byte(body), "sts", "us-east-1", "AKIDRESIGNED", "resigned-secret", "", []string{"content-type", "host", "x-amz-date", "x-amz-security-token"}, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := parseSigV4Authorization(req.Header.Get("Authorization"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.AccessKeyID != "AKIDRESIGNED" || auth.Date != "20240301" || auth.Region != "us-east-1" || auth.Service != "sts" {
+		t.Errorf("got credential scope %+v, want AKIDRESIGNED/20240301/us-east-1/sts", auth)
+	}
+	// only the headers signed originally are signed again, without the session token the new credentials don't have
+	if got := strings.Join(auth.SignedHeaders, ";"); got != "content-type;host;x-amz-date" {
+		t.Errorf("got signed headers %s, want content-type;host;x-amz-date", got)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20240301T100000Z" {
+		t.Errorf("got X-Amz-Date %s, want 20240301T100000Z", got)
+	}
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "" {
+		t.Errorf("got session token %s, want the original one removed", got)
+	}
+	if got := req.Header.Get("X-Forwarded-For"); got != "10.0.0.1" {
+		t.Errorf("got X-Forwarded-For %s, want the unsigned header kept", got)
+	}
+
+	if err := signRequestV4(req, []byte(body), "sts", "us-east-1", "ASIARESIGNED", "resigned-secret", "resigned-token", []string{"content-type"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if auth, err := parseSigV4Authorization(req.Header.Get("Authorization")); err != nil || strings.Join(auth.SignedHeaders, ";") != "content-type;host;x-amz-date;x-amz-security-token" {
+		t.Errorf("got authorization %s, want the session token signed", req.Header.Get("Authorization"))
+	}
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "resigned-token" {
+		t.Errorf("got session token %s, want resigned-token", got)
+	}
+}
+
+func TestSignRequestV4S3(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://orders-bucket.s3.us-east-1.amazonaws.com/reports/2024%2003.csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := signRequestV4(req, nil, "s3", "us-east-1", "AKIDRESIGNED", "resigned-secret", "", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("got payload hash %s, want the hash of the empty body", got)
+	}
+	if auth, err := parseSigV4Authorization(req.Header.Get("Authorization")); err != nil || strings.Join(auth.SignedHeaders, ";") != "host;x-amz-content-sha256;x-amz-date" {
+		t.Errorf("got authorization %s, want the payload hash signed", req.Header.Get("Authorization"))
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// singleUseCredentialsDuration is the minimum duration of credentials from sts:AssumeRole, in seconds
+const singleUseCredentialsDuration = 900
+
+// singleUseSTSClient is the client calling sts:AssumeRole for --aws-single-use-credentials
+var singleUseSTSClient = &http.Client{Timeout: 30 * time.Second}
+
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyID     string `xml:"AccessKeyId"`
+		SecretAccessKey string `xml:"SecretAccessKey"`
+		SessionToken    string `xml:"SessionToken"`
+	} `xml:"AssumeRoleResult>Credentials"`
+}
+
+func validateSingleUseCredentials() error {
+	if !*awsSingleUseCredentialsFlag {
+		return nil
+	}
+	if *modeFlag != "proxy" {
+		return fmt.Errorf("--aws-single-use-credentials requires proxy mode")
+	}
+	if *roleARNFlag == "" {
+		return fmt.Errorf("--aws-single-use-credentials requires --role-arn")
+	}
+
+	// the session policy must name resources in the account of the role, rather than the --account-id placeholder
+	arnSplit := strings.SplitN(*roleARNFlag, ":", 6)
+	if len(arnSplit) != 6 || arnSplit[0] != "arn" || arnSplit[2] != "iam" || !regexp.MustCompile(`^[0-9]{12}$`).MatchString(arnSplit[4]) || !strings.HasPrefix(arnSplit[5], "role/") {
+		return fmt.Errorf("--role-arn %q is not an IAM role ARN", *roleARNFlag)
+	}
+	if *accountIDFlag != placeholderAccountID && *accountIDFlag != arnSplit[4] {
+		return fmt.Errorf("--account-id %s does not match the account %s of --role-arn, which --aws-single-use-credentials scopes the policy to", *accountIDFlag, arnSplit[4])
+	}
+	*accountIDFlag = arnSplit[4]
+
+	_, _, _, err := getAWSCredentials()
+	return err
+}
+
+// assumeRoleWithSessionPolicy returns temporary credentials for the --role-arn role restricted by the policy
+func assumeRoleWithSessionPolicy(policy string) (accessKeyID string, secretAccessKey string, sessionToken string, err error) {
+	baseAccessKeyID, baseSecretAccessKey, baseSessionToken, err := getAWSCredentials()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	region := getAWSRegion()
+	body := url.Values{
+		"Action":          []string{"AssumeRole"},
+		"Version":         []string{"2011-06-15"},
+		"RoleArn":         []string{*roleARNFlag},
+		"RoleSessionName": []string{fmt.Sprintf("iamlive-%d", time.Now().UnixNano())},
+		"DurationSeconds": []string{fmt.Sprintf("%d", singleUseCredentialsDuration)},
+		"Policy":          []string{policy},
+	}.Encode()
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("https://sts.%s.amazonaws.com/", region), strings.NewReader(body))
+	if err != nil {
+		return "", "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if err := signRequestV4(req, []byte(body), "sts", region, baseAccessKeyID, baseSecretAccessKey, baseSessionToken, []string{"content-type"}, time.Now()); err != nil {
+		return "", "", "", err
+	}
+
+	resp, err := singleUseSTSClient.Do(req)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", "", err
+	}
+	if resp.StatusCode >= 300 {
+		return "", "", "", fmt.Errorf("sts:AssumeRole returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var assumeRole assumeRoleResponse
+	if err := xml.Unmarshal(respBody, &assumeRole); err != nil {
+		return "", "", "", err
+	}
+
+	return assumeRole.Credentials.AccessKeyID, assumeRole.Credentials.SecretAccessKey, assumeRole.Credentials.SessionToken, nil
+}
+
+// getSessionPolicy returns the policy for the calls captured so far and the call being made, if it has yet to be
+// recorded. Unlike getPolicy, account IDs are never redacted as the policy must be usable, and resources are in the
+// account of the --role-arn role, as set by validateSingleUseCredentials.
+func getSessionPolicy(pending *Entry) IAMPolicy {
+	entries := callLog.Snapshot()
+
+	if pending != nil {
+		entries = append(entries, *pending)
+	}
+
+	policy := IAMPolicy{
+		Version:   "2012-10-17",
+		Statement: []Statement{},
+	}
+	for _, entry := range entries {
+		policy.Statement = append(policy.Statement, getStatementsForProxyCall(entry, *accountIDFlag)...)
+	}
+
+	return aggregatePolicy(policy)
+}
+
+// resignWithSingleUseCredentials re-signs a proxied request with credentials scoped to the policy captured so far,
+// including the call being made if it has yet to be recorded
+func resignWithSingleUseCredentials(req *http.Request, body []byte, entry *Entry) error {
+	if req.URL.Query().Get("X-Amz-Signature") != "" {
+		return fmt.Errorf("presigned URLs cannot be re-signed")
+	}
+	if strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return fmt.Errorf("requests with chunk signatures cannot be re-signed")
+	}
+
+	auth, err := parseSigV4Authorization(req.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+
+	policy := getSessionPolicy(entry)
+	if len(policy.Statement) == 0 {
+		return fmt.Errorf("no calls have been captured to scope the credentials")
+	}
+	policyDoc, err := json.Marshal(policy) // compact, as session policies are limited in size
+	if err != nil {
+		return err
+	}
+
+	accessKeyID, secretAccessKey, sessionToken, err := assumeRoleWithSessionPolicy(string(policyDoc))
+	if err != nil {
+		return err
+	}
+
+	return signRequestV4(req, body, auth.Service, auth.Region, accessKeyID, secretAccessKey, sessionToken, auth.SignedHeaders, time.Now())
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRoleARN = "arn:aws:iam::210987654321:role/iamlive-test"
+
+// setTestSingleUseCredentials enables --aws-single-use-credentials with credentials for assuming testRoleARN
+func setTestSingleUseCredentials(t *testing.T) {
+	t.Helper()
+
+	setTestFlag(t, "aws-single-use-credentials", "true")
+	setTestFlag(t, "role-arn", testRoleARN)
+	setTestFlag(t, "account-id", placeholderAccountID)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDBASE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "base-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+}
+
+// startTestSTS stands in for sts:AssumeRole until the end of the test
+func startTestSTS(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	previous := singleUseSTSClient
+	singleUseSTSClient = &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	t.Cleanup(func() {
+		singleUseSTSClient = previous
+	})
+}
+
+func TestValidateSingleUseCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		roleARN       string
+		accountID     string
+		wantErr       string
+		wantAccountID string
+	}{
+		{name: "account from the role", roleARN: testRoleARN, accountID: placeholderAccountID, wantAccountID: "210987654321"},
+		{name: "matching account", roleARN: testRoleARN, accountID: "210987654321", wantAccountID: "210987654321"},
+		{name: "role with a path", roleARN: "arn:aws:iam::210987654321:role/service/iamlive-test", accountID: placeholderAccountID, wantAccountID: "210987654321"},
+		{name: "other partition", roleARN: "arn:aws-cn:iam::210987654321:role/iamlive-test", accountID: placeholderAccountID, wantAccountID: "210987654321"},
+		{name: "mismatched account", roleARN: testRoleARN, accountID: "111111111111", wantErr: "does not match the account 210987654321"},
+		{name: "missing role", roleARN: "", accountID: placeholderAccountID, wantErr: "requires --role-arn"},
+		{name: "user rather than role", roleARN: "arn:aws:iam::210987654321:user/iamlive-test", accountID: placeholderAccountID, wantErr: "is not an IAM role ARN"},
+		{name: "invalid account", roleARN: "arn:aws:iam::2109876:role/iamlive-test", accountID: placeholderAccountID, wantErr: "is not an IAM role ARN"},
+		{name: "not an ARN", roleARN: "iamlive-test", accountID: placeholderAccountID, wantErr: "is not an IAM role ARN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestSingleUseCredentials(t)
+			setTestFlag(t, "role-arn", tt.roleARN)
+			setTestFlag(t, "account-id", tt.accountID)
+
+			err := validateSingleUseCredentials()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *accountIDFlag != tt.wantAccountID {
+				t.Errorf("got account %s, want %s", *accountIDFlag, tt.wantAccountID)
+			}
+		})
+	}
+}
+
+func TestSessionPolicyUsesRoleAccount(t *testing.T) {
+	resetTestCallLog(t)
+	setTestSingleUseCredentials(t)
+	if err := validateSingleUseCredentials(); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := getSessionPolicy(&Entry{
+		Region:              "us-east-1",
+		Type:                "ApiCall",
+		Service:             "DynamoDB",
+		Method:              "GetItem",
+		Parameters:          map[string][]string{"TableName": {"orders"}},
+		FinalHTTPStatusCode: 200,
+	})
+
+	want := "arn:aws:dynamodb:us-east-1:210987654321:table/orders"
+	for _, statement := range policy.Statement {
+		if resources, ok := statement.Resource.([]string); ok {
+			for _, resource := range resources {
+				if resource == want {
+					return
+				}
+			}
+		}
+	}
+	t.Errorf("the session policy has no statement for %s: %+v", want, policy.Statement)
+}
+
+// getTestSignedHeader returns the headers of a request to ListBuckets signed with the base credentials
+func getTestSignedHeader(t *testing.T) http.Header {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", "http://s3.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signRequestV4(req, nil, "s3", "us-east-1", "AKIDBASE", "base-secret", "", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return req.Header
+}
+
+func TestProxySingleUseCredentials(t *testing.T) {
+	resetTestCallLog(t)
+	setTestSingleUseCredentials(t)
+	if err := validateSingleUseCredentials(); err != nil {
+		t.Fatal(err)
+	}
+
+	startTestSTS(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if got := r.PostForm.Get("RoleArn"); got != testRoleARN {
+			t.Errorf("got RoleArn %s, want %s", got, testRoleARN)
+		}
+		if got := r.PostForm.Get("Policy"); !strings.Contains(got, "s3:ListAllMyBuckets") {
+			t.Errorf("the session policy %s does not allow s3:ListAllMyBuckets", got)
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=AKIDBASE/") {
+			t.Errorf("sts:AssumeRole is not signed with the base credentials: %s", r.Header.Get("Authorization"))
+		}
+
+		w.Write([]byte(`<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>ASIASINGLEUSE</AccessKeyId><SecretAccessKey>single-use-secret</SecretAccessKey><SessionToken>single-use-token</SessionToken></Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+	})
+
+	var upstreamAuth, upstreamToken string
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamAuth = r.Header.Get("Authorization")
+		upstreamToken = r.Header.Get("X-Amz-Security-Token")
+	}))
+
+	if got := sendTestRequest(t, client, "GET", "http://s3.amazonaws.com/", getTestSignedHeader(t), ""); got != http.StatusOK {
+		t.Fatalf("got status code %d, want 200", got)
+	}
+	if !strings.Contains(upstreamAuth, "Credential=ASIASINGLEUSE/") {
+		t.Errorf("the request was not re-signed with the single-use credentials: %s", upstreamAuth)
+	}
+	if upstreamToken != "single-use-token" {
+		t.Errorf("got session token %q, want single-use-token", upstreamToken)
+	}
+}
+
+func TestProxySingleUseCredentialsFailure(t *testing.T) {
+	resetTestCallLog(t)
+	setTestSingleUseCredentials(t)
+	if err := validateSingleUseCredentials(); err != nil {
+		t.Fatal(err)
+	}
+
+	startTestSTS(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>PackedPolicyTooLarge</Code><Message>Packed policy consumes 102% of allotted space</Message></Error></ErrorResponse>`))
+	})
+
+	forwarded := false
+	client := startTestProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = true
+	}))
+
+	if got := sendTestRequest(t, client, "GET", "http://s3.amazonaws.com/", getTestSignedHeader(t), ""); got != http.StatusBadGateway {
+		t.Fatalf("got status code %d, want 502", got)
+	}
+	if forwarded {
+		t.Error("the request was forwarded with the original credentials")
+	}
+	if entry := getSingleTestEntry(t); entry.FinalHTTPStatusCode != http.StatusBadGateway {
+		t.Errorf("got recorded status code %d, want 502", entry.FinalHTTPStatusCode)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var arnRegexp = regexp.MustCompile(`arn:aws[a-z-]*:[a-z0-9-]+:[a-z0-9-]*:[0-9]{12}:[^\s"'<>&,\]]+`)
+
+// taggedResourceARNs maps resource identifiers (the final segment of the ARN) to the ARNs seen in tagging calls
+var taggedResourceARNs = make(map[string][]string)
+var taggedResourceARNsMutex sync.RWMutex
+
+type ec2DescribeTagsResponse struct {
+	TagSet []struct {
+		ResourceID   string `xml:"resourceId"`
+		ResourceType string `xml:"resourceType"`
+	} `xml:"tagSet>item"`
+}
+
+func isTaggingRequest(req *http.Request, body []byte) bool {
+	action := req.Header.Get("X-Amz-Target")
+	if action == "" {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			action = values.Get("Action")
+		}
+	}
+	if action == "" {
+		action = req.URL.Path
+	}
+
+	return strings.Contains(action, "Tags") || strings.HasSuffix(action, "GetResources") || strings.HasPrefix(req.URL.Path, "/tags/")
+}
+
+func getResourceID(arn string) string {
+	resource := strings.SplitN(arn, ":", 6)
+	if len(resource) != 6 {
+		return ""
+	}
+
+	return resource[5][strings.LastIndexAny(resource[5], "/:")+1:]
+}
+
+func addTaggedResourceARN(arn string) {
+	id := getResourceID(arn)
+	if id == "" || id == "*" {
+		return
+	}
+
+	taggedResourceARNsMutex.Lock()
+	defer taggedResourceARNsMutex.Unlock()
+
+	for _, existingARN := range taggedResourceARNs[id] {
+		if existingARN == arn {
+			return
+		}
+	}
+	taggedResourceARNs[id] = append(taggedResourceARNs[id], arn)
+}
+
+// cacheTaggedResourceARNs records the ARNs of resources referenced by tagging calls, such as ListTagsForResource,
+// DescribeTags and GetResources
+func cacheTaggedResourceARNs(req *http.Request, reqBody []byte, respBody []byte) {
+	path, _ := url.PathUnescape(req.URL.Path)
+	sources := []string{path, string(reqBody), string(respBody)}
+	if unescapedBody, err := url.QueryUnescape(string(reqBody)); err == nil {
+		sources = append(sources, unescapedBody) // query protocol services form encode the parameters
+	}
+	for _, source := range sources {
+		for _, arn := range arnRegexp.FindAllString(source, -1) {
+			addTaggedResourceARN(arn)
+		}
+	}
+
+	// EC2 returns resource IDs rather than ARNs
+	if strings.HasPrefix(req.Host, "ec2.") {
+		var describeTags ec2DescribeTagsResponse
+		if xml.Unmarshal(respBody, &describeTags) == nil {
+			region := getRegionFromHost(req.Host)
+			partition := "aws"
+			if strings.HasPrefix(region, "cn-") {
+				partition = "aws-cn"
+			}
+			for _, tag := range describeTags.TagSet {
+				addTaggedResourceARN(fmt.Sprintf("arn:%s:ec2:%s:%s:%s/%s", partition, region, getPolicyAccountID(), tag.ResourceType, tag.ResourceID))
+			}
+		}
+	}
+}
+
+func wildcardToRegexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$")
+}
+
+// inferResourceARNsFromTags replaces resources that are wildcarded, or in the account of the policy, with the
+// matching ARNs of the action's service seen in tagging calls for the resource identifiers within the call parameters
+func inferResourceARNsFromTags(resources []string, action string, call Entry, accountID string) []string {
+	service := strings.SplitN(action, ":", 2)[0]
+
+	var values []string
+	for _, paramValues := range call.Parameters {
+		values = append(values, paramValues...)
+	}
+	for _, value := range call.URIParameters {
+		values = append(values, value)
+	}
+
+	taggedResourceARNsMutex.RLock()
+	defer taggedResourceARNsMutex.RUnlock()
+
+	var candidates []string
+	for _, value := range values {
+		arns, ok := taggedResourceARNs[value]
+		if !ok {
+			arns = taggedResourceARNs[getResourceID(value)]
+		}
+		for _, arn := range arns {
+			if strings.SplitN(arn, ":", 4)[2] == service {
+				candidates = append(candidates, arn)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return resources
+	}
+
+	var inferred []string
+	for _, resource := range resources {
+		pattern := resource
+		arnSplit := strings.SplitN(resource, ":", 6)
+		if len(arnSplit) == 6 && arnSplit[4] == accountID {
+			arnSplit[4] = "*"
+			pattern = strings.Join(arnSplit, ":")
+		}
+		if !strings.Contains(pattern, "*") {
+			inferred = append(inferred, resource)
+			continue
+		}
+
+		patternRegexp := wildcardToRegexp(pattern)
+		matched := false
+		for _, candidate := range candidates {
+			if patternRegexp.MatchString(candidate) {
+				inferred = append(inferred, candidate)
+				matched = true
+			}
+		}
+		if !matched {
+			inferred = append(inferred, resource)
+		}
+	}
+
+	return uniqueSlice(inferred)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var sessionStartTime = time.Now()
+
+// getTimeBucket returns the label of the bucket a timestamp falls into, counted from the start of the session
+func getTimeBucket(timestamp time.Time) string {
+	bucket := int64(0)
+	if timestamp.After(sessionStartTime) {
+		bucket = int64(timestamp.Sub(sessionStartTime) / *tagEntriesByTimeBucketFlag)
+	}
+
+	return fmt.Sprintf("bucket-%d", bucket)
+}
+
+// getTimeBucketTimeline lists the actions first seen in each time bucket
+func getTimeBucketTimeline() []string {
+	entries := callLog.Snapshot()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	seenActions := make(map[string]bool)
+	bucketActions := make(map[string][]string)
+	var buckets []string
+	for _, entry := range entries {
+		for _, action := range getActions(entry.Service, entry.Method) {
+			if seenActions[action] {
+				continue
+			}
+			seenActions[action] = true
+
+			if _, ok := bucketActions[entry.TimeBucket]; !ok {
+				buckets = append(buckets, entry.TimeBucket)
+			}
+			bucketActions[entry.TimeBucket] = append(bucketActions[entry.TimeBucket], action)
+		}
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	timeline := []string{fmt.Sprintf("Timeline of actions first seen per %s:", *tagEntriesByTimeBucketFlag)}
+	for _, bucket := range buckets {
+		timeline = append(timeline, fmt.Sprintf("  %s: %s", bucket, strings.Join(bucketActions[bucket], ", ")))
+	}
+
+	return timeline
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// uiCallMsg delivers a captured call to the terminal UI
+type uiCallMsg Entry
+
+// uiLogMsg delivers a log message to the status line of the terminal UI
+type uiLogMsg string
+
+// uiLogWriter sends log messages to the terminal UI, which would otherwise be drawn over, dropping them when the
+// UI falls behind rather than blocking the caller
+type uiLogWriter chan string
+
+func (w uiLogWriter) Write(p []byte) (int, error) {
+	select {
+	case w <- strings.TrimSpace(string(p)):
+	default:
+	}
+	return len(p), nil
+}
+
+// uiCall is a row of the call table, with its IAM actions for filtering
+type uiCall struct {
+	entry   Entry
+	actions []string
+}
+
+// uiModel is the terminal UI, showing a table of the captured calls or the current policy
+type uiModel struct {
+	calls         []uiCall
+	events        chan Entry
+	logs          chan string
+	filter        string
+	filterInput   string
+	editingFilter bool
+	showPolicy    bool
+	offset        int
+	follow        bool
+	width         int
+	height        int
+	status        string
+}
+
+func newUIModel(events chan Entry, logs chan string) uiModel {
+	return uiModel{
+		events: events,
+		logs:   logs,
+		follow: true,
+		width:  80,
+		height: 24,
+	}
+}
+
+func waitForCall(events chan Entry) tea.Cmd {
+	return func() tea.Msg {
+		return uiCallMsg(<-events)
+	}
+}
+
+func waitForLog(logs chan string) tea.Cmd {
+	return func() tea.Msg {
+		return uiLogMsg(<-logs)
+	}
+}
+
+// Init starts listening for calls and log messages
+func (m uiModel) Init() tea.Cmd {
+	return tea.Batch(waitForCall(m.events), waitForLog(m.logs))
+}
+
+// isShown returns whether a call matches the filter of the terminal UI, a glob pattern matched against the IAM
+// actions of the call
+func (call uiCall) isShown(filter string) bool {
+	if filter == "" {
+		return true
+	}
+
+	for _, action := range call.actions {
+		if matchesActionPattern(action, []string{filter}) {
+			return true
+		}
+	}
+	return false
+}
+
+// rows returns the lines of the current view, which may be longer than the terminal
+func (m uiModel) rows() []string {
+	if m.showPolicy {
+		return strings.Split(strings.TrimRight(string(getPolicyOutput()), "\n"), "\n")
+	}
+
+	var rows []string
+	for _, call := range m.calls {
+		if !call.isShown(m.filter) {
+			continue
+		}
+		entry := call.entry
+		rows = append(rows, fmt.Sprintf("%-8s  %-24s  %-36s  %-15s  %6d",
+			entry.Timestamp.Format("15:04:05"),
+			truncateUIColumn(entry.Service, 24),
+			truncateUIColumn(entry.Method, 36),
+			truncateUIColumn(entry.Region, 15),
+			entry.FinalHTTPStatusCode,
+		))
+	}
+	return rows
+}
+
+func truncateUIColumn(value string, width int) string {
+	if width > 0 && len(value) > width {
+		return value[:width-1] + "~"
+	}
+	return value
+}
+
+// pageHeight returns the number of rows shown between the header and the status lines
+func (m uiModel) pageHeight() int {
+	if m.height > 5 {
+		return m.height - 4
+	}
+	return 1
+}
+
+// scrollTo moves the first visible row, following new calls when scrolled to the end
+func (m *uiModel) scrollTo(offset int) {
+	maxOffset := len(m.rows()) - m.pageHeight()
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	if offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	m.offset = offset
+	m.follow = offset == maxOffset
+}
+
+func (m uiModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.editingFilter = false
+	case tea.KeyEnter:
+		filter := strings.ToLower(strings.TrimSpace(m.filterInput))
+		if _, err := path.Match(filter, ""); err != nil {
+			m.status = fmt.Sprintf("Invalid filter %q: %v", filter, err)
+			return m, nil
+		}
+		m.filter = filter
+		m.editingFilter = false
+		m.status = ""
+		m.scrollTo(len(m.rows()))
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterInput); len(runes) > 0 {
+			m.filterInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filterInput += string(msg.Runes)
+	}
+
+	return m, nil
+}
+
+// Update handles captured calls, log messages and key presses
+func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case uiCallMsg:
+		m.calls = append(m.calls, uiCall{
+			entry:   Entry(msg),
+			actions: getActions(msg.Service, msg.Method),
+		})
+		if m.follow {
+			m.scrollTo(len(m.rows()))
+		}
+		return m, waitForCall(m.events)
+	case uiLogMsg:
+		m.status = string(msg)
+		return m, waitForLog(m.logs)
+	case tea.WindowSizeMsg:
+		if msg.Width > 0 && msg.Height > 0 { // the size is unknown in some terminals
+			m.width, m.height = msg.Width, msg.Height
+		}
+		m.scrollTo(m.offset)
+		return m, nil
+	case tea.KeyMsg:
+		if m.editingFilter {
+			return m.updateFilter(msg)
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "p":
+			m.showPolicy = !m.showPolicy
+			m.scrollTo(0)
+			if isPolicyFileOutput() {
+				writePolicyToFile()
+				m.status = "Saved the policy to " + getPolicyFileOutputDescription()
+			}
+		case "c":
+			callLog.Reset()
+			m.calls = nil
+			m.scrollTo(0)
+			m.status = "Cleared the captured calls"
+		case "f":
+			m.editingFilter = true
+			m.filterInput = m.filter
+		case "up", "k":
+			m.scrollTo(m.offset - 1)
+		case "down", "j":
+			m.scrollTo(m.offset + 1)
+		case "pgup":
+			m.scrollTo(m.offset - m.pageHeight())
+		case "pgdown", " ":
+			m.scrollTo(m.offset + m.pageHeight())
+		case "home", "g":
+			m.scrollTo(0)
+		case "end", "G":
+			m.scrollTo(len(m.rows()))
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the header, the visible rows, the status line and the key help
+func (m uiModel) View() string {
+	var sb strings.Builder
+
+	if m.showPolicy {
+		sb.WriteString("Policy\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("%-8s  %-24s  %-36s  %-15s  %6s\n", "TIME", "SERVICE", "ACTION", "REGION", "STATUS"))
+	}
+	sb.WriteString(strings.Repeat("-", m.width) + "\n")
+
+	rows := m.rows()
+	for i := m.offset; i < m.offset+m.pageHeight(); i++ {
+		if i < len(rows) {
+			sb.WriteString(truncateUIColumn(rows[i], m.width))
+		}
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case m.editingFilter:
+		sb.WriteString("Filter (e.g. s3:get*): " + m.filterInput + "\n")
+	case m.status != "":
+		sb.WriteString(truncateUIColumn(m.status, m.width) + "\n")
+	default:
+		sb.WriteString(fmt.Sprintf("%d calls", len(m.calls)))
+		if m.filter != "" {
+			sb.WriteString(", filtered by " + m.filter)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("p policy  c clear  f filter  q quit")
+
+	return sb.String()
+}
+
+// startUI runs the terminal UI while calls are captured, then writes the policy and ends the session once it is
+// quit
+func startUI() {
+	events := callEventBroker.Subscribe()
+	logs := make(chan string, 16)
+	log.SetOutput(uiLogWriter(logs))
+
+	program := tea.NewProgram(newUIModel(events, logs), tea.WithAltScreen())
+	go func() {
+		err := program.Start()
+		log.SetOutput(os.Stderr)
+		if err != nil {
+			log.Printf("ERROR: could not run the terminal UI: %v", err)
+		}
+
+		writePolicyToFile()
+		if callLog.Len() > 0 {
+			fmt.Println(string(getPolicyOutput()))
+		}
+		exitSession()
+	}()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateTestUI sends messages to the terminal UI model in turn, returning the model and the command of the last one
+func updateTestUI(t *testing.T, m uiModel, msgs ...tea.Msg) (uiModel, tea.Cmd) {
+	t.Helper()
+
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		var model tea.Model
+		model, cmd = m.Update(msg)
+		m = model.(uiModel)
+	}
+	return m, cmd
+}
+
+// getTestUIKeys returns the key messages of typing a string
+func getTestUIKeys(keys string) []tea.Msg {
+	var msgs []tea.Msg
+	for _, r := range keys {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+func getTestUICall(service string, method string) tea.Msg {
+	return uiCallMsg(Entry{Region: "us-east-1", Type: "ApiCall", Service: service, Method: method, FinalHTTPStatusCode: 200, Timestamp: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)})
+}
+
+// getTestUIRows returns the service and action of the rows shown in the call table
+func getTestUIRows(m uiModel) []string {
+	var rows []string
+	for _, row := range m.rows() {
+		fields := strings.Fields(row)
+		rows = append(rows, fields[1]+"."+fields[2])
+	}
+	return rows
+}
+
+func isTestQuitCmd(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	return reflect.DeepEqual(cmd(), tea.Quit())
+}
+
+func TestUIUpdateCalls(t *testing.T) {
+	events := make(chan Entry, 1)
+	m, cmd := updateTestUI(t, newUIModel(events, make(chan string)), getTestUICall("S3", "ListBuckets"), getTestUICall("EC2", "DescribeInstances"))
+
+	if want := []string{"S3.ListBuckets", "EC2.DescribeInstances"}; !reflect.DeepEqual(getTestUIRows(m), want) {
+		t.Errorf("got rows %v, want %v", getTestUIRows(m), want)
+	}
+	if view := m.View(); !strings.Contains(view, "10:00:00  S3") || !strings.Contains(view, "2 calls") {
+		t.Errorf("the view does not show the calls:\n%s", view)
+	}
+
+	// the next call is waited for after each one
+	events <- Entry{Service: "DynamoDB", Method: "ListTables"}
+	if msg, ok := cmd().(uiCallMsg); !ok || msg.Service != "DynamoDB" {
+		t.Errorf("got message %v, want the next call", msg)
+	}
+}
+
+func TestUIUpdateFilter(t *testing.T) {
+	m, _ := updateTestUI(t, newUIModel(nil, nil), getTestUICall("S3", "ListBuckets"), getTestUICall("S3", "GetObject"), getTestUICall("EC2", "DescribeInstances"))
+
+	tests := []struct {
+		name        string
+		keys        []tea.Msg
+		wantRows    []string
+		wantEditing bool
+		wantStatus  string
+	}{
+		{
+			name:     "glob pattern",
+			keys:     append(append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, getTestUIKeys("S3:List*")...), tea.KeyMsg{Type: tea.KeyEnter}),
+			wantRows: []string{"S3.ListBuckets"},
+		},
+		{
+			name:     "service pattern",
+			keys:     append(append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, getTestUIKeys("ec2:*")...), tea.KeyMsg{Type: tea.KeyEnter}),
+			wantRows: []string{"EC2.DescribeInstances"},
+		},
+		{
+			name:     "corrected with backspace",
+			keys:     append(append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, append(getTestUIKeys("s3:x"), tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g*")})...), tea.KeyMsg{Type: tea.KeyEnter}),
+			wantRows: []string{"S3.GetObject"},
+		},
+		{
+			name:     "cancelled",
+			keys:     append(append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, getTestUIKeys("ec2:*")...), tea.KeyMsg{Type: tea.KeyEsc}),
+			wantRows: []string{"S3.ListBuckets", "S3.GetObject", "EC2.DescribeInstances"},
+		},
+		{
+			name:        "invalid pattern",
+			keys:        append(append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, getTestUIKeys("s3:[")...), tea.KeyMsg{Type: tea.KeyEnter}),
+			wantRows:    []string{"S3.ListBuckets", "S3.GetObject", "EC2.DescribeInstances"},
+			wantEditing: true,
+			wantStatus:  `Invalid filter "s3:["`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := updateTestUI(t, m, tt.keys...)
+
+			if !reflect.DeepEqual(getTestUIRows(got), tt.wantRows) {
+				t.Errorf("got rows %v, want %v", getTestUIRows(got), tt.wantRows)
+			}
+			if got.editingFilter != tt.wantEditing {
+				t.Errorf("got editing %t, want %t", got.editingFilter, tt.wantEditing)
+			}
+			if !strings.HasPrefix(got.status, tt.wantStatus) {
+				t.Errorf("got status %q, want %q", got.status, tt.wantStatus)
+			}
+		})
+	}
+
+	// keys typed into the filter are not shortcuts
+	got, cmd := updateTestUI(t, m, append([]tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}}, getTestUIKeys("qcp")...)...)
+	if isTestQuitCmd(cmd) || len(got.calls) != 3 || got.showPolicy || got.filterInput != "qcp" {
+		t.Errorf("the keys typed into the filter were handled as shortcuts")
+	}
+}
+
+func TestUIUpdatePolicy(t *testing.T) {
+	resetTestCallLog(t)
+	file := filepath.Join(t.TempDir(), "policy.json")
+	setTestFlag(t, "output-file", file)
+	recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	m, _ := updateTestUI(t, newUIModel(nil, nil), getTestUIKeys("p")...)
+
+	if view := m.View(); !strings.HasPrefix(view, "Policy\n") || !strings.Contains(view, "s3:ListAllMyBuckets") {
+		t.Errorf("the view does not show the policy:\n%s", view)
+	}
+	if got := readTestOutputFile(t, file); !strings.Contains(got, "s3:ListAllMyBuckets") {
+		t.Errorf("the policy was not saved to --output-file:\n%s", got)
+	}
+	if !strings.HasPrefix(m.status, "Saved the policy to ") {
+		t.Errorf("got status %q, want the policy saved", m.status)
+	}
+
+	m, _ = updateTestUI(t, m, getTestUIKeys("p")...)
+	if m.showPolicy {
+		t.Errorf("p does not go back to the calls")
+	}
+}
+
+func TestUIUpdateClear(t *testing.T) {
+	resetTestCallLog(t)
+	recordCall(Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()})
+
+	m, _ := updateTestUI(t, newUIModel(nil, nil), getTestUICall("S3", "ListBuckets"), getTestUIKeys("c")[0])
+
+	if len(m.calls) != 0 || callLog.Len() != 0 {
+		t.Errorf("got %d calls in the UI and %d in the log after clearing, want none", len(m.calls), callLog.Len())
+	}
+	if m.status != "Cleared the captured calls" {
+		t.Errorf("got status %q", m.status)
+	}
+}
+
+func TestUIUpdateQuit(t *testing.T) {
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("q")}, {Type: tea.KeyCtrlC}} {
+		t.Run(key.String(), func(t *testing.T) {
+			if _, cmd := updateTestUI(t, newUIModel(nil, nil), key); !isTestQuitCmd(cmd) {
+				t.Errorf("%s does not quit", key)
+			}
+		})
+	}
+
+	// Ctrl+C quits while editing the filter, while q is typed
+	if _, cmd := updateTestUI(t, newUIModel(nil, nil), getTestUIKeys("f")[0], tea.KeyMsg{Type: tea.KeyCtrlC}); !isTestQuitCmd(cmd) {
+		t.Errorf("Ctrl+C does not quit while editing the filter")
+	}
+}
+
+func TestUIUpdateScrolling(t *testing.T) {
+	m, _ := updateTestUI(t, newUIModel(nil, nil), tea.WindowSizeMsg{Width: 100, Height: 10})
+	for i := 0; i < 20; i++ {
+		m, _ = updateTestUI(t, m, getTestUICall("S3", fmt.Sprintf("Call%d", i)))
+	}
+	// 6 rows fit between the header and status lines
+	if m.offset != 14 || !m.follow {
+		t.Fatalf("got offset %d following %t, want the last page followed", m.offset, m.follow)
+	}
+
+	tests := []struct {
+		key        tea.Msg
+		wantOffset int
+		wantFollow bool
+	}{
+		{key: tea.KeyMsg{Type: tea.KeyUp}, wantOffset: 13},
+		{key: getTestUICall("S3", "Call20"), wantOffset: 13}, // stays put once scrolled away from the end
+		{key: tea.KeyMsg{Type: tea.KeyPgUp}, wantOffset: 7},
+		{key: getTestUIKeys("g")[0], wantOffset: 0},
+		{key: getTestUIKeys("k")[0], wantOffset: 0},
+		{key: getTestUIKeys("j")[0], wantOffset: 1},
+		{key: tea.KeyMsg{Type: tea.KeyPgDown}, wantOffset: 7},
+		{key: tea.KeyMsg{Type: tea.KeyEnd}, wantOffset: 15, wantFollow: true},
+		{key: getTestUICall("S3", "Call21"), wantOffset: 16, wantFollow: true},
+		{key: tea.KeyMsg{Type: tea.KeyDown}, wantOffset: 16, wantFollow: true},
+		{key: tea.WindowSizeMsg{}, wantOffset: 16, wantFollow: true}, // an unknown size is ignored
+		{key: tea.WindowSizeMsg{Width: 100, Height: 30}, wantOffset: 0, wantFollow: true},
+	}
+
+	for i, tt := range tests {
+		m, _ = updateTestUI(t, m, tt.key)
+		if m.offset != tt.wantOffset || m.follow != tt.wantFollow {
+			t.Errorf("step %d: got offset %d following %t, want %d following %t", i, m.offset, m.follow, tt.wantOffset, tt.wantFollow)
+		}
+	}
+
+	if lines := strings.Split(m.View(), "\n"); len(lines) != 30 {
+		t.Errorf("got %d lines, want the 30 lines of the terminal", len(lines))
+	}
+}
+
+func TestUIProgram(t *testing.T) {
+	var output bytes.Buffer
+	events := make(chan Entry, 1)
+	events <- Entry{Region: "us-east-1", Type: "ApiCall", Service: "S3", Method: "ListBuckets", FinalHTTPStatusCode: 200, Timestamp: time.Now()}
+	program := tea.NewProgram(newUIModel(events, make(chan string)), tea.WithInput(strings.NewReader("q")), tea.WithOutput(&output))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- program.Start()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		program.Kill()
+		t.Fatal("the terminal UI did not quit on q")
+	}
+
+	if !strings.Contains(output.String(), "p policy  c clear  f filter  q quit") {
+		t.Errorf("the terminal UI was not drawn:\n%q", output.String())
+	}
+}
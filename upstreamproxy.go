@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/elazarl/goproxy"
+)
+
+var upstreamProxyURL *url.URL
+var upstreamNoProxy []string
+
+// getEnvUpstreamProxy returns the proxy set in the environment, used as the default for --upstream-proxy
+func getEnvUpstreamProxy() string {
+	for _, envVar := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if value := os.Getenv(envVar); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// isEnvUpstreamProxy returns whether --upstream-proxy was left to default to the proxy set in the environment
+func isEnvUpstreamProxy() bool {
+	return configSources["upstream-proxy"] == configSourceEnv && *upstreamProxyFlag == getEnvUpstreamProxy()
+}
+
+func loadUpstreamProxy() error {
+	if *upstreamProxyFlag == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(*upstreamProxyFlag)
+	if err != nil || proxyURL.Host == "" {
+		proxyURL, err = url.Parse("http://" + *upstreamProxyFlag) // e.g. corp-proxy:8080
+		if err != nil {
+			return fmt.Errorf("could not parse --upstream-proxy: %v", err)
+		}
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return fmt.Errorf("--upstream-proxy must be an http or https URL")
+	}
+	if isOwnProxyAddress(proxyURL) {
+		if isEnvUpstreamProxy() {
+			// a shell set up to send its AWS calls through iamlive is often the one starting it
+			log.Printf("WARNING: ignoring the proxy %s in HTTPS_PROXY or HTTP_PROXY, as it is the address of iamlive itself", proxyURL.Host)
+			return nil
+		}
+		return fmt.Errorf("--upstream-proxy %s is the address of iamlive itself", proxyURL.Host)
+	}
+	if *upstreamProxyUserFlag != "" {
+		proxyURL.User = url.UserPassword(*upstreamProxyUserFlag, *upstreamProxyPassFlag)
+	}
+	upstreamProxyURL = proxyURL
+
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	for _, host := range strings.Split(noProxy, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			upstreamNoProxy = append(upstreamNoProxy, host)
+		}
+	}
+
+	return nil
+}
+
+// isOwnProxyAddress returns whether a proxy URL points back at the --bind-addr of this proxy, which would make
+// every request loop
+func isOwnProxyAddress(proxyURL *url.URL) bool {
+	bindHost, bindPort, err := net.SplitHostPort(*bindAddrFlag)
+	if err != nil || proxyURL.Port() != bindPort {
+		return false
+	}
+
+	if proxyURL.Hostname() == bindHost || proxyURL.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(proxyURL.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+// isNoProxyHost returns whether a host, optionally with a port, is excluded from the upstream proxy by NO_PROXY
+func isNoProxyHost(addr string) bool {
+	host := addr
+	if splitHost, _, err := net.SplitHostPort(addr); err == nil {
+		host = splitHost
+	}
+	host = strings.ToLower(host)
+
+	for _, noProxyHost := range upstreamNoProxy {
+		if noProxyHost == "*" {
+			return true
+		}
+		if splitHost, _, err := net.SplitHostPort(noProxyHost); err == nil {
+			noProxyHost = splitHost
+		}
+		noProxyHost = strings.TrimPrefix(noProxyHost, ".")
+		if host == noProxyHost || strings.HasSuffix(host, "."+noProxyHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addUpstreamProxy routes the proxy's outbound requests and CONNECT tunnels through --upstream-proxy
+func addUpstreamProxy(proxy *goproxy.ProxyHttpServer) {
+	proxy.Tr.Proxy = func(req *http.Request) (*url.URL, error) {
+		if isNoProxyHost(req.URL.Host) {
+			return nil, nil
+		}
+		return upstreamProxyURL, nil
+	}
+
+	connectDial := proxy.NewConnectDialToProxyWithHandler(upstreamProxyURL.String(), func(req *http.Request) {
+		if upstreamProxyURL.User != nil {
+			password, _ := upstreamProxyURL.User.Password()
+			credentials := upstreamProxyURL.User.Username() + ":" + password
+			req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		}
+	})
+	proxy.ConnectDial = func(network, addr string) (net.Conn, error) {
+		if isNoProxyHost(addr) {
+			return net.Dial(network, addr)
+		}
+		return connectDial(network, addr)
+	}
+}